@@ -3,6 +3,8 @@ module github.com/wismed-web/wisite-api
 go 1.19
 
 require (
+	github.com/dgraph-io/badger/v3 v3.2103.5
+	github.com/digisan/db-helper v0.0.21
 	github.com/digisan/event-mgr v0.1.22
 	github.com/digisan/file-mgr v0.2.14
 	github.com/digisan/go-config v0.1.7
@@ -11,11 +13,16 @@ require (
 	github.com/digisan/logkit v0.1.5
 	github.com/digisan/user-mgr v0.5.1
 	github.com/golang-jwt/jwt/v4 v4.4.3
+	github.com/google/uuid v1.3.0
+	github.com/h2non/filetype v1.1.3
+	github.com/jtguibas/cinema v0.0.0-20200208054232-ca271f28a020
 	github.com/labstack/echo/v4 v4.10.0
 	github.com/postfinance/single v0.0.2
 	github.com/swaggo/echo-swagger v1.3.5
 	github.com/swaggo/swag v1.8.10
-	golang.org/x/net v0.5.0
+	golang.org/x/crypto v0.23.0
+	golang.org/x/image v0.18.0
+	golang.org/x/net v0.25.0
 )
 
 require (
@@ -25,9 +32,7 @@ require (
 	github.com/PuerkitoBio/urlesc v0.0.0-20170810143723-de5bf2ad4578 // indirect
 	github.com/cespare/xxhash v1.1.0 // indirect
 	github.com/cespare/xxhash/v2 v2.2.0 // indirect
-	github.com/dgraph-io/badger/v3 v3.2103.5 // indirect
 	github.com/dgraph-io/ristretto v0.1.1 // indirect
-	github.com/digisan/db-helper v0.0.21 // indirect
 	github.com/digisan/fileflatter v0.0.6 // indirect
 	github.com/digisan/go-mail v0.1.2 // indirect
 	github.com/dustin/go-humanize v1.0.1 // indirect
@@ -44,13 +49,10 @@ require (
 	github.com/golang/protobuf v1.5.2 // indirect
 	github.com/golang/snappy v0.0.4 // indirect
 	github.com/google/flatbuffers v23.1.21+incompatible // indirect
-	github.com/google/uuid v1.3.0 // indirect
 	github.com/gookit/color v1.5.2 // indirect
 	github.com/gorilla/mux v1.8.0 // indirect
-	github.com/h2non/filetype v1.1.3 // indirect
 	github.com/josharian/intern v1.0.0 // indirect
 	github.com/json-iterator/go v1.1.12 // indirect
-	github.com/jtguibas/cinema v0.0.0-20200208054232-ca271f28a020 // indirect
 	github.com/klauspost/compress v1.15.15 // indirect
 	github.com/labstack/gommon v0.4.0 // indirect
 	github.com/leodido/go-urn v1.2.1 // indirect
@@ -72,11 +74,10 @@ require (
 	github.com/valyala/fasttemplate v1.2.2 // indirect
 	github.com/xo/terminfo v0.0.0-20220910002029-abceb7e1c41e // indirect
 	go.opencensus.io v0.24.0 // indirect
-	golang.org/x/crypto v0.4.0 // indirect
-	golang.org/x/sys v0.4.0 // indirect
-	golang.org/x/text v0.6.0 // indirect
+	golang.org/x/sys v0.20.0 // indirect
+	golang.org/x/text v0.16.0 // indirect
 	golang.org/x/time v0.3.0 // indirect
-	golang.org/x/tools v0.1.12 // indirect
+	golang.org/x/tools v0.21.1-0.20240508182429-e35e4ccd0d2d // indirect
 	google.golang.org/protobuf v1.28.1 // indirect
 	gopkg.in/go-playground/validator.v9 v9.31.0 // indirect
 	gopkg.in/yaml.v2 v2.4.0 // indirect
@@ -3,6 +3,10 @@ package api
 import (
 	"github.com/labstack/echo/v4"
 	ad "github.com/wismed-web/wisite-api/server/api/admin"
+	"github.com/wismed-web/wisite-api/server/api/mfa"
+	"github.com/wismed-web/wisite-api/server/api/post"
+	"github.com/wismed-web/wisite-api/server/api/session"
+	"github.com/wismed-web/wisite-api/server/api/sign"
 )
 
 // register to main echo Group
@@ -11,20 +15,37 @@ import (
 func AdminHandler(r *echo.Group) {
 
 	var mGET = map[string]echo.HandlerFunc{
-		"/spa/menu": ad.Menu,
-		"/users":    ad.ListUser,
-		"/onlines":  ad.ListOnlineUser,
-		"/avatar":   ad.UserAvatar,
+		"/spa/menu":         ad.Menu,
+		"/users":            ad.ListUser,
+		"/onlines":          ad.ListOnlineUser,
+		"/avatar":           ad.UserAvatar,
+		"/moderation/queue": post.ModerationQueue,
+		"/analytics/top":    post.TopAnalytics,
+		"/lockouts":         sign.LockoutList,
+		"/sessions":         session.UserList,
+		"/mfa/require":      mfa.RequireAdmin,
 	}
 
-	var mPOST = map[string]echo.HandlerFunc{}
+	var mPOST = map[string]echo.HandlerFunc{
+		"/category/save": post.SaveCategory,
+	}
 
 	var mPUT = map[string]echo.HandlerFunc{
-		"/activate":    ad.ActivateUser,
-		"/officialize": ad.OfficializeUser,
+		"/activate":                  ad.ActivateUser,
+		"/officialize":               ad.OfficializeUser,
+		"/moderation/hide/:id":       post.HidePost,
+		"/moderation/restore/:id":    post.RestorePost,
+		"/moderation/warn/:id":       post.WarnAuthor,
+		"/moderation/deactivate/:id": post.DeactivateAuthor,
+		"/mfa/require":               mfa.SetRequire,
 	}
 
-	var mDELETE = map[string]echo.HandlerFunc{}
+	var mDELETE = map[string]echo.HandlerFunc{
+		"/category/del/:id":      post.DelCategory,
+		"/moderation/remove/:id": post.RemovePost,
+		"/lockout":               sign.ClearLockout,
+		"/sessions":              session.UserRevoke,
+	}
 	var mPATCH = map[string]echo.HandlerFunc{}
 
 	// ------------------------------------------------------- //
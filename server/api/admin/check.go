@@ -0,0 +1,36 @@
+package admin
+
+import (
+	"net/http"
+
+	u "github.com/digisan/user-mgr/user"
+	"github.com/golang-jwt/jwt/v4"
+	"github.com/labstack/echo/v4"
+)
+
+// admin check, active user of MemLevel 3 is admin. variable for replacing in test
+var IsAdmin = func(uname string) (bool, error) {
+	user, ok, err := u.LoadActiveUser(uname)
+	if err != nil {
+		return false, err
+	}
+	return ok && user.MemLevel == 3, nil
+}
+
+// for apis of other groups only admin can invoke. reply 401 if caller is not admin (inactive caller included),
+// and then return false with error of replying
+func Only(c echo.Context) (bool, error) {
+	var (
+		userTkn = c.Get("user").(*jwt.Token)
+		claims  = userTkn.Claims.(*u.UserClaims)
+		uname   = claims.UName
+	)
+	admin, err := IsAdmin(uname)
+	switch {
+	case err != nil:
+		return false, c.String(http.StatusInternalServerError, err.Error())
+	case !admin:
+		return false, c.String(http.StatusUnauthorized, "failed, you are not authorized to this api")
+	}
+	return true, nil
+}
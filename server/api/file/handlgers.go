@@ -11,6 +11,7 @@ import (
 	u "github.com/digisan/user-mgr/user"
 	"github.com/golang-jwt/jwt/v4"
 	"github.com/labstack/echo/v4"
+	"github.com/wismed-web/wisite-api/server/api/file/media"
 	"github.com/wismed-web/wisite-api/server/api/sign"
 )
 
@@ -133,6 +134,11 @@ func UploadFormFile(c echo.Context) error {
 		return c.String(http.StatusInternalServerError, err.Error())
 	}
 
+	// probe media metadata once, probe error only degrades this file's metadata
+	if _, err := media.Store(path, fileID(us.(*fm.UserSpace), path)); err != nil {
+		lk.Warn("UploadFormFile / media.Store ERR: %v", err)
+	}
+
 	// * root    path   	 	 "data/user-space/"
 	// * storage path   	 	 "data/user-space/cdutwhu/2022-05/g0/g1/g2/document/github key.1652858188.txt"
 	// * this    return 	 	 "2022-05/g0/g1/g2/document/github key.1652858188.txt"
@@ -191,7 +197,22 @@ func UploadBodyData(c echo.Context) error {
 		return c.String(http.StatusInternalServerError, err.Error())
 	}
 
+	// probe media metadata once, probe error only degrades this file's metadata
+	if _, err := media.Store(path, fileID(us.(*fm.UserSpace), path)); err != nil {
+		lk.Warn("UploadBodyData / media.Store ERR: %v", err)
+	}
+
 	parts := strings.Split(path, "/")
 	path = strings.Join(parts[3:], "/")
 	return c.JSON(http.StatusOK, path)
 }
+
+// file item id of [fpath] in user space [us], empty if not found
+func fileID(us *fm.UserSpace, fpath string) string {
+	for _, fi := range us.FIs {
+		if filepath.Clean(fi.Path) == filepath.Clean(fpath) {
+			return fi.Id
+		}
+	}
+	return ""
+}
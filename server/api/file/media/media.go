@@ -0,0 +1,179 @@
+package media
+
+import (
+	"encoding/json"
+	"fmt"
+	"image"
+	_ "image/gif"
+	_ "image/jpeg"
+	_ "image/png"
+	"mime"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/dgraph-io/badger/v3"
+	bh "github.com/digisan/db-helper/badger"
+	"github.com/digisan/file-mgr/fdb"
+	fd "github.com/digisan/gotk/filedir"
+	lk "github.com/digisan/logkit"
+	"github.com/h2non/filetype"
+	"github.com/jtguibas/cinema"
+)
+
+// key: storage path, e.g. "data/user-space/uname/2022-05/g0/image/a-1652858188.png";
+// value: json of media metadata, probed once at uploading
+type Meta struct {
+	Path       string    `json:"path"`
+	FileID     string    `json:"fileId"`
+	Type       string    `json:"type"`     // file type, e.g. "image", "video", "document"
+	Size       string    `json:"size"`     // real dimension "width,height", only for image & video
+	Duration   float64   `json:"duration"` // seconds, only for video
+	Bytes      int64     `json:"bytes"`
+	MIME       string    `json:"mime"`                 // e.g. "image/png", "video/webm"
+	Variants   []Variant `json:"variants"`             // resized versions, only for image, narrowest first
+	Poster     string    `json:"poster,omitempty"`     // storage path of poster frame, only for video
+	ProbeErr   string    `json:"probeErr,omitempty"`   // if not empty, this media is degraded, e.g. no dimension
+	VariantErr string    `json:"variantErr,omitempty"` // variants or poster failed, original media is still fine
+	Tm         time.Time `json:"tm"`
+}
+
+func (m Meta) String() string {
+	return fmt.Sprintf("%s [%s %s] size: %s, duration: %v, bytes: %d, probe error: %s, variant error: %s", m.Path, m.Type, m.MIME, m.Size, m.Duration, m.Bytes, m.ProbeErr, m.VariantErr)
+}
+
+func (m *Meta) BadgerDB() *badger.DB {
+	return DbGrp.Media
+}
+
+func (m *Meta) Key() []byte {
+	return []byte(m.Path)
+}
+
+func (m *Meta) Marshal(at any) (forKey, forValue []byte) {
+	forKey = m.Key()
+	forValue, err := json.Marshal(m)
+	lk.FailOnErr("%v", err)
+	return
+}
+
+func (m *Meta) Unmarshal(dbKey, dbVal []byte) (any, error) {
+	if err := json.Unmarshal(dbVal, m); err != nil {
+		return nil, err
+	}
+	return m, nil
+}
+
+// MIME type of [fpath] from its content signature, then from its extension
+func mimeType(fpath string) string {
+	if kind, err := filetype.MatchFile(fpath); err == nil && kind != filetype.Unknown {
+		return kind.MIME.Value
+	}
+	if t := mime.TypeByExtension(filepath.Ext(fpath)); len(t) > 0 {
+		return t
+	}
+	return "application/octet-stream"
+}
+
+// image or video stored before variants were generated
+func (m *Meta) lackVariants() bool {
+	return len(m.ProbeErr) == 0 && ((m.Type == "image" && m.Variants == nil && m.MIME != "image/gif") || (m.Type == "video" && len(m.Poster) == 0))
+}
+
+// probe media metadata of [fpath]. probe error is recorded in [ProbeErr], never fails
+func probeMedia(fpath string) *Meta {
+	m := &Meta{Path: filepath.Clean(fpath), Tm: time.Now()}
+	info, err := os.Stat(fpath)
+	if err != nil || info.IsDir() {
+		return m
+	}
+	m.Bytes = info.Size()
+	m.Type = fdb.GetFileType(fpath)
+	m.MIME = mimeType(fpath)
+
+	switch m.Type {
+	case "image":
+		f, err := os.Open(fpath)
+		if err != nil {
+			m.ProbeErr = err.Error()
+			break
+		}
+		defer f.Close()
+		cfg, _, err := image.DecodeConfig(f)
+		if err != nil {
+			m.ProbeErr = err.Error()
+			break
+		}
+		m.Size = fmt.Sprintf("%d,%d", cfg.Width, cfg.Height)
+
+	case "video":
+		video, err := cinema.Load(fpath)
+		if err != nil || video == nil {
+			m.ProbeErr = fmt.Sprintf("load video error: %v", err)
+			break
+		}
+		m.Size = fmt.Sprintf("%d,%d", video.Width(), video.Height())
+		m.Duration = video.Duration().Seconds()
+	}
+	if len(m.ProbeErr) > 0 {
+		lk.Warn("probe media error %v @ %s @ %s", m.ProbeErr, m.Type, fpath)
+	}
+	return m
+}
+
+// probe & store media metadata of uploaded file at [fpath], also generate its variants
+func Store(fpath, fileID string) (*Meta, error) {
+	m := probeMedia(fpath)
+	m.FileID = fileID
+	if len(m.ProbeErr) == 0 {
+		genVariants(m)
+	}
+	return m, bh.UpsertOneObject(m)
+}
+
+// stored media metadata of [fpath]. if not stored yet (e.g. not backfilled), probe & store it now,
+// variants are left to '-backfill-media' as generating them is too slow for reading.
+// missing file gets empty metadata without storing
+func Load(fpath string) (*Meta, error) {
+	fpath = filepath.Clean(fpath)
+	m, err := bh.GetOneObject[Meta]([]byte(fpath))
+	if err != nil {
+		return nil, err
+	}
+	if m != nil {
+		// stored before MIME type was recorded
+		if len(m.MIME) == 0 && fd.FileExists(fpath) {
+			m.MIME = mimeType(fpath)
+			return m, bh.UpsertOneObject(m)
+		}
+		return m, nil
+	}
+	if !fd.FileExists(fpath) {
+		return &Meta{Path: fpath}, nil
+	}
+	m = probeMedia(fpath)
+	return m, bh.UpsertOneObject(m)
+}
+
+// probe & store media metadata for all existing file items which have no metadata or no variants yet. return stored count
+func Backfill() (int, error) {
+	fis, err := fdb.ListFileItems(nil)
+	if err != nil {
+		return 0, err
+	}
+	n := 0
+	for _, fi := range fis {
+		m, err := bh.GetOneObject[Meta]([]byte(filepath.Clean(fi.Path)))
+		if err != nil {
+			return n, err
+		}
+		if (m != nil && !m.lackVariants()) || !fd.FileExists(fi.Path) {
+			continue
+		}
+		if _, err := Store(fi.Path, fi.Id); err != nil {
+			return n, err
+		}
+		n++
+	}
+	return n, nil
+}
@@ -0,0 +1,146 @@
+package media
+
+import (
+	"encoding/binary"
+	"hash/crc32"
+	"image"
+	"image/png"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestMain(m *testing.M) {
+	InitDB("")
+	os.Exit(m.Run())
+}
+
+func TestStoreLoad(t *testing.T) {
+	dir := t.TempDir()
+
+	good := filepath.Join(dir, "good.png")
+	f, err := os.Create(good)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := png.Encode(f, image.NewRGBA(image.Rect(0, 0, 40, 30))); err != nil {
+		t.Fatal(err)
+	}
+	f.Close()
+
+	// png signature, but broken content
+	bad := filepath.Join(dir, "bad.png")
+	if err := os.WriteFile(bad, []byte("\x89PNG\r\n\x1a\n-broken-"), os.ModePerm); err != nil {
+		t.Fatal(err)
+	}
+
+	m, err := Store(good, "fid")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if m.Type != "image" || m.MIME != "image/png" || m.Size != "40,30" || m.Bytes == 0 || len(m.ProbeErr) > 0 {
+		t.Fatalf("unexpected media: %v", m)
+	}
+
+	// stored value is read without probing again
+	if err := os.Remove(good); err != nil {
+		t.Fatal(err)
+	}
+	if m, err := Load(good); err != nil || m.Size != "40,30" || m.FileID != "fid" {
+		t.Fatalf("unexpected stored media: %v, %v", m, err)
+	}
+
+	// not stored yet, probed when loading, degraded without failing
+	if m, err := Load(bad); err != nil || m.Type != "image" || len(m.Size) > 0 || len(m.ProbeErr) == 0 {
+		t.Fatalf("unexpected degraded media: %v, %v", m, err)
+	}
+
+	if m, err := Load(filepath.Join(dir, "missing.png")); err != nil || len(m.Type) > 0 {
+		t.Fatalf("unexpected missing media: %v, %v", m, err)
+	}
+}
+
+func TestVariants(t *testing.T) {
+	dir := t.TempDir()
+
+	for name, width := range map[string]int{"big.png": 1600, "small.png": 200} {
+		f, err := os.Create(filepath.Join(dir, name))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if err := png.Encode(f, image.NewRGBA(image.Rect(0, 0, width, width/2))); err != nil {
+			t.Fatal(err)
+		}
+		f.Close()
+	}
+
+	m, err := Store(filepath.Join(dir, "big.png"), "fid")
+	if err != nil || len(m.ProbeErr) > 0 {
+		t.Fatalf("unexpected media: %v, %v", m, err)
+	}
+	if len(m.Variants) != len(variantWidths) {
+		t.Fatalf("want %d variants, got %v", len(variantWidths), m.Variants)
+	}
+	for i, v := range m.Variants {
+		cfg, err := func() (image.Config, error) {
+			f, err := os.Open(v.Path)
+			if err != nil {
+				return image.Config{}, err
+			}
+			defer f.Close()
+			return png.DecodeConfig(f)
+		}()
+		if err != nil || v.MIME != "image/png" || v.Width != variantWidths[i] || v.Height != v.Width/2 || cfg.Width != v.Width || cfg.Height != v.Height {
+			t.Fatalf("unexpected variant %+v, decoded %+v, %v", v, cfg, err)
+		}
+		if filepath.Dir(v.Path) != filepath.Join(dir, variantDir) {
+			t.Fatalf("variant should be stored in '%s', got %s", variantDir, v.Path)
+		}
+	}
+
+	// same file not stored yet, loading only probes, variants are left to backfill
+	unstored := filepath.Join(dir, "unstored.png")
+	if err := os.Link(filepath.Join(dir, "big.png"), unstored); err != nil {
+		t.Fatal(err)
+	}
+	if m, err := Load(unstored); err != nil || m.Size != "1600,800" || m.Variants != nil || !m.lackVariants() {
+		t.Fatalf("loading should not generate variants: %v, %v", m, err)
+	}
+
+	// no upscaled variant, stored as empty list
+	if m, err := Store(filepath.Join(dir, "small.png"), "fid"); err != nil || m.Variants == nil || len(m.Variants) > 0 || m.lackVariants() {
+		t.Fatalf("unexpected small media: %v, %v", m, err)
+	}
+	if m, err := Load(filepath.Join(dir, "small.png")); err != nil || m.Variants == nil || m.lackVariants() {
+		t.Fatalf("unexpected stored small media: %v, %v", m, err)
+	}
+}
+
+func TestHugeImage(t *testing.T) {
+	dir := t.TempDir()
+
+	// tiny png declaring 100000x100000 pixels
+	ihdr := make([]byte, 13)
+	binary.BigEndian.PutUint32(ihdr[0:], 100000)
+	binary.BigEndian.PutUint32(ihdr[4:], 100000)
+	ihdr[8], ihdr[9] = 8, 6 // 8 bits RGBA
+	chunk := func(typ string, data []byte) []byte {
+		b := binary.BigEndian.AppendUint32(nil, uint32(len(data)))
+		b = append(append(b, typ...), data...)
+		return binary.BigEndian.AppendUint32(b, crc32.ChecksumIEEE(append([]byte(typ), data...)))
+	}
+	data := append([]byte("\x89PNG\r\n\x1a\n"), chunk("IHDR", ihdr)...)
+	data = append(data, chunk("IEND", nil)...)
+	huge := filepath.Join(dir, "huge.png")
+	if err := os.WriteFile(huge, data, os.ModePerm); err != nil {
+		t.Fatal(err)
+	}
+
+	m, err := Store(huge, "fid")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if m.Size != "100000,100000" || len(m.ProbeErr) > 0 || len(m.VariantErr) == 0 || len(m.Variants) > 0 {
+		t.Fatalf("huge image should keep good metadata with variant error: %v", m)
+	}
+}
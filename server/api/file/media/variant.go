@@ -0,0 +1,167 @@
+package media
+
+import (
+	"fmt"
+	"image"
+	"image/jpeg"
+	"image/png"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	lk "github.com/digisan/logkit"
+	"github.com/jtguibas/cinema"
+	"golang.org/x/image/draw"
+	_ "golang.org/x/image/webp"
+)
+
+// resized versions of an uploaded image, and poster frame of an uploaded video.
+// they are stored under 'variants' folder beside the original file, e.g.
+// "data/user-space/uname/2022-05/g0/image/variants/a-1652858188-w320.jpg"
+
+// variant widths for image. only those narrower than the original are generated
+var variantWidths = []int{320, 640, 1280}
+
+const (
+	maxPixels    = 50_000_000 // larger image is not decoded for variants, it takes 4 bytes per pixel in memory
+	variantDir   = "variants"
+	jpegQuality  = 80
+	posterWidth  = 1280
+	posterOffset = time.Second // take poster frame from here, or from start for shorter video
+)
+
+type Variant struct {
+	Path   string `json:"path"` // storage path, like [Meta] Path
+	Width  int    `json:"width"`
+	Height int    `json:"height"`
+	MIME   string `json:"mime"`
+}
+
+func variantPath(fpath, suffix string) string {
+	stem := strings.TrimSuffix(filepath.Base(fpath), filepath.Ext(fpath))
+	return filepath.Join(filepath.Dir(fpath), variantDir, stem+"-"+suffix)
+}
+
+func hasAlpha(img image.Image) bool {
+	if o, ok := img.(interface{ Opaque() bool }); ok {
+		return !o.Opaque()
+	}
+	return true
+}
+
+// encode [img] to [fpath] as jpeg, or png if [img] has transparency. return MIME type
+func encodeImage(img image.Image, fpath string) (string, error) {
+	if err := os.MkdirAll(filepath.Dir(fpath), os.ModePerm); err != nil {
+		return "", err
+	}
+	f, err := os.Create(fpath)
+	if err != nil {
+		return "", err
+	}
+	defer f.Close()
+	if filepath.Ext(fpath) == ".png" {
+		return "image/png", png.Encode(f, img)
+	}
+	return "image/jpeg", jpeg.Encode(f, img, &jpeg.Options{Quality: jpegQuality})
+}
+
+// generate resized variants for image at [fpath]. jpeg, png & webp are decoded in pure Go.
+// there is no pure Go webp encoder, so webp variants are encoded as jpeg, or png if transparent.
+// animated gif is left as is, its variant would lose animation
+func imageVariants(fpath, mime string) ([]Variant, error) {
+	if mime == "image/gif" {
+		return nil, nil
+	}
+
+	f, err := os.Open(fpath)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	// check declared dimension before decoding, a small file can declare a huge image
+	cfg, _, err := image.DecodeConfig(f)
+	if err != nil {
+		return nil, err
+	}
+	if int64(cfg.Width)*int64(cfg.Height) > maxPixels {
+		return nil, fmt.Errorf("image %dx%d is too large for variants, max %d pixels", cfg.Width, cfg.Height, maxPixels)
+	}
+	if _, err := f.Seek(0, io.SeekStart); err != nil {
+		return nil, err
+	}
+	src, _, err := image.Decode(f)
+	if err != nil {
+		return nil, err
+	}
+
+	ext := ".jpg"
+	if mime == "image/png" || (mime != "image/jpeg" && hasAlpha(src)) {
+		ext = ".png"
+	}
+
+	b := src.Bounds()
+	variants := []Variant{}
+	for _, w := range variantWidths {
+		if w >= b.Dx() {
+			break
+		}
+		h := b.Dy() * w / b.Dx()
+		if h < 1 {
+			h = 1
+		}
+		dst := image.NewRGBA(image.Rect(0, 0, w, h))
+		draw.CatmullRom.Scale(dst, dst.Bounds(), src, b, draw.Src, nil)
+
+		vpath := variantPath(fpath, fmt.Sprintf("w%d%s", w, ext))
+		vmime, err := encodeImage(dst, vpath)
+		if err != nil {
+			return variants, err
+		}
+		variants = append(variants, Variant{Path: vpath, Width: w, Height: h, MIME: vmime})
+	}
+	return variants, nil
+}
+
+// generate image variants or video poster for [m], failure is recorded in [VariantErr]
+func genVariants(m *Meta) {
+	var err error
+	switch m.Type {
+	case "image":
+		m.Variants, err = imageVariants(m.Path, m.MIME)
+	case "video":
+		var video *cinema.Video
+		if video, err = cinema.Load(m.Path); err == nil {
+			m.Poster, err = videoPoster(m.Path, video)
+		}
+	}
+	if err != nil {
+		m.VariantErr = err.Error()
+		lk.Warn("generate media variants error %v @ %s", err, m.Path)
+	}
+}
+
+// extract one frame of video at [fpath] as jpeg poster by ffmpeg, return poster storage path
+func videoPoster(fpath string, video *cinema.Video) (string, error) {
+	start := posterOffset
+	if video.Duration() <= start {
+		start = 0
+	}
+	video.Mute()
+	video.SetFPS(1)
+	video.Trim(start, start+time.Second)
+	if w, h := video.Width(), video.Height(); w > posterWidth {
+		video.SetSize(posterWidth, h*posterWidth/w/2*2) // ffmpeg needs even height
+	}
+
+	ppath := variantPath(fpath, "poster.jpg")
+	if err := os.MkdirAll(filepath.Dir(ppath), os.ModePerm); err != nil {
+		return "", err
+	}
+	if err := video.Render(ppath); err != nil {
+		return "", err
+	}
+	return ppath, nil
+}
@@ -0,0 +1,63 @@
+package api
+
+import (
+	"github.com/labstack/echo/v4"
+	"github.com/wismed-web/wisite-api/server/api/mfa"
+	"github.com/wismed-web/wisite-api/server/api/sign"
+)
+
+// register to main echo Group
+
+// "/api/mfa"
+func MFAHandler(e *echo.Group) {
+
+	var mGET = map[string]echo.HandlerFunc{
+		"/status": mfa.MyStatus,
+	}
+
+	// 2fa code checking apis share attempts limit with sign-in 2fa code guessing
+	var mPOST = map[string]echo.HandlerFunc{
+		"/enroll":   mfa.MyEnroll,
+		"/recovery": sign.Guard("verify", mfa.MyRecovery),
+		"/disable":  sign.Guard("verify", mfa.MyDisable),
+	}
+
+	var mPUT = map[string]echo.HandlerFunc{
+		"/confirm": sign.Guard("verify", mfa.MyConfirm),
+	}
+
+	var mDELETE = map[string]echo.HandlerFunc{}
+	var mPATCH = map[string]echo.HandlerFunc{}
+
+	// ------------------------------------------------------- //
+
+	methods := []string{"GET", "POST", "PUT", "DELETE", "PATCH"}
+
+	mRegAPIs := map[string]map[string]echo.HandlerFunc{
+		"GET":    mGET,
+		"POST":   mPOST,
+		"PUT":    mPUT,
+		"DELETE": mDELETE,
+		"PATCH":  mPATCH,
+		// others...
+	}
+
+	mRegMethod := map[string]func(path string, h echo.HandlerFunc, m ...echo.MiddlewareFunc) *echo.Route{
+		"GET":    e.GET,
+		"POST":   e.POST,
+		"PUT":    e.PUT,
+		"DELETE": e.DELETE,
+		"PATCH":  e.PATCH,
+		// others...
+	}
+
+	for _, m := range methods {
+		mAPI, method := mRegAPIs[m], mRegMethod[m]
+		for path, handler := range mAPI {
+			if handler == nil {
+				continue
+			}
+			method(path, handler)
+		}
+	}
+}
@@ -0,0 +1,59 @@
+package mfa
+
+import (
+	"path/filepath"
+	"sync"
+
+	"github.com/dgraph-io/badger/v3"
+	. "github.com/digisan/go-generics/v2"
+	lk "github.com/digisan/logkit"
+)
+
+type DBGrp struct {
+	sync.Mutex
+	Secret *badger.DB // uname : totp secret & recovery codes
+	Policy *badger.DB // policy name : 2fa policy
+}
+
+var (
+	onceDB sync.Once // do once
+	DbGrp  *DBGrp    // global, for keeping single instance
+)
+
+func open(dir string) *badger.DB {
+	opt := badger.DefaultOptions("").WithInMemory(true)
+	if dir != "" {
+		opt = badger.DefaultOptions(dir)
+		opt.Logger = nil
+	}
+	db, err := badger.Open(opt)
+	lk.FailOnErr("%v", err)
+	return db
+}
+
+// init global 'DbGrp'. if [dir] is empty, use in-memory db
+func InitDB(dir string) *DBGrp {
+	if DbGrp == nil {
+		onceDB.Do(func() {
+			DbGrp = &DBGrp{
+				Secret: open(IF(dir == "", "", filepath.Join(dir, "mfa-secret"))),
+				Policy: open(IF(dir == "", "", filepath.Join(dir, "mfa-policy"))),
+			}
+		})
+	}
+	return DbGrp
+}
+
+func CloseDB() {
+	DbGrp.Lock()
+	defer DbGrp.Unlock()
+
+	if DbGrp.Secret != nil {
+		lk.FailOnErr("%v", DbGrp.Secret.Close())
+		DbGrp.Secret = nil
+	}
+	if DbGrp.Policy != nil {
+		lk.FailOnErr("%v", DbGrp.Policy.Close())
+		DbGrp.Policy = nil
+	}
+}
@@ -0,0 +1,222 @@
+package mfa
+
+import (
+	"fmt"
+	"net/http"
+	"strconv"
+
+	u "github.com/digisan/user-mgr/user"
+	"github.com/golang-jwt/jwt/v4"
+	"github.com/labstack/echo/v4"
+	"github.com/wismed-web/wisite-api/server/api/admin"
+)
+
+// *** after implementing, register with path in 'mfa.go' or 'admin.go' *** //
+
+// reply error of 2fa operation
+func replyErr(c echo.Context, err error) error {
+	switch err {
+	case ErrCode:
+		return c.String(http.StatusBadRequest, err.Error())
+	case errEnabled, errNotEnrolled, errNotEnabled, errRequired:
+		return c.String(http.StatusConflict, err.Error())
+	default:
+		return c.String(http.StatusInternalServerError, err.Error())
+	}
+}
+
+// caller's user, nil if error is replied
+func caller(c echo.Context) (*u.User, error) {
+	var (
+		userTkn = c.Get("user").(*jwt.Token)
+		claims  = userTkn.Claims.(*u.UserClaims)
+		uname   = claims.UName
+	)
+	user, ok, err := u.LoadActiveUser(uname)
+	switch {
+	case err != nil:
+		return nil, c.String(http.StatusInternalServerError, err.Error())
+	case !ok:
+		return nil, c.String(http.StatusUnauthorized, fmt.Sprintf("user [%s] is not active", uname))
+	}
+	return user, nil
+}
+
+// @Title my 2fa status
+// @Summary get own two-factor authentication status, i.e. enabled, pending enrollment, count of unused recovery codes, and whether it is required.
+// @Description
+// @Tags    MFA
+// @Accept  json
+// @Produce json
+// @Success 200 "OK - get status successfully"
+// @Failure 401 "Fail - inactive user"
+// @Failure 500 "Fail - internal error"
+// @Router /api/mfa/status [get]
+// @Security ApiKeyAuth
+func MyStatus(c echo.Context) error {
+	user, err := caller(c)
+	if user == nil {
+		return err
+	}
+	st, err := FetchStatus(user)
+	if err != nil {
+		return c.String(http.StatusInternalServerError, err.Error())
+	}
+	return c.JSON(http.StatusOK, st)
+}
+
+// @Title enroll 2fa
+// @Summary start two-factor authentication enrollment, step 1. return secret & 'otpauth' uri (for QR code) to add into authenticator app. re-enrolling replaces pending secret.
+// @Description
+// @Tags    MFA
+// @Accept  json
+// @Produce json
+// @Success 200 "OK - then confirm with a code from authenticator app"
+// @Failure 401 "Fail - inactive user"
+// @Failure 409 "Fail - 2fa is already enabled"
+// @Failure 500 "Fail - internal error"
+// @Router /api/mfa/enroll [post]
+// @Security ApiKeyAuth
+func MyEnroll(c echo.Context) error {
+	user, err := caller(c)
+	if user == nil {
+		return err
+	}
+	seed, uri, err := Enroll(user.UName)
+	if err != nil {
+		return replyErr(c, err)
+	}
+	return c.JSON(http.StatusOK, echo.Map{
+		"secret": seed,
+		"uri":    uri,
+	})
+}
+
+// @Title confirm 2fa
+// @Summary confirm two-factor authentication enrollment, step 2. send a code from authenticator app to enable 2fa, return one-time recovery codes, which are shown only this time.
+// @Description
+// @Tags    MFA
+// @Accept  multipart/form-data
+// @Produce json
+// @Param   code formData string true "6 digits code from authenticator app"
+// @Success 200 "OK - 2fa enabled, return recovery codes"
+// @Failure 400 "Fail - incorrect code"
+// @Failure 401 "Fail - inactive user"
+// @Failure 409 "Fail - not enrolled or already enabled"
+// @Failure 429 "Fail - too many attempts, retry after seconds in header Retry-After"
+// @Failure 500 "Fail - internal error"
+// @Router /api/mfa/confirm [put]
+// @Security ApiKeyAuth
+func MyConfirm(c echo.Context) error {
+	user, err := caller(c)
+	if user == nil {
+		return err
+	}
+	codes, err := Confirm(user.UName, c.FormValue("code"))
+	if err != nil {
+		return replyErr(c, err)
+	}
+	return c.JSON(http.StatusOK, codes)
+}
+
+// @Title renew recovery codes
+// @Summary replace own recovery codes with new ones, old ones become invalid.
+// @Description
+// @Tags    MFA
+// @Accept  multipart/form-data
+// @Produce json
+// @Param   code formData string true "6 digits code from authenticator app"
+// @Success 200 "OK - return new recovery codes"
+// @Failure 400 "Fail - incorrect code"
+// @Failure 401 "Fail - inactive user"
+// @Failure 409 "Fail - 2fa is not enabled"
+// @Failure 429 "Fail - too many attempts, retry after seconds in header Retry-After"
+// @Failure 500 "Fail - internal error"
+// @Router /api/mfa/recovery [post]
+// @Security ApiKeyAuth
+func MyRecovery(c echo.Context) error {
+	user, err := caller(c)
+	if user == nil {
+		return err
+	}
+	codes, err := RenewRecovery(user.UName, c.FormValue("code"))
+	if err != nil {
+		return replyErr(c, err)
+	}
+	return c.JSON(http.StatusOK, codes)
+}
+
+// @Title disable 2fa
+// @Summary turn off own two-factor authentication. not allowed if 2fa is required for admin.
+// @Description
+// @Tags    MFA
+// @Accept  multipart/form-data
+// @Produce json
+// @Param   code formData string true "code from authenticator app, or a recovery code"
+// @Success 200 "OK - 2fa disabled"
+// @Failure 400 "Fail - incorrect code"
+// @Failure 401 "Fail - inactive user"
+// @Failure 409 "Fail - 2fa is not enabled or required"
+// @Failure 429 "Fail - too many attempts, retry after seconds in header Retry-After"
+// @Failure 500 "Fail - internal error"
+// @Router /api/mfa/disable [post]
+// @Security ApiKeyAuth
+func MyDisable(c echo.Context) error {
+	user, err := caller(c)
+	if user == nil {
+		return err
+	}
+	if err := Disable(user, c.FormValue("code")); err != nil {
+		return replyErr(c, err)
+	}
+	return c.JSON(http.StatusOK, "2fa disabled")
+}
+
+// @Title get 2fa admin requirement
+// @Summary get whether two-factor authentication is required for admin (MemLevel 3) users.
+// @Description
+// @Tags    Admin
+// @Accept  json
+// @Produce json
+// @Success 200 "OK - get policy successfully"
+// @Failure 401 "Fail - unauthorized error"
+// @Failure 500 "Fail - internal error"
+// @Router /api/admin/mfa/require [get]
+// @Security ApiKeyAuth
+func RequireAdmin(c echo.Context) error {
+	if ok, err := admin.Only(c); !ok {
+		return err
+	}
+	p, err := FetchPolicy()
+	if err != nil {
+		return c.String(http.StatusInternalServerError, err.Error())
+	}
+	return c.JSON(http.StatusOK, p.RequireAdmin)
+}
+
+// @Title set 2fa admin requirement
+// @Summary require two-factor authentication for admin (MemLevel 3) users or not. when required, admin without 2fa must enroll at next sign-in.
+// @Description
+// @Tags    Admin
+// @Accept  json
+// @Produce json
+// @Param   flag query boolean true "true: require; false: not require"
+// @Success 200 "OK - set policy successfully"
+// @Failure 400 "Fail - invalid flag"
+// @Failure 401 "Fail - unauthorized error"
+// @Failure 500 "Fail - internal error"
+// @Router /api/admin/mfa/require [put]
+// @Security ApiKeyAuth
+func SetRequire(c echo.Context) error {
+	if ok, err := admin.Only(c); !ok {
+		return err
+	}
+	flag, err := strconv.ParseBool(c.QueryParam("flag"))
+	if err != nil {
+		return c.String(http.StatusBadRequest, "'flag' must be true or false")
+	}
+	if err := SetRequireAdmin(flag); err != nil {
+		return c.String(http.StatusInternalServerError, err.Error())
+	}
+	return c.JSON(http.StatusOK, flag)
+}
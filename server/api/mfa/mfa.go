@@ -0,0 +1,416 @@
+package mfa
+
+import (
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base32"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/url"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/dgraph-io/badger/v3"
+	bh "github.com/digisan/db-helper/badger"
+	. "github.com/digisan/go-generics/v2"
+	lk "github.com/digisan/logkit"
+	u "github.com/digisan/user-mgr/user"
+)
+
+// optional TOTP two-factor authentication. enrolled secret is pending until its first code is confirmed,
+// then one-time recovery codes are issued. sign-in of 2fa user is finished by answering a challenge
+
+const (
+	issuer       = "WISITE"
+	nRecovery    = 10
+	challengeTTL = 5 * time.Minute
+	challengeTry = 5 // wrong answers before challenge is dropped
+)
+
+var (
+	timeNow = time.Now // variable for replacing in test
+
+	b32 = base32.StdEncoding.WithPadding(base32.NoPadding)
+
+	errEnabled     = errors.New("2fa is already enabled, disable it first")
+	errNotEnrolled = errors.New("2fa is not enrolled")
+	errNotEnabled  = errors.New("2fa is not enabled")
+	errRequired    = errors.New("2fa is required for admin, cannot be disabled")
+	ErrCode        = errors.New("incorrect 2fa code")
+	ErrChallenge   = errors.New("invalid or expired 2fa challenge")
+
+	mtxChallenge sync.Mutex
+	mChallenge   = map[string]*challenge{} // token : challenge
+
+	mtxUser sync.Map // uname : *sync.Mutex, serializes secret updates of one user, e.g. last step for replay check
+)
+
+// lock secret of [uname], return unlock
+func lockUser(uname string) func() {
+	mtx, _ := mtxUser.LoadOrStore(uname, &sync.Mutex{})
+	mtx.(*sync.Mutex).Lock()
+	return mtx.(*sync.Mutex).Unlock
+}
+
+// key: uname;
+// value: json of user's totp secret
+type Secret struct {
+	Uname    string    `json:"uname"`
+	Seed     string    `json:"seed"` // base32 totp key, no padding
+	Enabled  bool      `json:"enabled"`
+	LastStep int64     `json:"lastStep"` // last accepted time step, a code is accepted only once
+	Recovery []string  `json:"recovery"` // sha256 of unused recovery codes
+	Tm       time.Time `json:"tm"`       // enrolled or enabled time
+}
+
+func (s Secret) String() string {
+	return fmt.Sprintf("2fa of %s, enabled: %v, recovery left: %d @%v", s.Uname, s.Enabled, len(s.Recovery), s.Tm)
+}
+
+func (s *Secret) BadgerDB() *badger.DB {
+	return DbGrp.Secret
+}
+
+func (s *Secret) Key() []byte {
+	return []byte(s.Uname)
+}
+
+func (s *Secret) Marshal(at any) (forKey, forValue []byte) {
+	forKey = s.Key()
+	forValue, err := json.Marshal(s)
+	lk.FailOnErr("%v", err)
+	return
+}
+
+func (s *Secret) Unmarshal(dbKey, dbVal []byte) (any, error) {
+	if err := json.Unmarshal(dbVal, s); err != nil {
+		return nil, err
+	}
+	return s, nil
+}
+
+// key: policy name;
+// value: json of 2fa policy
+type Policy struct {
+	Name         string `json:"name"`
+	RequireAdmin bool   `json:"requireAdmin"` // MemLevel 3 users must use 2fa
+}
+
+func (p *Policy) BadgerDB() *badger.DB {
+	return DbGrp.Policy
+}
+
+func (p *Policy) Key() []byte {
+	return []byte(p.Name)
+}
+
+func (p *Policy) Marshal(at any) (forKey, forValue []byte) {
+	forKey = p.Key()
+	forValue, err := json.Marshal(p)
+	lk.FailOnErr("%v", err)
+	return
+}
+
+func (p *Policy) Unmarshal(dbKey, dbVal []byte) (any, error) {
+	if err := json.Unmarshal(dbVal, p); err != nil {
+		return nil, err
+	}
+	return p, nil
+}
+
+func randHex(n int) string {
+	b := make([]byte, n)
+	_, err := rand.Read(b)
+	lk.FailOnErr("%v", err)
+	return hex.EncodeToString(b)
+}
+
+func hashCode(code string) string {
+	sum := sha256.Sum256([]byte(strings.ToLower(strings.TrimSpace(code))))
+	return hex.EncodeToString(sum[:])
+}
+
+func fetchSecret(uname string) (*Secret, error) {
+	return bh.GetOneObject[Secret]([]byte(uname))
+}
+
+// otpauth uri for authenticator app, usually shown as QR code
+func provisioningURI(uname, seed string) string {
+	label := url.PathEscape(issuer + ":" + uname)
+	params := url.Values{
+		"secret":    {seed},
+		"issuer":    {issuer},
+		"algorithm": {"SHA1"},
+		"digits":    {fmt.Sprint(digits)},
+		"period":    {fmt.Sprint(period)},
+	}
+	return "otpauth://totp/" + label + "?" + params.Encode()
+}
+
+// start or restart 2fa enrollment of [uname]. return base32 secret & its provisioning uri
+func Enroll(uname string) (seed, uri string, err error) {
+	defer lockUser(uname)()
+
+	s, err := fetchSecret(uname)
+	if err != nil {
+		return "", "", err
+	}
+	if s != nil && s.Enabled {
+		return "", "", errEnabled
+	}
+	key := make([]byte, 20) // 160 bits, as RFC 4226 recommends
+	if _, err := rand.Read(key); err != nil {
+		return "", "", err
+	}
+	s = &Secret{Uname: uname, Seed: b32.EncodeToString(key), Recovery: []string{}, Tm: timeNow()}
+	if err := bh.UpsertOneObject(s); err != nil {
+		return "", "", err
+	}
+	return s.Seed, provisioningURI(uname, s.Seed), nil
+}
+
+// check totp [code] of [s] and update its last step. caller saves [s]
+func (s *Secret) checkTOTP(code string) bool {
+	key, err := b32.DecodeString(s.Seed)
+	if err != nil {
+		return false
+	}
+	st, ok := matchStep(key, strings.TrimSpace(code), timeNow(), s.LastStep)
+	if ok {
+		s.LastStep = st
+	}
+	return ok
+}
+
+// new recovery codes of [s], only hashes are kept. caller saves [s]
+func (s *Secret) newRecovery() []string {
+	codes := []string{}
+	s.Recovery = []string{}
+	for i := 0; i < nRecovery; i++ {
+		code := randHex(5)
+		code = code[:5] + "-" + code[5:]
+		codes = append(codes, code)
+		s.Recovery = append(s.Recovery, hashCode(code))
+	}
+	return codes
+}
+
+// confirm pending enrollment of [uname] by first totp [code]. return one-time recovery codes
+func Confirm(uname, code string) ([]string, error) {
+	defer lockUser(uname)()
+
+	s, err := fetchSecret(uname)
+	if err != nil {
+		return nil, err
+	}
+	switch {
+	case s == nil:
+		return nil, errNotEnrolled
+	case s.Enabled:
+		return nil, errEnabled
+	case !s.checkTOTP(code):
+		return nil, ErrCode
+	}
+	s.Enabled, s.Tm = true, timeNow()
+	codes := s.newRecovery()
+	return codes, bh.UpsertOneObject(s)
+}
+
+// verify 2fa [code] of [uname], either a totp code or an unused recovery code, which is used up then
+func Verify(uname, code string) error {
+	defer lockUser(uname)()
+
+	s, err := fetchSecret(uname)
+	if err != nil {
+		return err
+	}
+	if s == nil || !s.Enabled {
+		return errNotEnabled
+	}
+	if !s.checkTOTP(code) {
+		h := hashCode(code)
+		if NotIn(h, s.Recovery...) {
+			return ErrCode
+		}
+		s.Recovery = Filter(s.Recovery, func(i int, e string) bool { return e != h })
+		lk.Log("2fa recovery code used by %s, %d left", uname, len(s.Recovery))
+	}
+	return bh.UpsertOneObject(s)
+}
+
+// replace recovery codes of [uname] after verifying totp [code]
+func RenewRecovery(uname, code string) ([]string, error) {
+	defer lockUser(uname)()
+
+	s, err := fetchSecret(uname)
+	if err != nil {
+		return nil, err
+	}
+	switch {
+	case s == nil || !s.Enabled:
+		return nil, errNotEnabled
+	case !s.checkTOTP(code):
+		return nil, ErrCode
+	}
+	codes := s.newRecovery()
+	return codes, bh.UpsertOneObject(s)
+}
+
+// turn off 2fa of [user] after verifying [code]. not allowed if 2fa is required for [user]
+func Disable(user *u.User, code string) error {
+	required, err := Required(user)
+	if err != nil {
+		return err
+	}
+	if required {
+		return errRequired
+	}
+	if err := Verify(user.UName, code); err != nil {
+		return err
+	}
+	_, err = bh.DeleteOneObject[Secret]([]byte(user.UName))
+	return err
+}
+
+type Status struct {
+	Enabled  bool `json:"enabled"`
+	Pending  bool `json:"pending"` // enrolled, waiting for first code
+	Recovery int  `json:"recovery"`
+	Required bool `json:"required"`
+}
+
+func FetchStatus(user *u.User) (*Status, error) {
+	s, err := fetchSecret(user.UName)
+	if err != nil {
+		return nil, err
+	}
+	required, err := Required(user)
+	if err != nil {
+		return nil, err
+	}
+	st := &Status{Required: required}
+	if s != nil {
+		st.Enabled, st.Pending, st.Recovery = s.Enabled, !s.Enabled, len(s.Recovery)
+	}
+	return st, nil
+}
+
+func FetchPolicy() (*Policy, error) {
+	p, err := bh.GetOneObject[Policy]([]byte("policy"))
+	if err != nil {
+		return nil, err
+	}
+	if p == nil {
+		p = &Policy{Name: "policy"}
+	}
+	return p, nil
+}
+
+func SetRequireAdmin(flag bool) error {
+	return bh.UpsertOneObject(&Policy{Name: "policy", RequireAdmin: flag})
+}
+
+// 2fa is mandatory for [user], i.e. admin when policy requires
+func Required(user *u.User) (bool, error) {
+	p, err := FetchPolicy()
+	if err != nil {
+		return false, err
+	}
+	return p.RequireAdmin && user.MemLevel == 3, nil
+}
+
+/////////////////////////////////////////////////////////////////////////////////
+
+// pending sign-in waiting for 2fa code
+type challenge struct {
+	uname  string
+	enroll bool // answer confirms enrollment
+	expire time.Time
+	tries  int
+}
+
+// sign-in step for [user] after password is checked. [mode] is "" if no 2fa, "totp" if a code is needed,
+// or "enroll" if 2fa is required but not enabled yet, then [seed] & [uri] are for enrolling.
+// [token] is the challenge to answer with code
+func Challenge(user *u.User) (mode, token, seed, uri string, err error) {
+	s, err := fetchSecret(user.UName)
+	if err != nil {
+		return "", "", "", "", err
+	}
+	required, err := Required(user)
+	if err != nil {
+		return "", "", "", "", err
+	}
+	switch {
+	case s != nil && s.Enabled:
+		mode = "totp"
+	case required && s != nil:
+		mode = "enroll" // pending secret may be added into app already, keep it
+		seed, uri = s.Seed, provisioningURI(user.UName, s.Seed)
+	case required:
+		mode = "enroll"
+		if seed, uri, err = Enroll(user.UName); err != nil {
+			return "", "", "", "", err
+		}
+	default:
+		return "", "", "", "", nil
+	}
+
+	mtxChallenge.Lock()
+	defer mtxChallenge.Unlock()
+
+	now := timeNow()
+	for tkn, c := range mChallenge {
+		if now.After(c.expire) {
+			delete(mChallenge, tkn)
+		}
+	}
+	token = randHex(32)
+	mChallenge[token] = &challenge{uname: user.UName, enroll: mode == "enroll", expire: now.Add(challengeTTL)}
+	return mode, token, seed, uri, nil
+}
+
+// uname of alive challenge [token], empty if not existing
+func ChallengeOwner(token string) string {
+	mtxChallenge.Lock()
+	defer mtxChallenge.Unlock()
+
+	if c, ok := mChallenge[token]; ok && !timeNow().After(c.expire) {
+		return c.uname
+	}
+	return ""
+}
+
+// answer challenge [token] with 2fa [code]. return uname of finished sign-in, and recovery codes if
+// it also confirmed enrollment
+func Answer(token, code string) (uname string, recovery []string, err error) {
+	// take challenge out, so it is answered once at a time without holding lock during db access
+	mtxChallenge.Lock()
+	c, ok := mChallenge[token]
+	delete(mChallenge, token)
+	mtxChallenge.Unlock()
+
+	if !ok || timeNow().After(c.expire) {
+		return "", nil, ErrChallenge
+	}
+	if c.enroll {
+		recovery, err = Confirm(c.uname, code)
+	} else {
+		err = Verify(c.uname, code)
+	}
+	switch {
+	case err == ErrCode:
+		if c.tries++; c.tries < challengeTry {
+			mtxChallenge.Lock()
+			mChallenge[token] = c
+			mtxChallenge.Unlock()
+		}
+		return "", nil, err
+	case err != nil:
+		return "", nil, err
+	}
+	return c.uname, recovery, nil
+}
@@ -0,0 +1,180 @@
+package mfa
+
+import (
+	"crypto/sha1"
+	"net/url"
+	"os"
+	"strings"
+	"testing"
+	"time"
+
+	u "github.com/digisan/user-mgr/user"
+	"github.com/google/uuid"
+)
+
+func TestMain(m *testing.M) {
+	InitDB("")
+	os.Exit(m.Run())
+}
+
+// unique [name] in one test run, so records of earlier runs (e.g. by -count=2) are never met
+func uniq(name string) string {
+	return name + "-" + uuid.NewString()[:8]
+}
+
+// current totp code of [uname]'s secret
+func codeOf(t *testing.T, uname string) string {
+	s, err := fetchSecret(uname)
+	if err != nil || s == nil {
+		t.Fatalf("no secret of %s: %v", uname, err)
+	}
+	key, err := b32.DecodeString(s.Seed)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return totp(key, timeNow(), digits, sha1.New)
+}
+
+// move clock to next time step, so a new code is accepted
+func nextStep() {
+	now := timeNow().Add(period * time.Second)
+	timeNow = func() time.Time { return now }
+}
+
+func TestEnrollFlow(t *testing.T) {
+	defer func() { timeNow = time.Now }()
+	nextStep()
+
+	user := &u.User{Core: u.Core{UName: uniq("m-alice")}}
+
+	seed, uri, err := Enroll(user.UName)
+	if err != nil {
+		t.Fatal(err)
+	}
+	pu, err := url.Parse(uri)
+	if err != nil || pu.Scheme != "otpauth" || pu.Host != "totp" || pu.Query().Get("secret") != seed || pu.Query().Get("issuer") != issuer {
+		t.Fatalf("unexpected provisioning uri: %s", uri)
+	}
+
+	if err := Verify(user.UName, codeOf(t, user.UName)); err != errNotEnabled {
+		t.Fatalf("pending secret should not verify, got %v", err)
+	}
+	if _, err := Confirm(user.UName, "000000"); err != ErrCode {
+		t.Fatalf("wrong code should not confirm, got %v", err)
+	}
+	code := codeOf(t, user.UName)
+	recovery, err := Confirm(user.UName, code)
+	if err != nil || len(recovery) != nRecovery {
+		t.Fatalf("confirm: %v, recovery: %v", err, recovery)
+	}
+	if _, _, err := Enroll(user.UName); err != errEnabled {
+		t.Fatalf("enabled 2fa should not re-enroll, got %v", err)
+	}
+
+	// code used by confirming is not accepted again
+	if err := Verify(user.UName, code); err != ErrCode {
+		t.Fatalf("replayed code should fail, got %v", err)
+	}
+	nextStep()
+	if err := Verify(user.UName, codeOf(t, user.UName)); err != nil {
+		t.Fatal(err)
+	}
+
+	// recovery code works once
+	if err := Verify(user.UName, strings.ToUpper(recovery[0])); err != nil {
+		t.Fatal(err)
+	}
+	if err := Verify(user.UName, recovery[0]); err != ErrCode {
+		t.Fatalf("used recovery code should fail, got %v", err)
+	}
+	st, err := FetchStatus(user)
+	if err != nil || !st.Enabled || st.Recovery != nRecovery-1 {
+		t.Fatalf("unexpected status %+v, %v", st, err)
+	}
+
+	if err := Disable(user, recovery[1]); err != nil {
+		t.Fatal(err)
+	}
+	if st, _ := FetchStatus(user); st.Enabled || st.Pending {
+		t.Fatalf("2fa should be off, got %+v", st)
+	}
+}
+
+func TestChallenge(t *testing.T) {
+	defer func() { timeNow = time.Now }()
+	nextStep()
+
+	user := &u.User{Core: u.Core{UName: uniq("m-bob")}}
+	if mode, _, _, _, err := Challenge(user); err != nil || mode != "" {
+		t.Fatalf("no challenge for user without 2fa, got '%s' %v", mode, err)
+	}
+
+	Enroll(user.UName)
+	if _, err := Confirm(user.UName, codeOf(t, user.UName)); err != nil {
+		t.Fatal(err)
+	}
+	mode, token, _, _, err := Challenge(user)
+	if err != nil || mode != "totp" || len(token) == 0 {
+		t.Fatalf("unexpected challenge '%s' %v", mode, err)
+	}
+	for i := 0; i < challengeTry; i++ {
+		if _, _, err := Answer(token, "000000"); err != ErrCode {
+			t.Fatalf("wrong code should fail, got %v", err)
+		}
+	}
+	nextStep()
+	if _, _, err := Answer(token, codeOf(t, user.UName)); err != ErrChallenge {
+		t.Fatalf("challenge should be dropped after %d tries, got %v", challengeTry, err)
+	}
+
+	_, token, _, _, _ = Challenge(user)
+	uname, recovery, err := Answer(token, codeOf(t, user.UName))
+	if err != nil || uname != user.UName || recovery != nil {
+		t.Fatalf("answer: %s %v %v", uname, recovery, err)
+	}
+	if _, _, err := Answer(token, codeOf(t, user.UName)); err != ErrChallenge {
+		t.Fatalf("answered challenge should be dropped, got %v", err)
+	}
+
+	_, token, _, _, _ = Challenge(user)
+	nextStep()
+	timeNow = func(now time.Time) func() time.Time {
+		return func() time.Time { return now.Add(challengeTTL + time.Second) }
+	}(timeNow())
+	if _, _, err := Answer(token, codeOf(t, user.UName)); err != ErrChallenge {
+		t.Fatalf("expired challenge should fail, got %v", err)
+	}
+}
+
+func TestRequireAdmin(t *testing.T) {
+	defer func() { timeNow = time.Now }()
+	defer SetRequireAdmin(false)
+	nextStep()
+
+	admin := &u.User{Core: u.Core{UName: uniq("m-admin")}, Admin: u.Admin{MemLevel: 3}}
+	member := &u.User{Core: u.Core{UName: uniq("m-member")}, Admin: u.Admin{MemLevel: 1}}
+
+	if err := SetRequireAdmin(true); err != nil {
+		t.Fatal(err)
+	}
+	if mode, _, _, _, _ := Challenge(member); mode != "" {
+		t.Fatalf("member should not be required, got '%s'", mode)
+	}
+	mode, token, seed, uri, err := Challenge(admin)
+	if err != nil || mode != "enroll" || len(seed) == 0 || len(uri) == 0 {
+		t.Fatalf("admin should enroll at sign-in, got '%s' %v", mode, err)
+	}
+	if _, _, again, _, _ := Challenge(admin); again != seed {
+		t.Fatal("pending secret should be kept by next sign-in")
+	}
+	uname, recovery, err := Answer(token, codeOf(t, admin.UName))
+	if err != nil || uname != admin.UName || len(recovery) != nRecovery {
+		t.Fatalf("enroll answer: %s %v %v", uname, recovery, err)
+	}
+	if mode, _, _, _, _ := Challenge(admin); mode != "totp" {
+		t.Fatalf("enrolled admin should answer totp, got '%s'", mode)
+	}
+	if err := Disable(admin, recovery[0]); err != errRequired {
+		t.Fatalf("required 2fa should not be disabled, got %v", err)
+	}
+}
@@ -0,0 +1,58 @@
+package mfa
+
+import (
+	"crypto/hmac"
+	"crypto/sha1"
+	"encoding/binary"
+	"fmt"
+	"hash"
+	"time"
+)
+
+// TOTP (RFC 6238) on HOTP (RFC 4226). authenticator apps use SHA1, 6 digits & 30 seconds step
+
+const (
+	digits = 6
+	period = 30 // seconds
+	skew   = 1  // accepted steps before & after current one, for clock drift
+)
+
+// HOTP value of [key] at [counter]
+func hotp(key []byte, counter uint64, n int, h func() hash.Hash) string {
+	msg := make([]byte, 8)
+	binary.BigEndian.PutUint64(msg, counter)
+	mac := hmac.New(h, key)
+	mac.Write(msg)
+	sum := mac.Sum(nil)
+
+	// dynamic truncation
+	offset := sum[len(sum)-1] & 0x0f
+	bin := binary.BigEndian.Uint32(sum[offset:offset+4]) & 0x7fffffff
+
+	mod := uint32(1)
+	for i := 0; i < n; i++ {
+		mod *= 10
+	}
+	return fmt.Sprintf("%0*d", n, bin%mod)
+}
+
+// time step of [t]
+func step(t time.Time) int64 {
+	return t.Unix() / period
+}
+
+// TOTP value of [key] at [t]
+func totp(key []byte, t time.Time, n int, h func() hash.Hash) string {
+	return hotp(key, uint64(step(t)), n, h)
+}
+
+// matched time step of [code] for [key] around [t], which must be after [last] step to prevent replay
+func matchStep(key []byte, code string, t time.Time, last int64) (int64, bool) {
+	now := step(t)
+	for s := now - skew; s <= now+skew; s++ {
+		if s > last && s >= 0 && hmac.Equal([]byte(hotp(key, uint64(s), digits, sha1.New)), []byte(code)) {
+			return s, true
+		}
+	}
+	return 0, false
+}
@@ -0,0 +1,63 @@
+package mfa
+
+import (
+	"crypto/sha1"
+	"crypto/sha256"
+	"crypto/sha512"
+	"hash"
+	"strings"
+	"testing"
+	"time"
+)
+
+// RFC 6238 Appendix B
+func TestTOTPVectors(t *testing.T) {
+	seed := "12345678901234567890"
+	algos := []struct {
+		name string
+		key  []byte
+		h    func() hash.Hash
+	}{
+		{"SHA1", []byte(seed), sha1.New},
+		{"SHA256", []byte(strings.Repeat(seed, 2)[:32]), sha256.New},
+		{"SHA512", []byte(strings.Repeat(seed, 4)[:64]), sha512.New},
+	}
+	vectors := []struct {
+		unix  int64
+		codes [3]string
+	}{
+		{59, [3]string{"94287082", "46119246", "90693936"}},
+		{1111111109, [3]string{"07081804", "68084774", "25091201"}},
+		{1111111111, [3]string{"14050471", "67062674", "99943326"}},
+		{1234567890, [3]string{"89005924", "91819424", "93441116"}},
+		{2000000000, [3]string{"69279037", "90698825", "38618901"}},
+		{20000000000, [3]string{"65353130", "77737706", "47863826"}},
+	}
+	for _, v := range vectors {
+		for i, a := range algos {
+			if got := totp(a.key, time.Unix(v.unix, 0), 8, a.h); got != v.codes[i] {
+				t.Errorf("%s @%d: got %s, want %s", a.name, v.unix, got, v.codes[i])
+			}
+		}
+	}
+}
+
+func TestMatchStep(t *testing.T) {
+	key := []byte("12345678901234567890")
+	now := time.Unix(1234567890, 0)
+	code := totp(key, now, digits, sha1.New)
+
+	st, ok := matchStep(key, code, now, 0)
+	if !ok || st != step(now) {
+		t.Fatalf("current code should match, got %d %v", st, ok)
+	}
+	if _, ok := matchStep(key, code, now, st); ok {
+		t.Fatal("replayed code should not match")
+	}
+	if _, ok := matchStep(key, code, now.Add(period*time.Second), 0); !ok {
+		t.Fatal("code of previous step should match for clock drift")
+	}
+	if _, ok := matchStep(key, code, now.Add(3*period*time.Second), 0); ok {
+		t.Fatal("code out of skew should not match")
+	}
+}
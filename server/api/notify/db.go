@@ -0,0 +1,59 @@
+package notify
+
+import (
+	"path/filepath"
+	"sync"
+
+	"github.com/dgraph-io/badger/v3"
+	. "github.com/digisan/go-generics/v2"
+	lk "github.com/digisan/logkit"
+)
+
+type DBGrp struct {
+	sync.Mutex
+	Inbox *badger.DB // uname + notification id : notification
+	Pref  *badger.DB // uname : notification preference
+}
+
+var (
+	onceDB sync.Once // do once
+	DbGrp  *DBGrp    // global, for keeping single instance
+)
+
+func open(dir string) *badger.DB {
+	opt := badger.DefaultOptions("").WithInMemory(true)
+	if dir != "" {
+		opt = badger.DefaultOptions(dir)
+		opt.Logger = nil
+	}
+	db, err := badger.Open(opt)
+	lk.FailOnErr("%v", err)
+	return db
+}
+
+// init global 'DbGrp'. if [dir] is empty, use in-memory db
+func InitDB(dir string) *DBGrp {
+	if DbGrp == nil {
+		onceDB.Do(func() {
+			DbGrp = &DBGrp{
+				Inbox: open(IF(dir == "", "", filepath.Join(dir, "notify-inbox"))),
+				Pref:  open(IF(dir == "", "", filepath.Join(dir, "notify-pref"))),
+			}
+		})
+	}
+	return DbGrp
+}
+
+func CloseDB() {
+	DbGrp.Lock()
+	defer DbGrp.Unlock()
+
+	if DbGrp.Inbox != nil {
+		lk.FailOnErr("%v", DbGrp.Inbox.Close())
+		DbGrp.Inbox = nil
+	}
+	if DbGrp.Pref != nil {
+		lk.FailOnErr("%v", DbGrp.Pref.Close())
+		DbGrp.Pref = nil
+	}
+}
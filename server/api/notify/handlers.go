@@ -0,0 +1,206 @@
+package notify
+
+import (
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+
+	u "github.com/digisan/user-mgr/user"
+	"github.com/golang-jwt/jwt/v4"
+	"github.com/labstack/echo/v4"
+)
+
+// *** after implementing, register with path in 'notify.go' *** //
+
+const (
+	defaultPageSize = 20
+	maxPageSize     = 100
+)
+
+// @Title list notifications
+// @Summary list own notifications, newest first. they are also pushed by websocket '/ws/msg' when connected.
+// @Description
+// @Tags    Notify
+// @Accept  json
+// @Produce json
+// @Param   unread query boolean false "true: only unread ones"
+// @Param   limit  query int     false "max count, default is 20, max is 100"
+// @Param   before query string  false "notification id, only older ones than it"
+// @Success 200 "OK - list successfully"
+// @Failure 400 "Fail - incorrect query param"
+// @Failure 500 "Fail - internal error"
+// @Router /api/notify/list [get]
+// @Security ApiKeyAuth
+func List(c echo.Context) error {
+	var (
+		userTkn = c.Get("user").(*jwt.Token)
+		claims  = userTkn.Claims.(*u.UserClaims)
+		uname   = claims.UName
+		before  = c.QueryParam("before")
+		limit   = defaultPageSize
+	)
+	if s := c.QueryParam("limit"); len(s) > 0 {
+		n, err := strconv.Atoi(s)
+		if err != nil || n < 1 || n > maxPageSize {
+			return c.String(http.StatusBadRequest, fmt.Sprintf("'limit' must be an integer in [1, %d]", maxPageSize))
+		}
+		limit = n
+	}
+	unread, _ := strconv.ParseBool(c.QueryParam("unread"))
+
+	ns, err := Inbox(uname, unread)
+	if err != nil {
+		return c.String(http.StatusInternalServerError, err.Error())
+	}
+	page := []*Notification{}
+	for _, n := range ns {
+		if len(page) == limit {
+			break
+		}
+		if len(before) == 0 || n.ID < before {
+			page = append(page, n)
+		}
+	}
+	return c.JSON(http.StatusOK, page)
+}
+
+// @Title unread notification count
+// @Summary get count of own unread notifications.
+// @Description
+// @Tags    Notify
+// @Accept  json
+// @Produce json
+// @Success 200 "OK - get count successfully"
+// @Failure 500 "Fail - internal error"
+// @Router /api/notify/unread-count [get]
+// @Security ApiKeyAuth
+func Unread(c echo.Context) error {
+	var (
+		userTkn = c.Get("user").(*jwt.Token)
+		claims  = userTkn.Claims.(*u.UserClaims)
+		uname   = claims.UName
+	)
+	n, err := UnreadCount(uname)
+	if err != nil {
+		return c.String(http.StatusInternalServerError, err.Error())
+	}
+	return c.JSON(http.StatusOK, n)
+}
+
+// @Title mark notification read
+// @Summary mark one own notification as read, or all of them if id is 'all'.
+// @Description
+// @Tags    Notify
+// @Accept  json
+// @Produce json
+// @Param   id path string true "notification id, or 'all'"
+// @Success 200 "OK - mark successfully, return count of newly read"
+// @Failure 404 "Fail - notification not found"
+// @Failure 500 "Fail - internal error"
+// @Router /api/notify/read/{id} [put]
+// @Security ApiKeyAuth
+func Read(c echo.Context) error {
+	var (
+		userTkn = c.Get("user").(*jwt.Token)
+		claims  = userTkn.Claims.(*u.UserClaims)
+		uname   = claims.UName
+		id      = c.Param("id")
+	)
+	if id == "all" {
+		id = ""
+	}
+	n, err := MarkRead(uname, id)
+	switch {
+	case err == errNotifyMissing:
+		return c.String(http.StatusNotFound, fmt.Sprintf("notification not found @%s", id))
+	case err != nil:
+		return c.String(http.StatusInternalServerError, err.Error())
+	}
+	return c.JSON(http.StatusOK, n)
+}
+
+// @Title delete notification
+// @Summary delete one own notification.
+// @Description
+// @Tags    Notify
+// @Accept  json
+// @Produce json
+// @Param   id path string true "notification id"
+// @Success 200 "OK - delete successfully"
+// @Failure 404 "Fail - notification not found"
+// @Failure 500 "Fail - internal error"
+// @Router /api/notify/del/{id} [delete]
+// @Security ApiKeyAuth
+func Del(c echo.Context) error {
+	var (
+		userTkn = c.Get("user").(*jwt.Token)
+		claims  = userTkn.Claims.(*u.UserClaims)
+		uname   = claims.UName
+		id      = c.Param("id")
+	)
+	switch err := Delete(uname, id); {
+	case err == errNotifyMissing:
+		return c.String(http.StatusNotFound, fmt.Sprintf("notification not found @%s", id))
+	case err != nil:
+		return c.String(http.StatusInternalServerError, err.Error())
+	}
+	return c.JSON(http.StatusOK, fmt.Sprintf("notification deleted @%s", id))
+}
+
+// @Title get muted notification types
+// @Summary get own muted notification types.
+// @Description
+// @Tags    Notify
+// @Accept  json
+// @Produce json
+// @Success 200 "OK - get successfully"
+// @Failure 500 "Fail - internal error"
+// @Router /api/notify/mute [get]
+// @Security ApiKeyAuth
+func GetMute(c echo.Context) error {
+	var (
+		userTkn = c.Get("user").(*jwt.Token)
+		claims  = userTkn.Claims.(*u.UserClaims)
+		uname   = claims.UName
+	)
+	pref, err := FetchPref(uname)
+	if err != nil {
+		return c.String(http.StatusInternalServerError, err.Error())
+	}
+	return c.JSON(http.StatusOK, pref.Muted)
+}
+
+// @Title set muted notification types
+// @Summary replace own muted notification types. muted types are neither recorded nor pushed.
+// @Description
+// @Tags    Notify
+// @Accept  json
+// @Produce json
+// @Param   types query string false "comma separated types from [follow, comment, reaction, mention, share], empty to unmute all"
+// @Success 200 "OK - set successfully"
+// @Failure 400 "Fail - invalid type"
+// @Failure 500 "Fail - internal error"
+// @Router /api/notify/mute [put]
+// @Security ApiKeyAuth
+func SetMute(c echo.Context) error {
+	var (
+		userTkn = c.Get("user").(*jwt.Token)
+		claims  = userTkn.Claims.(*u.UserClaims)
+		uname   = claims.UName
+		types   = []string{}
+	)
+	for _, t := range strings.Split(c.QueryParam("types"), ",") {
+		if t = strings.ToLower(strings.TrimSpace(t)); len(t) > 0 {
+			types = append(types, t)
+		}
+	}
+	pref, err := SetMuted(uname, types...)
+	switch {
+	case err == errMuteType:
+		return c.String(http.StatusBadRequest, err.Error())
+	case err != nil:
+		return c.String(http.StatusInternalServerError, err.Error())
+	}
+	return c.JSON(http.StatusOK, pref.Muted)
+}
@@ -0,0 +1,238 @@
+package notify
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"sort"
+	"time"
+
+	"github.com/dgraph-io/badger/v3"
+	bh "github.com/digisan/db-helper/badger"
+	. "github.com/digisan/go-generics/v2"
+	lk "github.com/digisan/logkit"
+	r "github.com/digisan/user-mgr/relation"
+	"github.com/google/uuid"
+	"github.com/wismed-web/wisite-api/server/ws"
+)
+
+// persistent per-user inbox. a notification is kept until deleted, and pushed by ws if receiver is connected
+
+const SEP = "^"
+
+// notification types
+const (
+	Follow   = "follow"   // new follower, target is follower uname
+	Comment  = "comment"  // comment on my Post, target is comment Post id
+	Reaction = "reaction" // reaction on my Post, target is Post id
+	Mention  = "mention"  // @uname in a Post, target is Post id
+	Share    = "share"    // my Post is shared, target is Post id
+	Admin    = "admin"    // admin action on my Post or account, cannot be muted
+)
+
+var (
+	Types = []string{Follow, Comment, Reaction, Mention, Share, Admin}
+
+	// [uname] has blocked [actor], variable for replacing in test
+	fnBlocked = func(uname, actor string) (bool, error) {
+		blocked, err := r.ListRel(uname, r.BLOCKED, true)
+		return In(actor, blocked...), err
+	}
+
+	errNotifyMissing = errors.New("notification is not existing")
+	errMuteType      = fmt.Errorf("mutable types are %v", Types[:len(Types)-1])
+)
+
+// key: receiver uname ^ notification id;
+// value: json of one notification
+type Notification struct {
+	ID     string    `json:"id"` // time ordered
+	Uname  string    `json:"uname"`
+	Type   string    `json:"type"` // one of [Types]
+	Actor  string    `json:"actor"`
+	Target string    `json:"target"`
+	Text   string    `json:"text"`
+	Tm     time.Time `json:"tm"`
+	Read   bool      `json:"read"`
+}
+
+func (n Notification) String() string {
+	return fmt.Sprintf("%s to %s: %s by %s on %s @%v, read: %v, %s", n.ID, n.Uname, n.Type, n.Actor, n.Target, n.Tm, n.Read, n.Text)
+}
+
+func (n *Notification) BadgerDB() *badger.DB {
+	return DbGrp.Inbox
+}
+
+func (n *Notification) Key() []byte {
+	return []byte(n.Uname + SEP + n.ID)
+}
+
+func (n *Notification) Marshal(at any) (forKey, forValue []byte) {
+	forKey = n.Key()
+	forValue, err := json.Marshal(n)
+	lk.FailOnErr("%v", err)
+	return
+}
+
+func (n *Notification) Unmarshal(dbKey, dbVal []byte) (any, error) {
+	if err := json.Unmarshal(dbVal, n); err != nil {
+		return nil, err
+	}
+	return n, nil
+}
+
+// key: uname;
+// value: json of notification preference
+type Pref struct {
+	Uname string   `json:"uname"`
+	Muted []string `json:"muted"` // muted notification types
+}
+
+func (p *Pref) BadgerDB() *badger.DB {
+	return DbGrp.Pref
+}
+
+func (p *Pref) Key() []byte {
+	return []byte(p.Uname)
+}
+
+func (p *Pref) Marshal(at any) (forKey, forValue []byte) {
+	forKey = p.Key()
+	forValue, err := json.Marshal(p)
+	lk.FailOnErr("%v", err)
+	return
+}
+
+func (p *Pref) Unmarshal(dbKey, dbVal []byte) (any, error) {
+	if err := json.Unmarshal(dbVal, p); err != nil {
+		return nil, err
+	}
+	return p, nil
+}
+
+// hex unix nano (fixed width) + random suffix, so ids of one user sort by time
+func newID(tm time.Time) string {
+	return fmt.Sprintf("%016x-%s", tm.UnixNano(), uuid.NewString()[:8])
+}
+
+// preference of [uname], default one if not set
+func FetchPref(uname string) (*Pref, error) {
+	p, err := bh.GetOneObject[Pref]([]byte(uname))
+	if err != nil {
+		return nil, err
+	}
+	if p == nil {
+		p = &Pref{Uname: uname, Muted: []string{}}
+	}
+	return p, nil
+}
+
+// replace muted types of [uname]. [Admin] type cannot be muted
+func SetMuted(uname string, types ...string) (*Pref, error) {
+	types = Settify(types...)
+	for _, t := range types {
+		if t == Admin || NotIn(t, Types...) {
+			return nil, errMuteType
+		}
+	}
+	sort.Strings(types)
+	p := &Pref{Uname: uname, Muted: types}
+	return p, bh.UpsertOneObject(p)
+}
+
+// record a [typ] notification to [uname] caused by [actor], and push it if [uname] is connected.
+// nothing if [uname] is [actor] self, or [uname] muted [typ] or blocked [actor]. return nil notification if not recorded
+func Push(uname, typ, actor, target, text string) (*Notification, error) {
+	if NotIn(typ, Types...) {
+		return nil, fmt.Errorf("invalid notification type '%s'", typ)
+	}
+	if len(uname) == 0 || uname == actor {
+		return nil, nil
+	}
+	if typ != Admin {
+		pref, err := FetchPref(uname)
+		if err != nil {
+			return nil, err
+		}
+		if In(typ, pref.Muted...) {
+			return nil, nil
+		}
+		blocked, err := fnBlocked(uname, actor)
+		if err != nil {
+			return nil, err
+		}
+		if blocked {
+			return nil, nil
+		}
+	}
+
+	now := time.Now()
+	n := &Notification{
+		ID:     newID(now),
+		Uname:  uname,
+		Type:   typ,
+		Actor:  actor,
+		Target: target,
+		Text:   text,
+		Tm:     now,
+	}
+	if err := bh.UpsertOneObject(n); err != nil {
+		return nil, err
+	}
+	go ws.SendMsg(uname, n)
+	return n, nil
+}
+
+// notifications of [uname], newest first. if [unreadOnly], only unread ones
+func Inbox(uname string, unreadOnly bool) ([]*Notification, error) {
+	ns, err := bh.GetObjects[Notification]([]byte(uname+SEP), func(n *Notification) bool { return !unreadOnly || !n.Read })
+	if err != nil {
+		return nil, err
+	}
+	sort.SliceStable(ns, func(i, j int) bool { return ns[i].ID > ns[j].ID })
+	return ns, nil
+}
+
+func UnreadCount(uname string) (int, error) {
+	ns, err := Inbox(uname, true)
+	return len(ns), err
+}
+
+// mark notification [id] of [uname] as read. if [id] is empty, mark all. return count of newly read
+func MarkRead(uname, id string) (int, error) {
+	var ns []*Notification
+	if len(id) == 0 {
+		var err error
+		if ns, err = Inbox(uname, true); err != nil {
+			return 0, err
+		}
+	} else {
+		n, err := bh.GetOneObject[Notification]((&Notification{Uname: uname, ID: id}).Key())
+		if err != nil {
+			return 0, err
+		}
+		if n == nil {
+			return 0, errNotifyMissing
+		}
+		if !n.Read {
+			ns = append(ns, n)
+		}
+	}
+	for _, n := range ns {
+		n.Read = true
+		if err := bh.UpsertOneObject(n); err != nil {
+			return 0, err
+		}
+	}
+	return len(ns), nil
+}
+
+// delete notification [id] of [uname]
+func Delete(uname, id string) error {
+	n, err := bh.DeleteOneObject[Notification]((&Notification{Uname: uname, ID: id}).Key())
+	if err == nil && n == 0 {
+		return errNotifyMissing
+	}
+	return err
+}
@@ -0,0 +1,92 @@
+package notify
+
+import (
+	"os"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+func TestMain(m *testing.M) {
+	InitDB("")
+	os.Exit(m.Run())
+}
+
+// unique [name] in one test run, so records of earlier runs (e.g. by -count=2) are never met
+func uniq(name string) string {
+	return name + "-" + uuid.NewString()[:8]
+}
+
+func TestInbox(t *testing.T) {
+	var (
+		alice   = uniq("n-alice")
+		mallory = uniq("n-mallory")
+		bob     = uniq("n-bob")
+		carol   = uniq("n-carol")
+		admin   = uniq("n-admin")
+	)
+	fnBlocked = func(uname, actor string) (bool, error) {
+		return uname == alice && actor == mallory, nil
+	}
+
+	push := func(typ, actor string) *Notification {
+		n, err := Push(alice, typ, actor, "target", "")
+		if err != nil {
+			t.Fatal(err)
+		}
+		time.Sleep(time.Millisecond) // distinct time ordered ids
+		return n
+	}
+
+	first := push(Follow, bob)
+	second := push(Comment, carol)
+	if push(Reaction, alice) != nil || push(Follow, mallory) != nil {
+		t.Fatal("self action & action from blocked user should not be notified")
+	}
+	if _, err := Push(alice, "unknown", bob, "", ""); err == nil {
+		t.Fatal("unknown type should be refused")
+	}
+
+	if _, err := SetMuted(alice, Admin); err != errMuteType {
+		t.Fatalf("admin type cannot be muted, got %v", err)
+	}
+	if _, err := SetMuted(alice, Reaction, Reaction); err != nil {
+		t.Fatal(err)
+	}
+	if push(Reaction, bob) != nil {
+		t.Fatal("muted type should not be notified")
+	}
+	third := push(Admin, admin)
+
+	ns, err := Inbox(alice, false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(ns) != 3 || ns[0].ID != third.ID || ns[2].ID != first.ID {
+		t.Fatalf("want 3 notifications newest first, got %v", ns)
+	}
+
+	if n, err := MarkRead(alice, second.ID); err != nil || n != 1 {
+		t.Fatalf("mark one read: %d %v", n, err)
+	}
+	if n, err := UnreadCount(alice); err != nil || n != 2 {
+		t.Fatalf("want 2 unread, got %d %v", n, err)
+	}
+	if n, err := MarkRead(alice, ""); err != nil || n != 2 {
+		t.Fatalf("mark all read: %d %v", n, err)
+	}
+	if _, err := MarkRead(alice, "missing"); err != errNotifyMissing {
+		t.Fatalf("missing notification: %v", err)
+	}
+
+	if err := Delete(alice, first.ID); err != nil {
+		t.Fatal(err)
+	}
+	if err := Delete(alice, first.ID); err != errNotifyMissing {
+		t.Fatalf("deleted notification should be missing, got %v", err)
+	}
+	if ns, _ := Inbox(alice, false); len(ns) != 2 {
+		t.Fatalf("want 2 notifications after deleting, got %d", len(ns))
+	}
+}
@@ -20,22 +20,51 @@ func PostHandler(e *echo.Group) {
 		"/thumbsup/status/:id": post.ThumbsUpStatus,
 		"/bookmark/status/:id": post.BookmarkStatus,
 		"/bookmark/bookmarked": post.BookmarkedPosts,
+		"/revision/list/:id":   post.RevisionList,
+		"/revision/one/:id":    post.RevisionOne,
+		"/audit":               post.AuditList,
+		"/search":              post.SearchPosts,
+		"/feed":                post.Feed,
+		"/thread/:id":          post.Thread,
+		"/react/types":         post.ReactionTypes,
+		"/react/status/:id":    post.ReactionStatus,
+		"/react/list/:id":      post.Reactors,
+		"/draft/list":          post.DraftList,
+		"/draft/one/:id":       post.DraftOne,
+		"/category/list":       post.CategoryList,
+		"/category/ids/:id":    post.CategoryPosts,
+		"/tag/list":            post.TagList,
+		"/tag/ids/:tag":        post.TagPosts,
+		"/tag/trending":        post.TrendingTagList,
+		"/warnings":            post.WarningList,
+		"/mentions":            post.MentionList,
+		"/analytics":           post.Analytics,
+		"/share/ids/:id":       post.ShareList,
 	}
 
 	var mPOST = map[string]echo.HandlerFunc{
-		"/upload": post.Upload,
+		"/upload":     post.Upload,
+		"/many":       post.GetMany,
+		"/draft/new":  post.NewDraft,
+		"/report/:id": post.ReportPost,
+		"/share/:id":  post.SharePost,
 	}
 
-	var mPUT = map[string]echo.HandlerFunc{}
+	var mPUT = map[string]echo.HandlerFunc{
+		"/edit/:id":         post.Edit,
+		"/draft/update/:id": post.UpdateDraft,
+	}
 
 	var mDELETE = map[string]echo.HandlerFunc{
-		"/del/one":   post.DelOne,
-		"/erase/one": post.EraseOne,
+		"/del/one":       post.DelOne,
+		"/erase/one":     post.EraseOne,
+		"/draft/del/:id": post.DelDraft,
 	}
 
 	var mPATCH = map[string]echo.HandlerFunc{
 		"/thumbsup/:id": post.ThumbsUp,
 		"/bookmark/:id": post.Bookmark,
+		"/react/:id":    post.React,
 	}
 
 	// ------------------------------------------------------- //
@@ -0,0 +1,435 @@
+package post
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/dgraph-io/badger/v3"
+	bh "github.com/digisan/db-helper/badger"
+	em "github.com/digisan/event-mgr"
+	. "github.com/digisan/go-generics/v2"
+	lk "github.com/digisan/logkit"
+)
+
+// Post views are de-duplicated per user per Post. [recordView] only buffers in memory,
+// views are written in batch by [runViewFlusher], so fetching Post is not slowed down
+
+const (
+	pfxViewer = "viewer" + SEP // key prefix of [Viewer], stored with [ViewCount] in one db for transaction
+	pfxCount  = "count" + SEP  // key prefix of [ViewCount]
+
+	viewFlushInterval  = 10 * time.Second
+	viewBatchSize      = 1000 // flush early when so many views are pending
+	maxAnalyticsMonths = 12
+)
+
+type pendingView struct {
+	owner string
+	tm    time.Time
+}
+
+var (
+	mtxView     = &sync.Mutex{}            // guard [mPendView]
+	mPendView   = map[string]pendingView{} // post id ^ viewer : pending view
+	chViewFlush = make(chan struct{}, 1)   // signal early flushing
+	mtxFlush    = &sync.Mutex{}            // flushing is exclusive
+
+	// own event ids in a month, indexed when event span is flushed. variable for replacing in test
+	fnFetchOwn = em.FetchOwn
+)
+
+// key: "viewer" ^ post id ^ viewer;
+// value: json of one viewer's first view on a Post
+type Viewer struct {
+	PostID string    `json:"postId"`
+	Uname  string    `json:"uname"`
+	Tm     time.Time `json:"tm"`
+}
+
+func (v *Viewer) BadgerDB() *badger.DB {
+	return DbGrp.View
+}
+
+func (v *Viewer) Key() []byte {
+	return []byte(pfxViewer + v.PostID + SEP + v.Uname)
+}
+
+func (v *Viewer) Marshal(at any) (forKey, forValue []byte) {
+	forKey = v.Key()
+	forValue, err := json.Marshal(v)
+	lk.FailOnErr("%v", err)
+	return
+}
+
+func (v *Viewer) Unmarshal(dbKey, dbVal []byte) (any, error) {
+	if err := json.Unmarshal(dbVal, v); err != nil {
+		return nil, err
+	}
+	return v, nil
+}
+
+// key: "count" ^ post id ^ day(yyyymmdd);
+// value: json of unique view count of a Post on a day
+type ViewCount struct {
+	PostID string `json:"postId"`
+	Owner  string `json:"owner"`
+	Day    string `json:"day"`
+	Count  int    `json:"count"`
+}
+
+func (vc ViewCount) String() string {
+	return fmt.Sprintf("%s(%s) @%s: %d", vc.PostID, vc.Owner, vc.Day, vc.Count)
+}
+
+func (vc *ViewCount) BadgerDB() *badger.DB {
+	return DbGrp.View
+}
+
+func (vc *ViewCount) Key() []byte {
+	return []byte(pfxCount + vc.PostID + SEP + vc.Day)
+}
+
+func (vc *ViewCount) Marshal(at any) (forKey, forValue []byte) {
+	forKey = vc.Key()
+	forValue, err := json.Marshal(vc)
+	lk.FailOnErr("%v", err)
+	return
+}
+
+func (vc *ViewCount) Unmarshal(dbKey, dbVal []byte) (any, error) {
+	if err := json.Unmarshal(dbVal, vc); err != nil {
+		return nil, err
+	}
+	return vc, nil
+}
+
+// buffer a view of [viewer] on Post [id] of [owner]. owner's own view is not counted
+func recordView(id, owner, viewer string) {
+	if owner == viewer || len(viewer) == 0 {
+		return
+	}
+	mtxView.Lock()
+	key := id + SEP + viewer
+	if _, ok := mPendView[key]; !ok {
+		mPendView[key] = pendingView{owner, time.Now()}
+	}
+	n := len(mPendView)
+	mtxView.Unlock()
+
+	if n >= viewBatchSize {
+		select {
+		case chViewFlush <- struct{}{}:
+		default:
+		}
+	}
+}
+
+// write buffered views, only first view of each viewer on a Post is counted.
+// new viewers & their counts are written in one transaction, if it fails, nothing is written and all views are pending again
+func flushViews() error {
+	mtxFlush.Lock()
+	defer mtxFlush.Unlock()
+
+	mtxView.Lock()
+	pending := mPendView
+	mPendView = map[string]pendingView{}
+	mtxView.Unlock()
+
+	if len(pending) == 0 {
+		return nil
+	}
+
+	err := DbGrp.View.Update(func(txn *badger.Txn) error {
+		mDelta := map[string]*ViewCount{}
+		for key, pv := range pending {
+			id, viewer, _ := strings.Cut(key, SEP)
+			v := &Viewer{PostID: id, Uname: viewer, Tm: pv.tm}
+			switch _, err := txn.Get(v.Key()); err {
+			case nil:
+				continue
+			case badger.ErrKeyNotFound:
+			default:
+				return err
+			}
+			if err := txn.Set(v.Marshal(nil)); err != nil {
+				return err
+			}
+			vc := &ViewCount{PostID: id, Owner: pv.owner, Day: pv.tm.Format("20060102")}
+			if delta, ok := mDelta[string(vc.Key())]; ok {
+				delta.Count++
+			} else {
+				vc.Count = 1
+				mDelta[string(vc.Key())] = vc
+			}
+		}
+
+		for key, vc := range mDelta {
+			item, err := txn.Get([]byte(key))
+			switch err {
+			case nil:
+				existing := &ViewCount{}
+				if err := item.Value(func(val []byte) error { return json.Unmarshal(val, existing) }); err != nil {
+					return err
+				}
+				vc.Count += existing.Count
+			case badger.ErrKeyNotFound:
+			default:
+				return err
+			}
+			if err := txn.Set(vc.Marshal(nil)); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		mtxView.Lock()
+		for key, pv := range pending {
+			mPendView[key] = pv // earlier view is kept
+		}
+		mtxView.Unlock()
+	}
+	return err
+}
+
+// flush buffered views every [viewFlushInterval] or when batch is full, and at last when [ctx] is done
+func runViewFlusher(ctx context.Context) {
+	ticker := time.NewTicker(viewFlushInterval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			lk.WarnOnErr("%v", flushViews())
+			lk.Log("post view flusher stopped")
+			return
+		case <-ticker.C:
+		case <-chViewFlush:
+		}
+		lk.WarnOnErr("%v", flushViews())
+	}
+}
+
+// unique view count of Post [id] by day(yyyymmdd)
+func dailyViews(id string) (map[string]int, error) {
+	vcs, err := bh.GetObjects[ViewCount]([]byte(pfxCount+id+SEP), nil)
+	if err != nil {
+		return nil, err
+	}
+	m := map[string]int{}
+	for _, vc := range vcs {
+		m[vc.Day] += vc.Count
+	}
+	return m, nil
+}
+
+// bookmark count of each event id from all users' bookmarks
+func bookmarkCounts() (map[string]int, error) {
+	bms, err := bh.GetObjects[em.Bookmark](nil, nil)
+	if err != nil {
+		return nil, err
+	}
+	m := map[string]int{}
+	for _, bm := range bms {
+		for _, idtm := range bm.EventIDTMs {
+			if id, _, _ := strings.Cut(idtm, "@"); len(id) > 0 {
+				m[id]++
+			}
+		}
+	}
+	return m, nil
+}
+
+// engagement of one Post
+type PostStat struct {
+	ID        string `json:"id"`
+	Owner     string `json:"owner"`
+	Period    string `json:"period"` // created month, yyyymm
+	Views     int    `json:"views"`
+	Reactions int    `json:"reactions"` // all reaction types
+	Bookmarks int    `json:"bookmarks"`
+	Comments  int    `json:"comments"`
+}
+
+func postStat(event *em.Event, views int, mBookmark map[string]int) (*PostStat, error) {
+	stat := &PostStat{
+		ID:        event.ID,
+		Owner:     event.Owner,
+		Period:    event.Tm.Format("200601"),
+		Views:     views,
+		Bookmarks: mBookmark[event.ID],
+	}
+	for _, typ := range Reactions() {
+		n, err := countPtps(event.ID, typ)
+		if err != nil {
+			return nil, err
+		}
+		stat.Reactions += n
+	}
+	flwers, err := em.Followers(event.ID)
+	if err != nil {
+		return nil, err
+	}
+	stat.Comments = len(Filter(flwers, nonEmpty))
+	return stat, nil
+}
+
+// sum of Post stats in one month
+type PeriodStat struct {
+	Period    string `json:"period"` // yyyymm
+	Posts     int    `json:"posts"`
+	Views     int    `json:"views"`
+	Reactions int    `json:"reactions"`
+	Bookmarks int    `json:"bookmarks"`
+	Comments  int    `json:"comments"`
+}
+
+// author analytics on own Posts created in a month range
+type Dashboard struct {
+	Periods    []*PeriodStat  `json:"periods"`    // each month, oldest first
+	Posts      []*PostStat    `json:"posts"`      // most viewed first
+	DailyViews map[string]int `json:"dailyViews"` // yyyymmdd: unique views of these Posts on that day
+}
+
+// months 'yyyymm' from [from] to [to], both included
+func months(from, to time.Time) []string {
+	periods := []string{}
+	for m := time.Date(from.Year(), from.Month(), 1, 0, 0, 0, 0, time.UTC); !m.After(to); m = m.AddDate(0, 1, 0) {
+		periods = append(periods, m.Format("200601"))
+	}
+	return periods
+}
+
+// analytics of [owner]'s alive Posts created in [periods] (yyyymm), by own event index
+func authorDashboard(owner string, periods []string) (*Dashboard, error) {
+	mBookmark, err := bookmarkCounts()
+	if err != nil {
+		return nil, err
+	}
+	dash := &Dashboard{Periods: []*PeriodStat{}, Posts: []*PostStat{}, DailyViews: map[string]int{}}
+	for _, period := range periods {
+		ids, err := fnFetchOwn(owner, period)
+		if err != nil {
+			return nil, err
+		}
+		evts, err := em.FetchEvents(true, ids...)
+		if err != nil {
+			return nil, err
+		}
+		ps := &PeriodStat{Period: period}
+		for _, evt := range evts {
+			if evt.EvtType != "Post" {
+				continue
+			}
+			daily, err := dailyViews(evt.ID)
+			if err != nil {
+				return nil, err
+			}
+			views := 0
+			for day, n := range daily {
+				views += n
+				dash.DailyViews[day] += n
+			}
+			stat, err := postStat(evt, views, mBookmark)
+			if err != nil {
+				return nil, err
+			}
+			dash.Posts = append(dash.Posts, stat)
+			ps.Posts++
+			ps.Views += stat.Views
+			ps.Reactions += stat.Reactions
+			ps.Bookmarks += stat.Bookmarks
+			ps.Comments += stat.Comments
+		}
+		dash.Periods = append(dash.Periods, ps)
+	}
+	sort.SliceStable(dash.Posts, func(i, j int) bool { return dash.Posts[i].Views > dash.Posts[j].Views })
+	return dash, nil
+}
+
+// one author's views in a period
+type AuthorStat struct {
+	Uname string `json:"uname"`
+	Views int    `json:"views"`
+	Posts int    `json:"posts"` // viewed Posts
+}
+
+// site-wide top viewed Posts & authors
+type TopStat struct {
+	Period  string        `json:"period"`
+	Posts   []*PostStat   `json:"posts"`
+	Authors []*AuthorStat `json:"authors"`
+}
+
+// [top] Posts & authors by unique views in [period] (yyyymm). deleted & hidden Posts are excluded
+func topStats(period string, top int) (*TopStat, error) {
+	vcs, err := bh.GetObjects[ViewCount]([]byte(pfxCount), func(vc *ViewCount) bool { return strings.HasPrefix(vc.Day, period) })
+	if err != nil {
+		return nil, err
+	}
+	mView := map[string]int{}
+	for _, vc := range vcs {
+		mView[vc.PostID] += vc.Count
+	}
+	ids, _ := MapToKVs(mView, nil, nil)
+	evts, err := em.FetchEvents(true, visibleIDs(ids)...)
+	if err != nil {
+		return nil, err
+	}
+
+	mBookmark, err := bookmarkCounts()
+	if err != nil {
+		return nil, err
+	}
+	stats := []*PostStat{}
+	mAuthor := map[string]*AuthorStat{}
+	for _, evt := range evts {
+		if evt.EvtType != "Post" {
+			continue
+		}
+		views := mView[evt.ID]
+		as, ok := mAuthor[evt.Owner]
+		if !ok {
+			as = &AuthorStat{Uname: evt.Owner}
+			mAuthor[evt.Owner] = as
+		}
+		as.Views += views
+		as.Posts++
+		stats = append(stats, &PostStat{ID: evt.ID, Owner: evt.Owner, Period: evt.Tm.Format("200601"), Views: views})
+	}
+
+	sort.SliceStable(stats, func(i, j int) bool {
+		if stats[i].Views != stats[j].Views {
+			return stats[i].Views > stats[j].Views
+		}
+		return stats[i].ID < stats[j].ID
+	})
+	if len(stats) > top {
+		stats = stats[:top]
+	}
+	for i, s := range stats { // engagement only for listed ones
+		evt, err := em.FetchEvent(true, s.ID)
+		if err != nil {
+			return nil, err
+		}
+		if stats[i], err = postStat(evt, s.Views, mBookmark); err != nil {
+			return nil, err
+		}
+	}
+
+	_, authors := MapToKVs(mAuthor, nil, nil)
+	sort.SliceStable(authors, func(i, j int) bool {
+		if authors[i].Views != authors[j].Views {
+			return authors[i].Views > authors[j].Views
+		}
+		return authors[i].Uname < authors[j].Uname
+	})
+	if len(authors) > top {
+		authors = authors[:top]
+	}
+	return &TopStat{Period: period, Posts: stats, Authors: authors}, nil
+}
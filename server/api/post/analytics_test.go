@@ -0,0 +1,133 @@
+package post
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+
+	em "github.com/digisan/event-mgr"
+	. "github.com/digisan/go-generics/v2"
+)
+
+func TestViews(t *testing.T) {
+	var (
+		alice = uniq("an-alice")
+		bob   = uniq("an-bob")
+		carol = uniq("an-carol")
+	)
+	id := newTestPost(t, alice)
+	other := newTestPost(t, bob)
+
+	for _, viewer := range []string{bob, carol, bob, alice, ""} {
+		recordView(id, alice, viewer)
+	}
+	recordView(other, bob, carol)
+	if err := flushViews(); err != nil {
+		t.Fatal(err)
+	}
+	recordView(id, alice, carol) // viewed before, not counted again
+	if err := flushViews(); err != nil {
+		t.Fatal(err)
+	}
+
+	daily, err := dailyViews(id)
+	if err != nil {
+		t.Fatal(err)
+	}
+	today := time.Now().Format("20060102")
+	if len(daily) != 1 || daily[today] != 2 {
+		t.Fatalf("want 2 unique views today, got %v", daily)
+	}
+
+	bm, err := em.NewBookmark(carol, true)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := bm.AddEvent(id); err != nil {
+		t.Fatal(err)
+	}
+	if _, _, err := react(id, "Thanks", bob); err != nil {
+		t.Fatal(err)
+	}
+
+	// own index is only flushed with event span
+	fnFetchOwn = func(owner, yyyymm string) ([]string, error) {
+		if owner == alice && yyyymm == time.Now().Format("200601") {
+			return []string{id}, nil
+		}
+		return []string{}, nil
+	}
+	rec := invoke(Analytics, http.MethodGet, alice, "")
+	dash := &Dashboard{}
+	if err := json.Unmarshal(rec.Body.Bytes(), dash); err != nil || rec.Code != http.StatusOK {
+		t.Fatalf("unexpected dashboard: %d %s", rec.Code, rec.Body.String())
+	}
+	if len(dash.Periods) != 1 || dash.Periods[0].Posts != 1 || dash.Periods[0].Views != 2 || dash.DailyViews[today] != 2 {
+		t.Fatalf("unexpected periods: %+v %v", dash.Periods[0], dash.DailyViews)
+	}
+	if p := dash.Posts[0]; p.ID != id || p.Reactions != 1 || p.Bookmarks != 1 || p.Comments != 0 {
+		t.Fatalf("unexpected Post stat: %+v", p)
+	}
+	if rec := invoke(Analytics, http.MethodGet, alice, "from=202301&to=202501"); rec.Code != http.StatusBadRequest {
+		t.Fatalf("too long range should be refused, got %d", rec.Code)
+	}
+
+	if rec := invoke(TopAnalytics, http.MethodGet, alice, ""); rec.Code != http.StatusUnauthorized {
+		t.Fatalf("non-admin should not get top list, got %d", rec.Code)
+	}
+	topList := func(n int) *TopStat {
+		rec := invoke(TopAnalytics, http.MethodGet, "admin", fmt.Sprintf("top=%d", n))
+		top := &TopStat{}
+		if err := json.Unmarshal(rec.Body.Bytes(), top); err != nil || rec.Code != http.StatusOK {
+			t.Fatalf("unexpected top list: %d %s", rec.Code, rec.Body.String())
+		}
+		return top
+	}
+	if top := topList(1); len(top.Posts) != 1 || top.Posts[0].Views != 2 || len(top.Authors) != 1 {
+		t.Fatalf("unexpected top 1 list: %+v", top)
+	}
+	// other tests' Posts may be listed too, only views of this run's Post & author are checked
+	top := topList(maxPageSize)
+	posts := Filter(top.Posts, func(i int, p *PostStat) bool { return p.ID == id })
+	authors := Filter(top.Authors, func(i int, a *AuthorStat) bool { return a.Uname == alice })
+	if len(posts) != 1 || posts[0].Views != 2 || len(authors) != 1 || authors[0].Views != 2 {
+		t.Fatalf("unexpected top list: %+v", top)
+	}
+}
+
+func TestViewsFlushFailed(t *testing.T) {
+	var (
+		alice = uniq("an-alice")
+		bob   = uniq("an-bob")
+		huge  = strings.Repeat("x", 70000) // key too large for badger, fails the transaction
+	)
+	id := newTestPost(t, alice)
+
+	recordView(id, alice, bob)
+	recordView(id, alice, huge)
+	if err := flushViews(); err == nil {
+		t.Fatal("flushing with too large key should fail")
+	}
+	if daily, err := dailyViews(id); err != nil || len(daily) > 0 {
+		t.Fatalf("failed flushing should write nothing, got %v %v", daily, err)
+	}
+
+	mtxView.Lock()
+	_, okBob := mPendView[id+SEP+bob]
+	_, okHuge := mPendView[id+SEP+huge]
+	delete(mPendView, id+SEP+huge)
+	mtxView.Unlock()
+	if !okBob || !okHuge {
+		t.Fatal("views of failed flushing should be pending again")
+	}
+
+	if err := flushViews(); err != nil {
+		t.Fatal(err)
+	}
+	if daily, err := dailyViews(id); err != nil || daily[time.Now().Format("20060102")] != 1 {
+		t.Fatalf("pending view should be counted at next flushing, got %v %v", daily, err)
+	}
+}
@@ -0,0 +1,68 @@
+package post
+
+import (
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/dgraph-io/badger/v3"
+	bh "github.com/digisan/db-helper/badger"
+	lk "github.com/digisan/logkit"
+)
+
+// key: time(unix nano) ^ target;
+// value: json of one audit record for performed or refused action on a Post
+type Audit struct {
+	Tm     time.Time `json:"tm"`
+	Actor  string    `json:"actor"`  // who invoked this action
+	Action string    `json:"action"` // e.g. "delete", "erase"
+	Target string    `json:"target"` // Post ID
+	Owner  string    `json:"owner"`  // Post owner, empty if Post is missing
+	Done   bool      `json:"done"`   // true: performed; false: refused
+	Reason string    `json:"reason"`
+}
+
+func (a Audit) String() string {
+	return fmt.Sprintf("%v [%s] %s <%s>(%s) done: %v, reason: %s", a.Tm, a.Actor, a.Action, a.Target, a.Owner, a.Done, a.Reason)
+}
+
+func (a *Audit) BadgerDB() *badger.DB {
+	return DbGrp.Audit
+}
+
+func (a *Audit) Key() []byte {
+	return []byte(fmt.Sprintf("%019d%s%s", a.Tm.UnixNano(), SEP, a.Target))
+}
+
+func (a *Audit) Marshal(at any) (forKey, forValue []byte) {
+	forKey = a.Key()
+	forValue, err := json.Marshal(a)
+	lk.FailOnErr("%v", err)
+	return
+}
+
+func (a *Audit) Unmarshal(dbKey, dbVal []byte) (any, error) {
+	if err := json.Unmarshal(dbVal, a); err != nil {
+		return nil, err
+	}
+	return a, nil
+}
+
+func addAudit(actor, action, target, owner string, done bool, reason string) error {
+	a := &Audit{
+		Tm:     time.Now(),
+		Actor:  actor,
+		Action: action,
+		Target: target,
+		Owner:  owner,
+		Done:   done,
+		Reason: reason,
+	}
+	lk.Log("audit: %v", a)
+	return bh.UpsertOneObject(a)
+}
+
+// audit records ordered by time, [filter] can be nil
+func AuditTrail(filter func(*Audit) bool) ([]*Audit, error) {
+	return bh.GetObjects[Audit](nil, filter)
+}
@@ -0,0 +1,91 @@
+package post
+
+import (
+	"sync"
+
+	em "github.com/digisan/event-mgr"
+	. "github.com/digisan/go-generics/v2"
+)
+
+const (
+	maxBatch     = 100 // max Post ids in one batch
+	batchWorkers = 8   // concurrent workers for presenting Post content, e.g. media size probing
+)
+
+// one item of batch fetching, Status is one of [ok, missing, deleted, hidden, error]
+type BatchItem struct {
+	ID     string    `json:"id"`
+	Status string    `json:"status"`
+	Error  string    `json:"error,omitempty"`
+	Post   *PostView `json:"post,omitempty"`
+}
+
+// fetch & present Posts of [ids] for [uname] by a bounded worker pool, result is in [ids] order without duplicates
+func fetchMany(ids []string, uname, base string) []*BatchItem {
+	ids = Settify(ids...)
+	items := make([]*BatchItem, len(ids))
+
+	chIdx := make(chan int)
+	wg := sync.WaitGroup{}
+	for w := 0; w < batchWorkers; w++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := range chIdx {
+				items[i] = fetchOne(ids[i], uname, base)
+			}
+		}()
+	}
+	for i := range ids {
+		chIdx <- i
+	}
+	close(chIdx)
+	wg.Wait()
+
+	return items
+}
+
+func fetchOne(id, uname, base string) *BatchItem {
+	item := &BatchItem{ID: id}
+	event, err := em.FetchEvent(false, id)
+	switch {
+	case err != nil:
+		item.Status, item.Error = "error", err.Error()
+		return item
+	case event == nil || NotIn(event.EvtType, "Post", shareType): // other events (e.g. Vote) are not Post
+		item.Status = "missing"
+		return item
+	case event.Deleted:
+		item.Status = "deleted"
+		return item
+	}
+
+	// hidden by moderation, only owner & admin can see it, whatever its type or content is
+	hidden, err := hiddenFor(event.ID, event.Owner, uname)
+	switch {
+	case err != nil:
+		item.Status, item.Error = "error", err.Error()
+		return item
+	case hidden:
+		item.Status = "hidden"
+		return item
+	}
+
+	var view *PostView
+	switch {
+	case event.EvtType == shareType:
+		view, err = viewShare(event, uname, base)
+	case len(event.RawJSON) == 0:
+		view = &PostView{Event: event}
+	default:
+		if view, err = viewPost(event, uname, base); err == nil {
+			recordView(event.ID, event.Owner, uname)
+		}
+	}
+	if err != nil {
+		item.Status, item.Error = "error", err.Error()
+		return item
+	}
+	item.Status, item.Post = "ok", view
+	return item
+}
@@ -0,0 +1,81 @@
+package post
+
+import (
+	"testing"
+	"time"
+
+	em "github.com/digisan/event-mgr"
+	clt "github.com/wismed-web/wisite-api/server/api/client"
+)
+
+func TestFetchMany(t *testing.T) {
+	clt.AddLayout("b-viewer", &clt.Layout{})
+
+	ids := []string{}
+	for i := 0; i < 20; i++ {
+		ids = append(ids, newTestPost(t, "b-alice"))
+	}
+	if _, err := em.DelEvent(ids[3]); err != nil {
+		t.Fatal(err)
+	}
+	ids = append(ids, "missing-id", ids[0])
+
+	items := fetchMany(ids, "b-viewer", "http://127.0.0.1:3323")
+	if len(items) != 21 {
+		t.Fatalf("duplicate id should be fetched once, got %d items", len(items))
+	}
+	for i, item := range items {
+		want := "ok"
+		switch i {
+		case 3:
+			want = "deleted"
+		case 20:
+			want = "missing"
+		}
+		if item.ID != ids[i] || item.Status != want || (want == "ok") != (item.Post != nil) {
+			t.Fatalf("item %d: want %s @%s, got %+v", i, want, ids[i], item)
+		}
+	}
+
+	// fetched Posts are viewed, duplicate id is viewed once
+	if err := flushViews(); err != nil {
+		t.Fatal(err)
+	}
+	if daily, err := dailyViews(ids[0]); err != nil || daily[time.Now().Format("20060102")] != 1 {
+		t.Fatalf("fetched Post should be viewed once, got %v %v", daily, err)
+	}
+}
+
+func TestFetchManyHidden(t *testing.T) {
+	var (
+		alice  = uniq("b-alice")
+		bob    = uniq("b-bob")
+		viewer = uniq("b-viewer")
+	)
+	clt.AddLayout(viewer, &clt.Layout{})
+	clt.AddLayout(bob, &clt.Layout{})
+
+	share, err := sharePost(bob, newTestPost(t, alice), "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	empty := em.NewEvent("", alice, "Post", "", "")
+	if err := em.AddEvent(empty); err != nil {
+		t.Fatal(err)
+	}
+	ids := []string{share.ID, empty.ID}
+	for _, id := range ids {
+		setHidden(id, true)
+		defer setHidden(id, false)
+	}
+
+	// hidden share & hidden Post without content are hidden as GetOne does, except for owner
+	for i, item := range fetchMany(ids, viewer, "http://127.0.0.1:3323") {
+		if item.Status != "hidden" || item.Post != nil {
+			t.Fatalf("item %d should be hidden, got %+v", i, item)
+		}
+	}
+	if item := fetchOne(share.ID, bob, "http://127.0.0.1:3323"); item.Status != "ok" {
+		t.Fatalf("owner should see own hidden share, got %+v", item)
+	}
+}
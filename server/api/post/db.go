@@ -0,0 +1,107 @@
+package post
+
+import (
+	"path/filepath"
+	"sync"
+
+	"github.com/dgraph-io/badger/v3"
+	. "github.com/digisan/go-generics/v2"
+	lk "github.com/digisan/logkit"
+)
+
+type DBGrp struct {
+	sync.Mutex
+	Revision   *badger.DB // post id + seq : earlier post body
+	Audit      *badger.DB // time + post id : audit record
+	Draft      *badger.DB // owner + draft id : unpublished Post
+	Category   *badger.DB // category id : admin-defined category
+	Report     *badger.DB // post id + reporter : user report
+	Moderation *badger.DB // post id : admin moderation result
+	Warning    *badger.DB // uname + time : admin warning to author
+	Mention    *badger.DB // mentioned uname + post id : mention
+	View       *badger.DB // post id + viewer : first view; post id + day : unique view count
+	Share      *badger.DB // original post id + share id : share record
+}
+
+var (
+	onceDB sync.Once // do once
+	DbGrp  *DBGrp    // global, for keeping single instance
+)
+
+func open(dir string) *badger.DB {
+	opt := badger.DefaultOptions("").WithInMemory(true)
+	if dir != "" {
+		opt = badger.DefaultOptions(dir)
+		opt.Logger = nil
+	}
+	db, err := badger.Open(opt)
+	lk.FailOnErr("%v", err)
+	return db
+}
+
+// init global 'DbGrp'. if [dir] is empty, use in-memory db
+func InitDB(dir string) *DBGrp {
+	if DbGrp == nil {
+		onceDB.Do(func() {
+			DbGrp = &DBGrp{
+				Revision:   open(IF(dir == "", "", filepath.Join(dir, "post-revision"))),
+				Audit:      open(IF(dir == "", "", filepath.Join(dir, "post-audit"))),
+				Draft:      open(IF(dir == "", "", filepath.Join(dir, "post-draft"))),
+				Category:   open(IF(dir == "", "", filepath.Join(dir, "post-category"))),
+				Report:     open(IF(dir == "", "", filepath.Join(dir, "post-report"))),
+				Moderation: open(IF(dir == "", "", filepath.Join(dir, "post-moderation"))),
+				Warning:    open(IF(dir == "", "", filepath.Join(dir, "post-warning"))),
+				Mention:    open(IF(dir == "", "", filepath.Join(dir, "post-mention"))),
+				View:       open(IF(dir == "", "", filepath.Join(dir, "post-view"))),
+				Share:      open(IF(dir == "", "", filepath.Join(dir, "post-share"))),
+			}
+		})
+	}
+	return DbGrp
+}
+
+func CloseDB() {
+	DbGrp.Lock()
+	defer DbGrp.Unlock()
+
+	if DbGrp.Revision != nil {
+		lk.FailOnErr("%v", DbGrp.Revision.Close())
+		DbGrp.Revision = nil
+	}
+	if DbGrp.Audit != nil {
+		lk.FailOnErr("%v", DbGrp.Audit.Close())
+		DbGrp.Audit = nil
+	}
+	if DbGrp.Draft != nil {
+		lk.FailOnErr("%v", DbGrp.Draft.Close())
+		DbGrp.Draft = nil
+	}
+	if DbGrp.Category != nil {
+		lk.FailOnErr("%v", DbGrp.Category.Close())
+		DbGrp.Category = nil
+	}
+	if DbGrp.Report != nil {
+		lk.FailOnErr("%v", DbGrp.Report.Close())
+		DbGrp.Report = nil
+	}
+	if DbGrp.Moderation != nil {
+		lk.FailOnErr("%v", DbGrp.Moderation.Close())
+		DbGrp.Moderation = nil
+	}
+	if DbGrp.Warning != nil {
+		lk.FailOnErr("%v", DbGrp.Warning.Close())
+		DbGrp.Warning = nil
+	}
+	if DbGrp.Mention != nil {
+		lk.FailOnErr("%v", DbGrp.Mention.Close())
+		DbGrp.Mention = nil
+	}
+	if DbGrp.View != nil {
+		lk.FailOnErr("%v", DbGrp.View.Close())
+		DbGrp.View = nil
+	}
+	if DbGrp.Share != nil {
+		lk.FailOnErr("%v", DbGrp.Share.Close())
+		DbGrp.Share = nil
+	}
+}
@@ -0,0 +1,203 @@
+package post
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/dgraph-io/badger/v3"
+	bh "github.com/digisan/db-helper/badger"
+	em "github.com/digisan/event-mgr"
+	lk "github.com/digisan/logkit"
+	"github.com/google/uuid"
+)
+
+// scan interval for due scheduled drafts
+const scheduleInterval = 15 * time.Second
+
+var (
+	mtxDraft = &sync.Mutex{} // draft updating & publishing are exclusive, a draft is never published twice
+
+	fnPublish = publish // variable for replacing in test
+
+	errDraftMissing  = errors.New("draft is not existing")
+	errPublishAtPast = errors.New("'publish_at' must be a future time")
+)
+
+// key: owner ^ draft id;
+// value: json of one unpublished Post. if [PublishAt] is set, it is published as a normal Post event at that time
+type Draft struct {
+	ID        string    `json:"id"`
+	Owner     string    `json:"owner"`
+	Followee  string    `json:"followee"`   // followee Post ID, empty for a new post
+	PublishAt time.Time `json:"publish_at"` // zero time means not scheduled
+	Created   time.Time `json:"created"`
+	Updated   time.Time `json:"updated"`
+	Failure   string    `json:"failure,omitempty"` // why last scheduled publishing failed, then it falls back to unscheduled
+	Post      *Post     `json:"post,omitempty"`
+}
+
+func (d Draft) String() string {
+	return fmt.Sprintf("%s by %s, followee: %s, publish at: %v, updated: %v, failure: %s", d.ID, d.Owner, d.Followee, d.PublishAt, d.Updated, d.Failure)
+}
+
+func (d *Draft) BadgerDB() *badger.DB {
+	return DbGrp.Draft
+}
+
+func (d *Draft) Key() []byte {
+	return []byte(d.Owner + SEP + d.ID)
+}
+
+func (d *Draft) Marshal(at any) (forKey, forValue []byte) {
+	forKey = d.Key()
+	forValue, err := json.Marshal(d)
+	lk.FailOnErr("%v", err)
+	return
+}
+
+func (d *Draft) Unmarshal(dbKey, dbVal []byte) (any, error) {
+	if err := json.Unmarshal(dbVal, d); err != nil {
+		return nil, err
+	}
+	return d, nil
+}
+
+func (d *Draft) scheduled() bool {
+	return !d.PublishAt.IsZero()
+}
+
+func checkPublishAt(publishAt time.Time) error {
+	if !publishAt.IsZero() && !publishAt.After(time.Now()) {
+		return errPublishAtPast
+	}
+	return nil
+}
+
+// nil if not found
+func FetchDraft(owner, id string) (*Draft, error) {
+	return bh.GetOneObject[Draft]((&Draft{Owner: owner, ID: id}).Key())
+}
+
+// all drafts of [owner], most recently updated first
+func Drafts(owner string) ([]*Draft, error) {
+	drafts, err := bh.GetObjects[Draft]([]byte(owner+SEP), nil)
+	if err != nil {
+		return nil, err
+	}
+	sort.SliceStable(drafts, func(i, j int) bool { return drafts[i].Updated.After(drafts[j].Updated) })
+	return drafts, nil
+}
+
+// save cleaned [P] as a new draft of [owner], scheduled if [publishAt] is not zero
+func newDraft(owner, flwee string, publishAt time.Time, P *Post) (*Draft, error) {
+	if err := checkPublishAt(publishAt); err != nil {
+		return nil, err
+	}
+	now := time.Now()
+	d := &Draft{
+		ID:        uuid.NewString(),
+		Owner:     owner,
+		Followee:  flwee,
+		PublishAt: publishAt,
+		Created:   now,
+		Updated:   now,
+		Post:      P,
+	}
+	mtxDraft.Lock()
+	defer mtxDraft.Unlock()
+	return d, bh.UpsertOneObject(d)
+}
+
+// replace draft [id] content with cleaned [P], scheduled if [publishAt] is not zero
+func updateDraft(owner, id, flwee string, publishAt time.Time, P *Post) (*Draft, error) {
+	if err := checkPublishAt(publishAt); err != nil {
+		return nil, err
+	}
+	mtxDraft.Lock()
+	defer mtxDraft.Unlock()
+
+	d, err := FetchDraft(owner, id)
+	if err != nil {
+		return nil, err
+	}
+	if d == nil {
+		return nil, errDraftMissing
+	}
+	d.Followee, d.PublishAt, d.Updated, d.Failure, d.Post = flwee, publishAt, time.Now(), "", P
+	return d, bh.UpsertOneObject(d)
+}
+
+func deleteDraft(owner, id string) error {
+	mtxDraft.Lock()
+	defer mtxDraft.Unlock()
+
+	d, err := FetchDraft(owner, id)
+	if err != nil {
+		return err
+	}
+	if d == nil {
+		return errDraftMissing
+	}
+	_, err = bh.DeleteOneObject[Draft](d.Key())
+	return err
+}
+
+// publish all scheduled drafts due at [now] as normal Post events, return published event IDs.
+// due draft is removed before publishing, so it cannot be published again even if later steps fail.
+// failed one is restored as unscheduled draft with its failure reason
+func publishDue(now time.Time) ([]string, error) {
+	mtxDraft.Lock()
+	defer mtxDraft.Unlock()
+
+	due, err := bh.GetObjects[Draft](nil, func(d *Draft) bool { return d.scheduled() && !d.PublishAt.After(now) })
+	if err != nil {
+		return nil, err
+	}
+
+	ids := []string{}
+	for _, d := range due {
+		if d.Post == nil {
+			d.Post = &Post{}
+		}
+		err := d.Post.clean(d.Owner) // attachment may be removed after scheduling
+		if err == nil {
+			if _, err := bh.DeleteOneObject[Draft](d.Key()); err != nil {
+				return ids, err
+			}
+			var evt *em.Event
+			if evt, err = fnPublish(d.Owner, d.Followee, d.Post); err == nil {
+				lk.Log("scheduled draft %v is published as %s", d, evt.ID)
+				ids = append(ids, evt.ID)
+				continue
+			}
+		}
+		lk.Warn("publish scheduled draft %v error: %v", d, err)
+		d.PublishAt, d.Failure = time.Time{}, err.Error()
+		if err := bh.UpsertOneObject(d); err != nil {
+			return ids, err
+		}
+	}
+	return ids, nil
+}
+
+// publish due drafts every [scheduleInterval] until [ctx] is done. drafts due while server was down are published at start
+func runScheduler(ctx context.Context) {
+	ticker := time.NewTicker(scheduleInterval)
+	defer ticker.Stop()
+	for {
+		_, err := publishDue(time.Now())
+		lk.WarnOnErr("%v", err)
+
+		select {
+		case <-ctx.Done():
+			lk.Log("post draft scheduler stopped")
+			return
+		case <-ticker.C:
+		}
+	}
+}
@@ -0,0 +1,122 @@
+package post
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"testing"
+	"time"
+
+	em "github.com/digisan/event-mgr"
+)
+
+func TestDraftSchedule(t *testing.T) {
+	var (
+		owner = uniq("d-alice")
+		bob   = uniq("d-bob")
+	)
+	at := time.Now().Add(time.Hour)
+
+	if _, err := newDraft(owner, "", time.Now().Add(-time.Minute), &Post{}); err != errPublishAtPast {
+		t.Fatalf("past 'publish_at' should be refused, got %v", err)
+	}
+
+	plain, err := newDraft(owner, "", time.Time{}, &Post{Topic: "plain draft"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	due, err := newDraft(owner, "", at, &Post{Topic: "scheduled draft", Content: []Paragraph{{Text: "announcement"}}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	broken, err := newDraft(owner, "", at, &Post{Topic: "broken draft", Content: []Paragraph{{Atch: Attachment{Path: "2023-05/missing.png"}}}})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if drafts, err := Drafts(owner); err != nil || len(drafts) != 3 || drafts[0].ID != broken.ID {
+		t.Fatalf("unexpected drafts: %v, %v", drafts, err)
+	}
+
+	// nothing is due yet
+	if ids, err := publishDue(time.Now()); err != nil || len(ids) != 0 {
+		t.Fatalf("unexpected published: %v, %v", ids, err)
+	}
+
+	ids, err := publishDue(at.Add(time.Second))
+	if err != nil || len(ids) != 1 {
+		t.Fatalf("want 1 published, got %v, %v", ids, err)
+	}
+	evt, err := em.FetchEvent(true, ids[0])
+	if err != nil || evt == nil || evt.Owner != owner {
+		t.Fatalf("unexpected published event: %v, %v", evt, err)
+	}
+	P := &Post{}
+	if err := json.Unmarshal([]byte(evt.RawJSON), P); err != nil || P.Topic != "scheduled draft" || P.Category != "post" {
+		t.Fatalf("unexpected published Post: %v, %v", P, err)
+	}
+
+	// published draft is removed, failed one falls back to unscheduled draft
+	if d, err := FetchDraft(owner, due.ID); err != nil || d != nil {
+		t.Fatalf("published draft should be removed, got %v, %v", d, err)
+	}
+	if d, err := FetchDraft(owner, broken.ID); err != nil || d == nil || d.scheduled() || len(d.Failure) == 0 {
+		t.Fatalf("failed draft should be unscheduled with failure, got %v, %v", d, err)
+	}
+	if ids, err := publishDue(at.Add(time.Hour)); err != nil || len(ids) != 0 {
+		t.Fatalf("failed draft should not be retried, got %v, %v", ids, err)
+	}
+
+	if _, err := updateDraft(bob, plain.ID, "", time.Time{}, &Post{}); err != errDraftMissing {
+		t.Fatalf("other's draft should be missing, got %v", err)
+	}
+	if d, err := updateDraft(owner, plain.ID, "", at, &Post{Topic: "updated"}); err != nil || !d.scheduled() || d.Post.Topic != "updated" {
+		t.Fatalf("unexpected updated draft: %v, %v", d, err)
+	}
+	if err := deleteDraft(owner, plain.ID); err != nil {
+		t.Fatal(err)
+	}
+	if err := deleteDraft(owner, plain.ID); err != errDraftMissing {
+		t.Fatalf("deleted draft should be missing, got %v", err)
+	}
+}
+
+func TestDraftPublishFailure(t *testing.T) {
+	owner := uniq("d-alice")
+	at := time.Now().Add(time.Hour)
+	d, err := newDraft(owner, "", at, &Post{Topic: "failing draft"})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// draft is taken out before publishing, then restored if publishing fails
+	defer func() { fnPublish = publish }()
+	fnPublish = func(uname, flwee string, P *Post) (*em.Event, error) {
+		if d, err := FetchDraft(owner, d.ID); err != nil || d != nil {
+			t.Errorf("draft should be removed before publishing, got %v, %v", d, err)
+		}
+		return nil, errors.New("event store is down")
+	}
+	if ids, err := publishDue(at.Add(time.Second)); err != nil || len(ids) != 0 {
+		t.Fatalf("unexpected published: %v, %v", ids, err)
+	}
+	restored, err := FetchDraft(owner, d.ID)
+	if err != nil || restored == nil || restored.scheduled() || restored.Failure != "event store is down" || restored.Post.Topic != "failing draft" {
+		t.Fatalf("failed draft should be restored unscheduled with failure, got %v, %v", restored, err)
+	}
+}
+
+func TestSchedulerStop(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan struct{})
+	go func() {
+		runScheduler(ctx)
+		close(done)
+	}()
+	cancel()
+	select {
+	case <-done:
+	case <-time.After(5 * time.Second):
+		t.Fatal("scheduler did not stop after Cancel")
+	}
+}
@@ -0,0 +1,142 @@
+package post
+
+import (
+	"strconv"
+	"time"
+
+	em "github.com/digisan/event-mgr"
+	. "github.com/digisan/go-generics/v2"
+	r "github.com/digisan/user-mgr/relation"
+)
+
+// relation list, variable for replacing in test
+var fnListRel = func(uname string, flag int) ([]string, error) {
+	return r.ListRel(uname, flag, true)
+}
+
+// popular score weights
+const (
+	wReaction = 1.0 // each reaction of any configured type
+	wComment  = 2.0
+)
+
+// followed users and [uname] self, without blocked & muted users
+func feedAuthors(uname string) (map[string]struct{}, error) {
+	following, err := fnListRel(uname, r.FOLLOWING)
+	if err != nil {
+		return nil, err
+	}
+	blocked, err := fnListRel(uname, r.BLOCKED)
+	if err != nil {
+		return nil, err
+	}
+	muted, err := fnListRel(uname, r.MUTED)
+	if err != nil {
+		return nil, err
+	}
+	authors := map[string]struct{}{}
+	for _, author := range append(following, uname) {
+		if len(author) > 0 && NotIn(author, blocked...) && NotIn(author, muted...) {
+			authors[author] = struct{}{}
+		}
+	}
+	return authors, nil
+}
+
+// participants count of an event category, emptied category may load as [""]
+func countPtps(id, category string) (int, error) {
+	ep, err := em.NewEventParticipate(id, true)
+	if err != nil {
+		return 0, err
+	}
+	ptps, err := ep.Ptps(category)
+	if err != nil {
+		return 0, err
+	}
+	return len(Filter(ptps, func(i int, e string) bool { return len(e) > 0 })), nil
+}
+
+// weighted reaction & comment count
+func popularScore(id string) (float64, error) {
+	nReaction, err := reactionCount(id)
+	if err != nil {
+		return 0, err
+	}
+	comments, err := em.Followers(id)
+	if err != nil {
+		return 0, err
+	}
+	nComment := len(Filter(comments, func(i int, e string) bool { return len(e) > 0 }))
+	return wReaction*float64(nReaction) + wComment*float64(nComment), nil
+}
+
+// decimal prefixes exactly covering integers in [lo, hi], e.g. [1995, 2012] => 1995..1999, 200, 2010, 2011, 2012
+func decPrefixes(lo, hi int64) []string {
+	prefixes := []string{}
+	for lo <= hi {
+		step := int64(1)
+		for lo%(step*10) == 0 && lo+step*10-1 <= hi {
+			step *= 10
+		}
+		prefixes = append(prefixes, strconv.FormatInt(lo/step, 10))
+		lo += step
+	}
+	return prefixes
+}
+
+// ids of top-level events in spans since [since], only those spans are scanned from span index.
+// span key is "start unix minute-span minutes" ("MINUTE" span type), unix minutes keep 8 digits till year 2160
+func spanIDsSince(since time.Time) ([]string, error) {
+	ids := []string{}
+	for _, prefix := range decPrefixes(since.Unix()/60, time.Now().Unix()/60) {
+		batch, err := em.FetchEvtIDs([]byte(prefix)) // current span ids are always included
+		if err != nil {
+			return nil, err
+		}
+		ids = append(ids, batch...)
+	}
+	return Settify(ids...), nil
+}
+
+// feed cursors of listed Posts & Shares for [uname], comments excluded. if [popular], only Posts since [since] are
+// taken from span index and ranked by popular score; otherwise all are taken from in-memory listing without fetching events
+func feedCursors(uname string, popular bool, since time.Time) ([]cursor, error) {
+	authors, err := feedAuthors(uname)
+	if err != nil {
+		return nil, err
+	}
+	inFeed := func(id string, l listing) bool {
+		_, ok := authors[l.owner]
+		return ok && !l.reply && !isHidden(id)
+	}
+
+	all := []cursor{}
+	if !popular {
+		idx.RLock()
+		defer idx.RUnlock()
+		for id, l := range idx.mListed {
+			if inFeed(id, l) {
+				all = append(all, cursor{0, l.tm, id})
+			}
+		}
+		return all, nil
+	}
+
+	ids, err := spanIDsSince(since)
+	if err != nil {
+		return nil, err
+	}
+	idx.RLock()
+	for _, id := range ids {
+		if l, ok := idx.mListed[id]; ok && inFeed(id, l) && !l.tm.Before(since) {
+			all = append(all, cursor{0, l.tm, id})
+		}
+	}
+	idx.RUnlock()
+	for i := range all {
+		if all[i].score, err = popularScore(all[i].id); err != nil {
+			return nil, err
+		}
+	}
+	return all, nil
+}
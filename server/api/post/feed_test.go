@@ -0,0 +1,102 @@
+package post
+
+import (
+	"reflect"
+	"testing"
+	"time"
+
+	em "github.com/digisan/event-mgr"
+	. "github.com/digisan/go-generics/v2"
+	r "github.com/digisan/user-mgr/relation"
+)
+
+func TestFeed(t *testing.T) {
+	var (
+		alice = uniq("f-alice")
+		bob   = uniq("f-bob")
+		carol = uniq("f-carol")
+		dave  = uniq("f-dave")
+		eve   = uniq("f-eve")
+	)
+	mRel := map[int][]string{
+		r.FOLLOWING: {bob, carol, dave},
+		r.BLOCKED:   {carol},
+		r.MUTED:     {dave},
+	}
+	fnListRel = func(uname string, flag int) ([]string, error) {
+		if uname != alice {
+			return []string{}, nil
+		}
+		return mRel[flag], nil
+	}
+
+	idOwn := newTestPost(t, alice)
+	idBob := newTestPost(t, bob)
+	newTestPost(t, carol)
+	newTestPost(t, dave)
+	newTestPost(t, eve)
+
+	// comment makes own Post popular, reactions of all types make bob's Post more popular
+	ef, err := em.NewEventFollow(idOwn, true)
+	if err != nil {
+		t.Fatal(err)
+	}
+	cmt := em.NewEvent("", bob, "Post", `{"category":"comment"}`, idOwn)
+	if err := em.AddEvent(cmt); err != nil {
+		t.Fatal(err)
+	}
+	if err := IndexEvent(cmt); err != nil {
+		t.Fatal(err)
+	}
+	if err := ef.AddFollower(cmt.ID); err != nil {
+		t.Fatal(err)
+	}
+	ep, err := em.NewEventParticipate(idBob, true)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, typ := range []string{"ThumbsUp", "Insightful", "Thanks"} {
+		if err := ep.AddPtps(typ, alice); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	ids := func(popular bool) []string {
+		all, err := feedCursors(alice, popular, time.Now().Add(-time.Hour))
+		if err != nil {
+			t.Fatal(err)
+		}
+		page, err := pageCursors(all, 10, "", "")
+		if err != nil {
+			t.Fatal(err)
+		}
+		return page.IDs
+	}
+
+	// comment is not in feed, blocked & muted authors are excluded
+	latest := ids(false)
+	if len(latest) != 2 || !reflect.DeepEqual(Settify(append(latest, idOwn, idBob)...), latest) {
+		t.Fatalf("unexpected latest feed: %v", latest)
+	}
+	if popular := ids(true); len(popular) != 2 || popular[0] != idBob || popular[1] != idOwn {
+		t.Fatalf("unexpected popular feed: %v", popular)
+	}
+}
+
+func TestDecPrefixes(t *testing.T) {
+	tests := []struct {
+		lo, hi int64
+		want   []string
+	}{
+		{1995, 2012, []string{"1995", "1996", "1997", "1998", "1999", "200", "2010", "2011", "2012"}},
+		{2000, 2999, []string{"2"}},
+		{29630000, 29630000, []string{"29630000"}},
+		{29630010, 29630119, []string{"2963001", "2963002", "2963003", "2963004", "2963005", "2963006", "2963007", "2963008", "2963009", "2963010", "2963011"}},
+		{5, 4, []string{}},
+	}
+	for _, tt := range tests {
+		if got := decPrefixes(tt.lo, tt.hi); !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("decPrefixes(%d, %d) = %v, want %v", tt.lo, tt.hi, got, tt.want)
+		}
+	}
+}
@@ -1,23 +1,19 @@
 package post
 
 import (
-	"encoding/json"
 	"fmt"
 	"net/http"
-	"path/filepath"
 	"strconv"
 	"strings"
 	"time"
 
 	em "github.com/digisan/event-mgr"
-	fm "github.com/digisan/file-mgr"
-	"github.com/digisan/file-mgr/fdb"
 	. "github.com/digisan/go-generics/v2"
-	fd "github.com/digisan/gotk/filedir"
 	lk "github.com/digisan/logkit"
 	u "github.com/digisan/user-mgr/user"
 	"github.com/golang-jwt/jwt/v4"
 	"github.com/labstack/echo/v4"
+	"github.com/wismed-web/wisite-api/server/api/admin"
 )
 
 // https://github.com/swaggo/swag
@@ -38,10 +34,11 @@ func Template(c echo.Context) error {
 	return c.JSON(http.StatusOK, Post{
 		Category: "Post category",
 		Topic:    "Post topic",
-		Keywords: "keywords for this Post",
+		Keywords: "keywords for this Post, separated by comma, normalized as tags",
+		CatID:    "optional admin-defined category id",
 		Content: []Paragraph{
 			{
-				Text:     "some words for this paragraph",
+				Text:     "some words for this paragraph, @uname mentions a member",
 				RichText: "html format for text",
 				Atch: Attachment{
 					Path: "attachment path, which should have been given from 'file upload'",
@@ -62,6 +59,7 @@ func Template(c echo.Context) error {
 // @Param   data body string true "filled Post template json file"
 // @Param   followee  query string false "followee Post ID (empty when doing a new post)"
 // @Success 200 "OK - upload successfully"
+// @Header  200 {string} X-Sanitize-Report "json {count, removed} of removed unsafe html elements & attributes, only first few listed, only if any"
 // @Failure 400 "Fail - incorrect Post format"
 // @Failure 500 "Fail - internal error"
 // @Router /api/post/upload [post]
@@ -81,67 +79,21 @@ func Upload(c echo.Context) error {
 	}
 	lk.Log("Uploading ---> [%s] --- %v", uname, P)
 
-	// get rid of empty paragraph
+	// get rid of empty paragraph & validate each path from P
 	//
-	FilterFast(&P.Content, func(i int, e Paragraph) bool {
-		return len(e.Text) > 0 || len(e.Atch.Path) > 0
-	})
-
-	// validate each path from P
-	//
-	paths := []string{}
-	for _, item := range P.Content {
-		if len(item.Atch.Path) > 0 {
-			path := filepath.Join("data/user-space", uname, item.Atch.Path)
-			paths = append(paths, path)
-		}
-	}
-	if ok, epath := fd.AllExistAsWhole(paths...); !ok {
-		return c.String(http.StatusBadRequest, fmt.Sprintf("'%s' is invalid storage at server", filepath.Base(epath)))
+	if err := P.clean(uname); err != nil {
+		return c.String(http.StatusBadRequest, err.Error())
 	}
 
-	// set P Category
+	// remove unsafe html from RichText
 	//
-	switch {
-	case len(flwee) > 0:
-		P.Category = "comment"
-	default:
-		P.Category = "post"
-	}
+	sanitizeReport(c, uname, P)
 
-	// save P as JSON for event
-	//
-	data, err := json.Marshal(P)
+	evt, err := publish(uname, flwee, P)
 	if err != nil {
 		return c.String(http.StatusInternalServerError, err.Error())
 	}
 
-	evt := em.NewEvent("", uname, "Post", string(data), flwee)
-	if len(evt.ID) > 0 {
-		if err = em.AddEvent(evt); err != nil {
-			return c.String(http.StatusInternalServerError, err.Error())
-		}
-
-		// DEBUG
-		// gio.MustAppendFile("./debug.txt", []byte(evt.ID), true)
-
-		// FOLLOWING...
-		if len(flwee) > 0 {
-			ef, err := em.FetchFollow(flwee)
-			if err != nil {
-				return c.String(http.StatusInternalServerError, err.Error())
-			}
-			if ef == nil {
-				if ef, err = em.NewEventFollow(flwee, true); err != nil {
-					return c.String(http.StatusInternalServerError, err.Error())
-				}
-			}
-			if err := ef.AddFollower(evt.ID); err != nil {
-				return c.String(http.StatusInternalServerError, err.Error())
-			}
-		}
-	}
-
 	// lk.Log("---> %s", em.CurrIDs())
 
 	return c.JSON(http.StatusOK, evt.ID)
@@ -153,8 +105,12 @@ func Upload(c echo.Context) error {
 // @Tags    Post
 // @Accept  json
 // @Produce json
-// @Param   fetchby query string true "time or count"
-// @Param   value   query string true "recent [value] minutes for time OR most recent [value] count"
+// @Param   fetchby query string  false "time or count. if missing, all Post ids are paged (unavailable when 'compat')"
+// @Param   value   query string  false "recent [value] minutes for time OR most recent [value] count"
+// @Param   limit   query int     false "page size, default is 20, max is 100"
+// @Param   before  query string  false "cursor ('next_cursor' of last page) for older Post ids"
+// @Param   after   query string  false "cursor ('prev_cursor' of last page) for newer Post ids"
+// @Param   compat  query boolean false "true: return plain id array as before, no paging"
 // @Success 200 "OK - get successfully"
 // @Failure 400 "Fail - incorrect query param type"
 // @Failure 404 "Fail - not found"
@@ -168,6 +124,14 @@ func IdBatch(c echo.Context) error {
 		ids     = []string{}
 	)
 
+	if compat, _ := strconv.ParseBool(c.QueryParam("compat")); !compat && len(fetchby) == 0 {
+		ids, err := em.FetchEvtIDs(nil)
+		if err != nil {
+			return c.String(http.StatusInternalServerError, err.Error())
+		}
+		return replyIDs(c, ids)
+	}
+
 	if fetchby = strings.ToLower(fetchby); NotIn(fetchby, "time", "count") {
 		return c.String(http.StatusBadRequest, "'fetchby' must be one of [time, count]")
 	}
@@ -195,7 +159,7 @@ func IdBatch(c echo.Context) error {
 	// if len(ids) == 0 {
 	// 	return c.JSON(http.StatusNotFound, ids)
 	// }
-	return c.JSON(http.StatusOK, ids)
+	return replyIDs(c, ids)
 }
 
 // @Title get all Post id group
@@ -204,7 +168,12 @@ func IdBatch(c echo.Context) error {
 // @Tags    Post
 // @Accept  json
 // @Produce json
+// @Param   limit   query int     false "page size, default is 20, max is 100"
+// @Param   before  query string  false "cursor ('next_cursor' of last page) for older Post ids"
+// @Param   after   query string  false "cursor ('prev_cursor' of last page) for newer Post ids"
+// @Param   compat  query boolean false "true: return plain id array as before, no paging"
 // @Success 200 "OK - get successfully"
+// @Failure 400 "Fail - incorrect query param"
 // @Failure 404 "Fail - empty event ids"
 // @Failure 500 "Fail - internal error"
 // @Router /api/post/ids-all [get]
@@ -221,7 +190,7 @@ func IdAll(c echo.Context) error {
 	// if len(ids) == 0 {
 	// 	return c.JSON(http.StatusNotFound, ids)
 	// }
-	return c.JSON(http.StatusOK, ids)
+	return replyIDs(c, ids)
 }
 
 // @Title get one Post content
@@ -231,7 +200,6 @@ func IdAll(c echo.Context) error {
 // @Accept  json
 // @Produce json
 // @Param   id     query string  true "Post ID for its content"
-// @Param   remote query boolean true "remote ip for media src?"
 // @Success 200 "OK - get Post event successfully"
 // @Failure 400 "Fail - incorrect query param id"
 // @Failure 404 "Fail - not found"
@@ -244,7 +212,6 @@ func GetOne(c echo.Context) error {
 		claims  = userTkn.Claims.(*u.UserClaims)
 		uname   = claims.UName
 		id      = c.QueryParam("id")
-		remote  = c.QueryParam("remote")
 	)
 
 	lk.Log("Into GetOne, event id is %v", id)
@@ -257,72 +224,38 @@ func GetOne(c echo.Context) error {
 	if err != nil {
 		return c.String(http.StatusInternalServerError, err.Error())
 	}
-	if event == nil {
+	if event == nil || NotIn(event.EvtType, "Post", shareType) { // other events (e.g. Vote) are served by their own api
+		return c.String(http.StatusNotFound, fmt.Sprintf("Post not found @%s", id))
+	}
+	// hidden by moderation, only owner & admin can see it
+	hidden, err := hiddenFor(event.ID, event.Owner, uname)
+	if err != nil {
+		return c.String(http.StatusInternalServerError, err.Error())
+	}
+	if hidden {
 		return c.String(http.StatusNotFound, fmt.Sprintf("Post not found @%s", id))
 	}
 	if len(event.RawJSON) == 0 {
 		return c.JSON(http.StatusOK, fmt.Sprintf("Post has no content @%s", id))
 	}
 
-	////////////////////////////////////
-
-	// set up event content, i.e. Post
-	P := &Post{}
-	if err := json.Unmarshal([]byte(event.RawJSON), P); err != nil {
-		lk.Warn("Unmarshal Post Error, event is %v", event)
-		return c.String(http.StatusInternalServerError, "convert RawJSON to [Post] Unmarshal error")
-	}
-
-	for i, p := range P.Content {
-
-		// originally, path start with yyyy-mm
-		path := p.Atch.Path
-
-		// 1) update path for remote access
-		P.Content[i].Atch.Path = filepath.Join(event.Owner, path)
-
-		// 2) update type
-		fpath := filepath.Join("data", "user-space", event.Owner, path)
-		ftype := fdb.GetFileType(fpath)
-		P.Content[i].Atch.Type = ftype
-
-		sz := ""
-		switch ftype {
-		case "image":
-			sz, err = fm.GetImageSize(fpath)
-		case "video":
-			sz, err = fm.GetVideoSize(fpath)
-		default:
-			// no need to get area size
-		}
-		if In(ftype, "image", "video") && err != nil {
-			lk.Warn("get media area size error %v @ %s @ %s", err, ftype, fpath)
+	if event.EvtType == shareType {
+		view, err := viewShare(event, uname, mediaBase(c))
+		if err != nil {
 			return c.String(http.StatusInternalServerError, err.Error())
 		}
-
-		// 3) update area size
-		P.Content[i].Atch.Size = sz
+		return c.JSON(http.StatusOK, view)
 	}
 
-	rmt, err := strconv.ParseBool(remote)
+	view, err := viewPost(event, uname, mediaBase(c))
 	if err != nil {
-		c.String(http.StatusBadRequest, err.Error())
-	}
-
-	P.GenVFX(uname, rmt)
-
-	////////////////////////////////////
-
-	PData, err := json.Marshal(P)
-	if err != nil {
-		c.String(http.StatusInternalServerError, err.Error())
+		return c.String(http.StatusInternalServerError, err.Error())
 	}
-
-	event.RawJSON = string(PData)
+	recordView(event.ID, event.Owner, uname)
 
 	lk.Log("-->\n %v", event)
 
-	return c.JSON(http.StatusOK, event)
+	return c.JSON(http.StatusOK, view)
 }
 
 // @Title delete one Post content
@@ -331,25 +264,53 @@ func GetOne(c echo.Context) error {
 // @Tags    Post
 // @Accept  json
 // @Produce json
-// @Param   id   query string true "Post ID for deleting"
+// @Param   id     query string true  "Post ID for deleting"
+// @Param   reason query string false "reason for deleting, recorded in audit trail"
 // @Success 200 "OK - delete successfully"
 // @Failure 400 "Fail - incorrect query param id"
+// @Failure 403 "Fail - neither Post owner nor admin"
 // @Failure 404 "Fail - not found"
 // @Failure 500 "Fail - internal error"
 // @Router /api/post/del/one [delete]
 // @Security ApiKeyAuth
 func DelOne(c echo.Context) error {
 	var (
-		id = c.QueryParam("id")
+		userTkn = c.Get("user").(*jwt.Token)
+		claims  = userTkn.Claims.(*u.UserClaims)
+		uname   = claims.UName
+		id      = c.QueryParam("id")
+		reason  = c.QueryParam("reason")
 	)
 	if len(id) == 0 {
 		return c.String(http.StatusBadRequest, "'id' is invalid (cannot be empty)")
 	}
 
+	event, err := em.FetchEvent(true, id)
+	if err != nil {
+		return c.String(http.StatusInternalServerError, err.Error())
+	}
+	if event == nil {
+		lk.WarnOnErr("%v", addAudit(uname, "delete", id, "", false, "not existing"))
+		return c.String(http.StatusNotFound, fmt.Sprintf("<%s> is not existing, nothing to delete", id))
+	}
+
+	isAdmin, err := admin.IsAdmin(uname)
+	if err != nil {
+		return c.String(http.StatusInternalServerError, err.Error())
+	}
+	if event.Owner != uname && !isAdmin {
+		lk.WarnOnErr("%v", addAudit(uname, "delete", id, event.Owner, false, "neither owner nor admin"))
+		return c.String(http.StatusForbidden, "only Post owner or admin can delete this Post")
+	}
+
 	n, err := em.DelEvent(id)
 	if err != nil {
 		return c.String(http.StatusInternalServerError, err.Error())
 	}
+	UnindexEvent(id)
+	if err := addAudit(uname, "delete", id, event.Owner, n == 1, IF(len(reason) > 0, reason, IF(isAdmin && event.Owner != uname, "by admin", "by owner"))); err != nil {
+		return c.String(http.StatusInternalServerError, err.Error())
+	}
 	return c.JSON(http.StatusOK, IF(n == 1, fmt.Sprintf("<%s> is deleted", id), fmt.Sprintf("<%s> is not existing, nothing to delete", id)))
 }
 
@@ -359,25 +320,56 @@ func DelOne(c echo.Context) error {
 // @Tags    Post
 // @Accept  json
 // @Produce json
-// @Param   id   query string true "Post ID for erasing"
+// @Param   id     query string true  "Post ID for erasing"
+// @Param   reason query string false "reason for erasing, recorded in audit trail"
 // @Success 200 "OK - erase successfully"
 // @Failure 400 "Fail - incorrect query param id"
+// @Failure 403 "Fail - not admin"
 // @Failure 404 "Fail - not found"
 // @Failure 500 "Fail - internal error"
 // @Router /api/post/erase/one [delete]
 // @Security ApiKeyAuth
 func EraseOne(c echo.Context) error {
 	var (
-		id = c.QueryParam("id")
+		userTkn = c.Get("user").(*jwt.Token)
+		claims  = userTkn.Claims.(*u.UserClaims)
+		uname   = claims.UName
+		id      = c.QueryParam("id")
+		reason  = c.QueryParam("reason")
 	)
 	if len(id) == 0 {
 		return c.String(http.StatusBadRequest, "'id' is invalid (cannot be empty)")
 	}
 
+	event, err := em.FetchEvent(false, id)
+	if err != nil {
+		return c.String(http.StatusInternalServerError, err.Error())
+	}
+	if event == nil {
+		lk.WarnOnErr("%v", addAudit(uname, "erase", id, "", false, "not existing"))
+		return c.String(http.StatusNotFound, fmt.Sprintf("<%s> is not existing, nothing to erase", id))
+	}
+
+	isAdmin, err := admin.IsAdmin(uname)
+	if err != nil {
+		return c.String(http.StatusInternalServerError, err.Error())
+	}
+	if !isAdmin {
+		lk.WarnOnErr("%v", addAudit(uname, "erase", id, event.Owner, false, "not admin"))
+		return c.String(http.StatusForbidden, "only admin can erase Post permanently")
+	}
+
 	n, err := em.EraseEvents(id)
 	if err != nil {
 		return c.String(http.StatusInternalServerError, err.Error())
 	}
+	UnindexEvent(id)
+	if _, err := eraseRevisions(id); err != nil {
+		return c.String(http.StatusInternalServerError, err.Error())
+	}
+	if err := addAudit(uname, "erase", id, event.Owner, n == 1, IF(len(reason) > 0, reason, "by admin")); err != nil {
+		return c.String(http.StatusInternalServerError, err.Error())
+	}
 	return c.JSON(http.StatusOK, IF(n == 1, fmt.Sprintf("<%s> is erased permanently", id), fmt.Sprintf("<%s> is not existing, nothing to erase", id)))
 }
 
@@ -388,6 +380,10 @@ func EraseOne(c echo.Context) error {
 // @Accept  json
 // @Produce json
 // @Param   period query string false "time period for query, format is 'yyyymm', e.g. '202206'. if missing, current yyyymm applies"
+// @Param   limit   query int     false "page size, default is 20, max is 100"
+// @Param   before  query string  false "cursor ('next_cursor' of last page) for older Post ids"
+// @Param   after   query string  false "cursor ('prev_cursor' of last page) for newer Post ids"
+// @Param   compat  query boolean false "true: return plain id array as before, no paging"
 // @Success 200 "OK - get successfully"
 // @Failure 400 "Fail - incorrect query param type"
 // @Failure 404 "Fail - empty event ids"
@@ -418,7 +414,7 @@ func OwnPosts(c echo.Context) error {
 	// if len(ids) == 0 {
 	// 	return c.JSON(http.StatusNotFound, ids)
 	// }
-	return c.JSON(http.StatusOK, ids)
+	return replyIDs(c, ids)
 }
 
 // @Title toggle a bookmark for a post
@@ -481,7 +477,11 @@ func BookmarkStatus(c echo.Context) error {
 // @Tags    Post
 // @Accept  json
 // @Produce json
-// @Param   order query string false "order[desc asc] to get Post ids ordered by event time"
+// @Param   order   query string  false "order[desc asc] to get Post ids ordered by bookmark time. only for 'compat'"
+// @Param   limit   query int     false "page size, default is 20, max is 100"
+// @Param   before  query string  false "cursor ('next_cursor' of last page) for older Post ids"
+// @Param   after   query string  false "cursor ('prev_cursor' of last page) for newer Post ids"
+// @Param   compat  query boolean false "true: return plain id array as before, no paging"
 // @Success 200 "OK - get successfully"
 // @Failure 500 "Fail - internal error"
 // @Router /api/post/bookmark/bookmarked [get]
@@ -497,7 +497,7 @@ func BookmarkedPosts(c echo.Context) error {
 	if err != nil {
 		return c.String(http.StatusInternalServerError, err.Error())
 	}
-	return c.JSON(http.StatusOK, bm.Bookmarks(order))
+	return replyIDs(c, bm.Bookmarks(order))
 }
 
 // @Title get a Post follower-Post ids
@@ -507,6 +507,10 @@ func BookmarkedPosts(c echo.Context) error {
 // @Accept  json
 // @Produce json
 // @Param   followee query string true "followee Post ID"
+// @Param   limit   query int     false "page size, default is 20, max is 100"
+// @Param   before  query string  false "cursor ('next_cursor' of last page) for older Post ids"
+// @Param   after   query string  false "cursor ('prev_cursor' of last page) for newer Post ids"
+// @Param   compat  query boolean false "true: return plain id array as before, no paging"
 // @Success 200 "OK - get successfully"
 // @Failure 404 "Fail - empty follower ids"
 // @Failure 500 "Fail - internal error"
@@ -524,7 +528,7 @@ func Followers(c echo.Context) error {
 	// if len(flwers) == 0 {
 	// 	return c.JSON(http.StatusNotFound, flwers)
 	// }
-	return c.JSON(http.StatusOK, flwers)
+	return replyIDs(c, flwers)
 }
 
 // @Title add or remove a thumbsup for a post
@@ -545,26 +549,15 @@ func ThumbsUp(c echo.Context) error {
 		uname   = claims.UName
 		id      = c.Param("id")
 	)
-	ep, err := em.NewEventParticipate(id, true)
-	if err != nil {
-		return c.String(http.StatusInternalServerError, err.Error())
-	}
-	has, err := ep.TogglePtp("ThumbsUp", uname)
+	has, n, err := react(id, "ThumbsUp", uname)
 	if err != nil {
 		return c.String(http.StatusInternalServerError, err.Error())
 	}
-	ptps, err := ep.Ptps("ThumbsUp")
-	if err != nil {
-		return c.String(http.StatusInternalServerError, err.Error())
-	}
-
-	lk.Log("---> %v", ptps)
-
 	return c.JSON(http.StatusOK, struct {
 		ThumbsUp bool
 		Count    int
 	}{
-		has, len(ptps),
+		has, n,
 	})
 }
 
@@ -602,3 +595,42 @@ func ThumbsUpStatus(c echo.Context) error {
 		has, len(ptps),
 	})
 }
+
+// @Title get Post audit trail
+// @Summary get audit records of performed or refused Post deletion (admin only).
+// @Description
+// @Tags    Post
+// @Accept  json
+// @Produce json
+// @Param   actor  query string false "filter by who invoked the action"
+// @Param   target query string false "filter by target Post ID"
+// @Success 200 "OK - get audit trail successfully"
+// @Failure 401 "Fail - unauthorized error"
+// @Failure 500 "Fail - internal error"
+// @Router /api/post/audit [get]
+// @Security ApiKeyAuth
+func AuditList(c echo.Context) error {
+	var (
+		actor  = c.QueryParam("actor")
+		target = c.QueryParam("target")
+	)
+
+	if ok, err := admin.Only(c); !ok {
+		return err
+	}
+
+	audits, err := AuditTrail(func(a *Audit) bool {
+		switch {
+		case len(actor) > 0 && a.Actor != actor:
+			return false
+		case len(target) > 0 && a.Target != target:
+			return false
+		default:
+			return true
+		}
+	})
+	if err != nil {
+		return c.String(http.StatusInternalServerError, err.Error())
+	}
+	return c.JSON(http.StatusOK, audits)
+}
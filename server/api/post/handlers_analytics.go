@@ -0,0 +1,106 @@
+package post
+
+import (
+	"fmt"
+	"net/http"
+	"strconv"
+	"time"
+
+	u "github.com/digisan/user-mgr/user"
+	"github.com/golang-jwt/jwt/v4"
+	"github.com/labstack/echo/v4"
+	"github.com/wismed-web/wisite-api/server/api/admin"
+)
+
+// *** after implementing, register with path in 'post.go' (admin ones in 'admin.go') *** //
+
+// month query param [name] as 'yyyymm', [dft] if missing
+func monthParam(c echo.Context, name string, dft time.Time) (time.Time, error) {
+	s := c.QueryParam(name)
+	if len(s) == 0 {
+		s = dft.Format("200601")
+	}
+	tm, err := time.Parse("200601", s)
+	if err != nil {
+		return tm, fmt.Errorf("'%s' format must be 'yyyymm', e.g. '202206'", name)
+	}
+	return tm, nil
+}
+
+// @Title own Post analytics
+// @Summary author dashboard of views, reactions, bookmarks & comments on own Posts created in a month range. views are updated in batch, so latest ones may be counted a few seconds later.
+// @Description
+// @Tags    Post
+// @Accept  json
+// @Produce json
+// @Param   from query string false "first month as 'yyyymm', default is current month"
+// @Param   to   query string false "last month as 'yyyymm', default is current month. at most 12 months from 'from'"
+// @Success 200 "OK - get analytics successfully"
+// @Failure 400 "Fail - incorrect query param"
+// @Failure 500 "Fail - internal error"
+// @Router /api/post/analytics [get]
+// @Security ApiKeyAuth
+func Analytics(c echo.Context) error {
+	var (
+		userTkn = c.Get("user").(*jwt.Token)
+		claims  = userTkn.Claims.(*u.UserClaims)
+		uname   = claims.UName
+		now     = time.Now()
+	)
+
+	from, err := monthParam(c, "from", now)
+	if err != nil {
+		return c.String(http.StatusBadRequest, err.Error())
+	}
+	to, err := monthParam(c, "to", now)
+	if err != nil {
+		return c.String(http.StatusBadRequest, err.Error())
+	}
+	periods := months(from, to)
+	if len(periods) == 0 || len(periods) > maxAnalyticsMonths {
+		return c.String(http.StatusBadRequest, fmt.Sprintf("'to' must not be before 'from', and at most %d months", maxAnalyticsMonths))
+	}
+
+	dash, err := authorDashboard(uname, periods)
+	if err != nil {
+		return c.String(http.StatusInternalServerError, err.Error())
+	}
+	return c.JSON(http.StatusOK, dash)
+}
+
+// @Title site-wide top Posts & authors
+// @Summary admin gets most viewed Posts (with engagement) and authors in a month.
+// @Description
+// @Tags    Admin
+// @Accept  json
+// @Produce json
+// @Param   period query string false "month as 'yyyymm', default is current month"
+// @Param   top    query int    false "max count of Posts & authors, default is 10, max is 100"
+// @Success 200 "OK - get top list successfully"
+// @Failure 400 "Fail - incorrect query param"
+// @Failure 401 "Fail - unauthorized error"
+// @Failure 500 "Fail - internal error"
+// @Router /api/admin/analytics/top [get]
+// @Security ApiKeyAuth
+func TopAnalytics(c echo.Context) error {
+	if ok, err := admin.Only(c); !ok {
+		return err
+	}
+
+	period, err := monthParam(c, "period", time.Now())
+	if err != nil {
+		return c.String(http.StatusBadRequest, err.Error())
+	}
+	top := defaultTrendingTop
+	if s := c.QueryParam("top"); len(s) > 0 {
+		if top, err = strconv.Atoi(s); err != nil || top < 1 || top > maxPageSize {
+			return c.String(http.StatusBadRequest, fmt.Sprintf("'top' must be an integer in [1, %d]", maxPageSize))
+		}
+	}
+
+	stats, err := topStats(period.Format("200601"), top)
+	if err != nil {
+		return c.String(http.StatusInternalServerError, err.Error())
+	}
+	return c.JSON(http.StatusOK, stats)
+}
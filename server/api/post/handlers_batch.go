@@ -0,0 +1,45 @@
+package post
+
+import (
+	"fmt"
+	"net/http"
+
+	lk "github.com/digisan/logkit"
+	u "github.com/digisan/user-mgr/user"
+	"github.com/golang-jwt/jwt/v4"
+	"github.com/labstack/echo/v4"
+)
+
+// *** after implementing, register with path in 'post.go' *** //
+
+// @Title get many Post contents
+// @Summary get a batch of Post contents in one response. missing or deleted Post is reported per item.
+// @Description
+// @Tags    Post
+// @Accept  json
+// @Produce json
+// @Param   data   body  string  true  "Post ids json, e.g. {'ids': ['id1', 'id2']}, at most 100 ids"
+// @Success 200 "OK - get Post items in request order"
+// @Failure 400 "Fail - incorrect ids format or too many ids"
+// @Router /api/post/many [post]
+// @Security ApiKeyAuth
+func GetMany(c echo.Context) error {
+	var (
+		userTkn = c.Get("user").(*jwt.Token)
+		claims  = userTkn.Claims.(*u.UserClaims)
+		uname   = claims.UName
+	)
+
+	req := struct {
+		IDs []string `json:"ids"`
+	}{}
+	if err := c.Bind(&req); err != nil {
+		lk.Warn("incorrect ids format: " + err.Error())
+		return c.String(http.StatusBadRequest, "incorrect ids format: "+err.Error())
+	}
+	if len(req.IDs) > maxBatch {
+		return c.String(http.StatusBadRequest, fmt.Sprintf("too many ids, at most %d", maxBatch))
+	}
+
+	return c.JSON(http.StatusOK, fetchMany(req.IDs, uname, mediaBase(c)))
+}
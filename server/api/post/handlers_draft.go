@@ -0,0 +1,199 @@
+package post
+
+import (
+	"fmt"
+	"net/http"
+	"time"
+
+	lk "github.com/digisan/logkit"
+	u "github.com/digisan/user-mgr/user"
+	"github.com/golang-jwt/jwt/v4"
+	"github.com/labstack/echo/v4"
+)
+
+// *** after implementing, register with path in 'post.go' *** //
+
+// body of draft creating & updating
+type DraftReq struct {
+	Post      *Post     `json:"post"`       // filled Post template
+	Followee  string    `json:"followee"`   // followee Post ID, empty for a new post
+	PublishAt time.Time `json:"publish_at"` // RFC3339, missing means not scheduled
+}
+
+// bind, clean & sanitize draft request from [uname]
+func bindDraft(c echo.Context, uname string) (*DraftReq, error) {
+	req := new(DraftReq)
+	if err := c.Bind(req); err != nil {
+		lk.Warn("incorrect Draft format: " + err.Error())
+		return nil, fmt.Errorf("incorrect draft format: %v", err)
+	}
+	if req.Post == nil {
+		req.Post = new(Post)
+	}
+	if err := req.Post.clean(uname); err != nil {
+		return nil, err
+	}
+	sanitizeReport(c, uname, req.Post)
+	return req, nil
+}
+
+// @Title create a draft
+// @Summary save a Post as own draft. if 'publish_at' is given, it is published as a normal Post at that time.
+// @Description
+// @Tags    Post
+// @Accept  json
+// @Produce json
+// @Param   data body string true "draft json, e.g. {'post': {filled Post template}, 'followee': 'optional Post ID', 'publish_at': '2023-06-01T09:00:00+10:00'}"
+// @Success 200 "OK - create successfully, return draft id"
+// @Header  200 {string} X-Sanitize-Report "json {count, removed} of removed unsafe html elements & attributes, only first few listed, only if any"
+// @Failure 400 "Fail - incorrect draft format, or 'publish_at' is not future time"
+// @Failure 500 "Fail - internal error"
+// @Router /api/post/draft/new [post]
+// @Security ApiKeyAuth
+func NewDraft(c echo.Context) error {
+	var (
+		userTkn = c.Get("user").(*jwt.Token)
+		claims  = userTkn.Claims.(*u.UserClaims)
+		uname   = claims.UName
+	)
+
+	req, err := bindDraft(c, uname)
+	if err != nil {
+		return c.String(http.StatusBadRequest, err.Error())
+	}
+
+	d, err := newDraft(uname, req.Followee, req.PublishAt, req.Post)
+	switch {
+	case err == errPublishAtPast:
+		return c.String(http.StatusBadRequest, err.Error())
+	case err != nil:
+		return c.String(http.StatusInternalServerError, err.Error())
+	}
+	return c.JSON(http.StatusOK, d.ID)
+}
+
+// @Title update a draft
+// @Summary replace own draft content & schedule. without 'publish_at', it becomes unscheduled.
+// @Description
+// @Tags    Post
+// @Accept  json
+// @Produce json
+// @Param   id   path string true "draft id"
+// @Param   data body string true "draft json, e.g. {'post': {filled Post template}, 'followee': 'optional Post ID', 'publish_at': '2023-06-01T09:00:00+10:00'}"
+// @Success 200 "OK - update successfully, return updated draft"
+// @Header  200 {string} X-Sanitize-Report "json {count, removed} of removed unsafe html elements & attributes, only first few listed, only if any"
+// @Failure 400 "Fail - incorrect draft format, or 'publish_at' is not future time"
+// @Failure 404 "Fail - draft not found"
+// @Failure 500 "Fail - internal error"
+// @Router /api/post/draft/update/{id} [put]
+// @Security ApiKeyAuth
+func UpdateDraft(c echo.Context) error {
+	var (
+		userTkn = c.Get("user").(*jwt.Token)
+		claims  = userTkn.Claims.(*u.UserClaims)
+		uname   = claims.UName
+		id      = c.Param("id")
+	)
+
+	req, err := bindDraft(c, uname)
+	if err != nil {
+		return c.String(http.StatusBadRequest, err.Error())
+	}
+
+	d, err := updateDraft(uname, id, req.Followee, req.PublishAt, req.Post)
+	switch {
+	case err == errPublishAtPast:
+		return c.String(http.StatusBadRequest, err.Error())
+	case err == errDraftMissing:
+		return c.String(http.StatusNotFound, fmt.Sprintf("draft not found @%s", id))
+	case err != nil:
+		return c.String(http.StatusInternalServerError, err.Error())
+	}
+	return c.JSON(http.StatusOK, d)
+}
+
+// @Title list own drafts
+// @Summary list own drafts (without content), most recently updated first. scheduled one has 'publish_at'.
+// @Description
+// @Tags    Post
+// @Accept  json
+// @Produce json
+// @Success 200 "OK - list successfully"
+// @Failure 500 "Fail - internal error"
+// @Router /api/post/draft/list [get]
+// @Security ApiKeyAuth
+func DraftList(c echo.Context) error {
+	var (
+		userTkn = c.Get("user").(*jwt.Token)
+		claims  = userTkn.Claims.(*u.UserClaims)
+		uname   = claims.UName
+	)
+
+	drafts, err := Drafts(uname)
+	if err != nil {
+		return c.String(http.StatusInternalServerError, err.Error())
+	}
+	for _, d := range drafts {
+		d.Post = nil // only meta
+	}
+	return c.JSON(http.StatusOK, drafts)
+}
+
+// @Title get one draft
+// @Summary get one own draft with its content.
+// @Description
+// @Tags    Post
+// @Accept  json
+// @Produce json
+// @Param   id path string true "draft id"
+// @Success 200 "OK - get draft successfully"
+// @Failure 404 "Fail - draft not found"
+// @Failure 500 "Fail - internal error"
+// @Router /api/post/draft/one/{id} [get]
+// @Security ApiKeyAuth
+func DraftOne(c echo.Context) error {
+	var (
+		userTkn = c.Get("user").(*jwt.Token)
+		claims  = userTkn.Claims.(*u.UserClaims)
+		uname   = claims.UName
+		id      = c.Param("id")
+	)
+
+	d, err := FetchDraft(uname, id)
+	if err != nil {
+		return c.String(http.StatusInternalServerError, err.Error())
+	}
+	if d == nil {
+		return c.String(http.StatusNotFound, fmt.Sprintf("draft not found @%s", id))
+	}
+	return c.JSON(http.StatusOK, d)
+}
+
+// @Title delete a draft
+// @Summary delete one own draft, scheduled one is cancelled.
+// @Description
+// @Tags    Post
+// @Accept  json
+// @Produce json
+// @Param   id path string true "draft id"
+// @Success 200 "OK - delete successfully"
+// @Failure 404 "Fail - draft not found"
+// @Failure 500 "Fail - internal error"
+// @Router /api/post/draft/del/{id} [delete]
+// @Security ApiKeyAuth
+func DelDraft(c echo.Context) error {
+	var (
+		userTkn = c.Get("user").(*jwt.Token)
+		claims  = userTkn.Claims.(*u.UserClaims)
+		uname   = claims.UName
+		id      = c.Param("id")
+	)
+
+	switch err := deleteDraft(uname, id); {
+	case err == errDraftMissing:
+		return c.String(http.StatusNotFound, fmt.Sprintf("draft not found @%s", id))
+	case err != nil:
+		return c.String(http.StatusInternalServerError, err.Error())
+	}
+	return c.JSON(http.StatusOK, fmt.Sprintf("draft deleted @%s", id))
+}
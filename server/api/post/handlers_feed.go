@@ -0,0 +1,65 @@
+package post
+
+import (
+	"net/http"
+	"strconv"
+	"time"
+
+	u "github.com/digisan/user-mgr/user"
+	"github.com/golang-jwt/jwt/v4"
+	"github.com/labstack/echo/v4"
+)
+
+// *** after implementing, register with path in 'post.go' *** //
+
+// @Title get personalized feed
+// @Summary get a page of Post ids from followed users and self, without blocked or muted users.
+// @Description
+// @Tags    Post
+// @Accept  json
+// @Produce json
+// @Param   mode   query string false "'latest' (default) or 'popular' (ranked by reaction and comment counts)"
+// @Param   days   query int    false "only for 'popular', rank Posts in recent [days], default is 7"
+// @Param   limit  query int    false "page size, default is 20, max is 100"
+// @Param   before query string false "cursor ('next_cursor' of last page) for next page"
+// @Param   after  query string false "cursor ('prev_cursor' of last page) for previous page"
+// @Success 200 "OK - get feed page successfully"
+// @Failure 400 "Fail - incorrect query param"
+// @Failure 500 "Fail - internal error"
+// @Router /api/post/feed [get]
+// @Security ApiKeyAuth
+func Feed(c echo.Context) error {
+	var (
+		userTkn = c.Get("user").(*jwt.Token)
+		claims  = userTkn.Claims.(*u.UserClaims)
+		uname   = claims.UName
+		mode    = c.QueryParam("mode")
+		days    = 7
+	)
+
+	if mode != "" && mode != "latest" && mode != "popular" {
+		return c.String(http.StatusBadRequest, "'mode' must be one of [latest, popular]")
+	}
+	if s := c.QueryParam("days"); len(s) > 0 {
+		n, err := strconv.Atoi(s)
+		if err != nil || n < 1 {
+			return c.String(http.StatusBadRequest, "'days' must be a positive integer")
+		}
+		days = n
+	}
+	limit, before, after, err := pageParams(c)
+	if err != nil {
+		return c.String(http.StatusBadRequest, err.Error())
+	}
+
+	page, err := pageAlive(func() ([]cursor, error) {
+		return feedCursors(uname, mode == "popular", time.Now().AddDate(0, 0, -days))
+	}, limit, before, after)
+	switch {
+	case err == errCursor:
+		return c.String(http.StatusBadRequest, "'before' or 'after' is an invalid cursor")
+	case err != nil:
+		return c.String(http.StatusInternalServerError, err.Error())
+	}
+	return c.JSON(http.StatusOK, page)
+}
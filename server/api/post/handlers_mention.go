@@ -0,0 +1,44 @@
+package post
+
+import (
+	"net/http"
+
+	u "github.com/digisan/user-mgr/user"
+	"github.com/golang-jwt/jwt/v4"
+	"github.com/labstack/echo/v4"
+)
+
+// *** after implementing, register with path in 'post.go' *** //
+
+// @Title get Post ids mentioning me
+// @Summary get ids of Posts mentioning the caller by '@uname', newest first, cursor paged. Posts from blocked users are excluded.
+// @Description
+// @Tags    Post
+// @Accept  json
+// @Produce json
+// @Param   limit  query int     false "page size, default is 20, max is 100"
+// @Param   before query string  false "cursor ('next_cursor' of last page) for older Post ids"
+// @Param   after  query string  false "cursor ('prev_cursor' of last page) for newer Post ids"
+// @Param   compat query boolean false "true: return plain id array, no paging"
+// @Success 200 "OK - get successfully"
+// @Failure 400 "Fail - incorrect query param"
+// @Failure 500 "Fail - internal error"
+// @Router /api/post/mentions [get]
+// @Security ApiKeyAuth
+func MentionList(c echo.Context) error {
+	var (
+		userTkn = c.Get("user").(*jwt.Token)
+		claims  = userTkn.Claims.(*u.UserClaims)
+		uname   = claims.UName
+	)
+
+	mentions, err := Mentions(uname)
+	if err != nil {
+		return c.String(http.StatusInternalServerError, err.Error())
+	}
+	ids := []string{}
+	for _, m := range mentions {
+		ids = append(ids, m.PostID)
+	}
+	return replyIDs(c, ids)
+}
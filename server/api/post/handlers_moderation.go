@@ -0,0 +1,266 @@
+package post
+
+import (
+	"fmt"
+	"net/http"
+
+	em "github.com/digisan/event-mgr"
+	. "github.com/digisan/go-generics/v2"
+	u "github.com/digisan/user-mgr/user"
+	"github.com/golang-jwt/jwt/v4"
+	"github.com/labstack/echo/v4"
+	"github.com/wismed-web/wisite-api/server/api/admin"
+)
+
+// *** after implementing, register with path in 'post.go' (admin ones in 'admin.go') *** //
+
+// @Title report a Post
+// @Summary report a Post to admin for moderation. reporting the same Post again replaces own earlier report.
+// @Description
+// @Tags    Post
+// @Accept  json
+// @Produce json
+// @Param   id     path  string true  "Post ID for reporting"
+// @Param   reason query string true  "reason code, one of [spam, abuse, misinformation, illegal, other]"
+// @Param   note   query string false "more details for admin"
+// @Success 200 "OK - report successfully"
+// @Failure 400 "Fail - incorrect reason code, or reporting own Post"
+// @Failure 404 "Fail - Post not found"
+// @Failure 500 "Fail - internal error"
+// @Router /api/post/report/{id} [post]
+// @Security ApiKeyAuth
+func ReportPost(c echo.Context) error {
+	var (
+		userTkn = c.Get("user").(*jwt.Token)
+		claims  = userTkn.Claims.(*u.UserClaims)
+		uname   = claims.UName
+		id      = c.Param("id")
+	)
+
+	event, err := em.FetchEvent(true, id)
+	if err != nil {
+		return c.String(http.StatusInternalServerError, err.Error())
+	}
+	if event == nil || event.EvtType != "Post" || isHidden(id) {
+		return c.String(http.StatusNotFound, fmt.Sprintf("Post not found @%s", id))
+	}
+
+	switch err := report(event, uname, c.QueryParam("reason"), c.QueryParam("note")); {
+	case err == errReportReason || err == errReportSelf:
+		return c.String(http.StatusBadRequest, err.Error())
+	case err != nil:
+		return c.String(http.StatusInternalServerError, err.Error())
+	}
+	return c.JSON(http.StatusOK, fmt.Sprintf("Post reported @%s", id))
+}
+
+// @Title list own warnings
+// @Summary list warnings from admin on own Posts, oldest first.
+// @Description
+// @Tags    Post
+// @Accept  json
+// @Produce json
+// @Success 200 "OK - list successfully"
+// @Failure 500 "Fail - internal error"
+// @Router /api/post/warnings [get]
+// @Security ApiKeyAuth
+func WarningList(c echo.Context) error {
+	var (
+		userTkn = c.Get("user").(*jwt.Token)
+		claims  = userTkn.Claims.(*u.UserClaims)
+		uname   = claims.UName
+	)
+
+	warnings, err := Warnings(uname)
+	if err != nil {
+		return c.String(http.StatusInternalServerError, err.Error())
+	}
+	return c.JSON(http.StatusOK, warnings)
+}
+
+// @Title moderation queue
+// @Summary admin lists reported Posts with report counts, Posts with most pending reports first.
+// @Description
+// @Tags    Admin
+// @Accept  json
+// @Produce json
+// @Param   status query string false "filter by status, one of [open, hidden, restored, removed, all], default is open"
+// @Success 200 "OK - list successfully"
+// @Failure 400 "Fail - incorrect status"
+// @Failure 401 "Fail - unauthorized error"
+// @Failure 500 "Fail - internal error"
+// @Router /api/admin/moderation/queue [get]
+// @Security ApiKeyAuth
+func ModerationQueue(c echo.Context) error {
+	if ok, err := admin.Only(c); !ok {
+		return err
+	}
+
+	status := c.QueryParam("status")
+	switch {
+	case len(status) == 0:
+		status = "open"
+	case status == "all":
+		status = ""
+	case NotIn(status, "open", "hidden", "restored", "removed"):
+		return c.String(http.StatusBadRequest, "'status' must be one of [open, hidden, restored, removed, all]")
+	}
+
+	items, err := moderationQueue(status)
+	if err != nil {
+		return c.String(http.StatusInternalServerError, err.Error())
+	}
+	return c.JSON(http.StatusOK, items)
+}
+
+// fetch Post [id] for admin moderation. [alive] false for including deleted one
+func moderatedPost(c echo.Context, alive bool) (*em.Event, string, error) {
+	if ok, err := admin.Only(c); !ok {
+		return nil, "", err
+	}
+	var (
+		userTkn = c.Get("user").(*jwt.Token)
+		claims  = userTkn.Claims.(*u.UserClaims)
+		uname   = claims.UName
+		id      = c.Param("id")
+	)
+	event, err := em.FetchEvent(alive, id)
+	if err != nil {
+		return nil, "", c.String(http.StatusInternalServerError, err.Error())
+	}
+	if event == nil || event.EvtType != "Post" {
+		return nil, "", c.String(http.StatusNotFound, fmt.Sprintf("Post not found @%s", id))
+	}
+	return event, uname, nil
+}
+
+// @Title hide a Post
+// @Summary admin hides a Post. it disappears from all listings & search, only its owner & admin can still get it.
+// @Description
+// @Tags    Admin
+// @Accept  json
+// @Produce json
+// @Param   id   path  string true  "Post ID for hiding"
+// @Param   note query string false "moderation note, recorded in audit trail"
+// @Success 200 "OK - hide successfully"
+// @Failure 401 "Fail - unauthorized error"
+// @Failure 404 "Fail - Post not found"
+// @Failure 500 "Fail - internal error"
+// @Router /api/admin/moderation/hide/{id} [put]
+// @Security ApiKeyAuth
+func HidePost(c echo.Context) error {
+	event, uname, err := moderatedPost(c, true)
+	if event == nil {
+		return err
+	}
+	if err := moderate(event, uname, "hidden", c.QueryParam("note")); err != nil {
+		return c.String(http.StatusInternalServerError, err.Error())
+	}
+	return c.JSON(http.StatusOK, fmt.Sprintf("Post hidden @%s", event.ID))
+}
+
+// @Title restore a Post
+// @Summary admin restores a hidden or reported Post, its pending reports are dismissed.
+// @Description
+// @Tags    Admin
+// @Accept  json
+// @Produce json
+// @Param   id   path  string true  "Post ID for restoring"
+// @Param   note query string false "moderation note, recorded in audit trail"
+// @Success 200 "OK - restore successfully"
+// @Failure 401 "Fail - unauthorized error"
+// @Failure 404 "Fail - Post not found"
+// @Failure 500 "Fail - internal error"
+// @Router /api/admin/moderation/restore/{id} [put]
+// @Security ApiKeyAuth
+func RestorePost(c echo.Context) error {
+	event, uname, err := moderatedPost(c, true)
+	if event == nil {
+		return err
+	}
+	if err := moderate(event, uname, "restored", c.QueryParam("note")); err != nil {
+		return c.String(http.StatusInternalServerError, err.Error())
+	}
+	return c.JSON(http.StatusOK, fmt.Sprintf("Post restored @%s", event.ID))
+}
+
+// @Title remove a Post
+// @Summary admin removes (deletes) a reported Post.
+// @Description
+// @Tags    Admin
+// @Accept  json
+// @Produce json
+// @Param   id   path  string true  "Post ID for removing"
+// @Param   note query string false "moderation note, recorded in audit trail"
+// @Success 200 "OK - remove successfully"
+// @Failure 401 "Fail - unauthorized error"
+// @Failure 404 "Fail - Post not found"
+// @Failure 500 "Fail - internal error"
+// @Router /api/admin/moderation/remove/{id} [delete]
+// @Security ApiKeyAuth
+func RemovePost(c echo.Context) error {
+	event, uname, err := moderatedPost(c, true)
+	if event == nil {
+		return err
+	}
+	if err := moderate(event, uname, "removed", c.QueryParam("note")); err != nil {
+		return c.String(http.StatusInternalServerError, err.Error())
+	}
+	return c.JSON(http.StatusOK, fmt.Sprintf("Post removed @%s", event.ID))
+}
+
+// @Title warn a Post author
+// @Summary admin warns the author of a Post. the author gets it in warning list, and by websocket if online.
+// @Description
+// @Tags    Admin
+// @Accept  json
+// @Produce json
+// @Param   id   path  string true "Post ID whose author is warned"
+// @Param   note query string true "warning message to author"
+// @Success 200 "OK - warn successfully"
+// @Failure 400 "Fail - empty warning message"
+// @Failure 401 "Fail - unauthorized error"
+// @Failure 404 "Fail - Post not found"
+// @Failure 500 "Fail - internal error"
+// @Router /api/admin/moderation/warn/{id} [put]
+// @Security ApiKeyAuth
+func WarnAuthor(c echo.Context) error {
+	event, uname, err := moderatedPost(c, false)
+	if event == nil {
+		return err
+	}
+	note := c.QueryParam("note")
+	if len(note) == 0 {
+		return c.String(http.StatusBadRequest, "'note' is invalid (cannot be empty)")
+	}
+	w, err := warn(event, uname, note)
+	if err != nil {
+		return c.String(http.StatusInternalServerError, err.Error())
+	}
+	return c.JSON(http.StatusOK, w)
+}
+
+// @Title deactivate a Post author
+// @Summary admin deactivates the account of a Post author.
+// @Description
+// @Tags    Admin
+// @Accept  json
+// @Produce json
+// @Param   id   path  string true  "Post ID whose author is deactivated"
+// @Param   note query string false "moderation note, recorded in audit trail"
+// @Success 200 "OK - deactivate successfully"
+// @Failure 401 "Fail - unauthorized error"
+// @Failure 404 "Fail - Post not found"
+// @Failure 500 "Fail - internal error"
+// @Router /api/admin/moderation/deactivate/{id} [put]
+// @Security ApiKeyAuth
+func DeactivateAuthor(c echo.Context) error {
+	event, uname, err := moderatedPost(c, false)
+	if event == nil {
+		return err
+	}
+	if err := deactivate(event, uname, c.QueryParam("note")); err != nil {
+		return c.String(http.StatusInternalServerError, err.Error())
+	}
+	return c.JSON(http.StatusOK, fmt.Sprintf("author deactivated @%s", event.Owner))
+}
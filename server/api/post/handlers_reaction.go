@@ -0,0 +1,140 @@
+package post
+
+import (
+	"fmt"
+	"net/http"
+
+	em "github.com/digisan/event-mgr"
+	u "github.com/digisan/user-mgr/user"
+	"github.com/golang-jwt/jwt/v4"
+	"github.com/labstack/echo/v4"
+)
+
+// *** after implementing, register with path in 'post.go' *** //
+
+// @Title get reaction types
+// @Summary get all available reaction types.
+// @Description
+// @Tags    Post
+// @Accept  json
+// @Produce json
+// @Success 200 "OK - get reaction types successfully"
+// @Router /api/post/react/types [get]
+// @Security ApiKeyAuth
+func ReactionTypes(c echo.Context) error {
+	return c.JSON(http.StatusOK, Reactions())
+}
+
+// @Title add or remove a reaction for a post
+// @Summary add or remove a personal reaction of one type for a post.
+// @Description
+// @Tags    Post
+// @Accept  json
+// @Produce json
+// @Param   id   path  string true "Post ID (event id) for adding or removing reaction"
+// @Param   type query string true "reaction type, one of '/api/post/react/types'"
+// @Success 200 "OK - added or removed reaction successfully"
+// @Failure 400 "Fail - unsupported reaction type"
+// @Failure 404 "Fail - Post not found"
+// @Failure 500 "Fail - internal error"
+// @Router /api/post/react/{id} [patch]
+// @Security ApiKeyAuth
+func React(c echo.Context) error {
+	var (
+		userTkn = c.Get("user").(*jwt.Token)
+		claims  = userTkn.Claims.(*u.UserClaims)
+		uname   = claims.UName
+		id      = c.Param("id")
+	)
+	typ, err := reactionType(c.QueryParam("type"))
+	if err != nil {
+		return c.String(http.StatusBadRequest, fmt.Sprintf("'type' must be one of %v", Reactions()))
+	}
+	if !em.EventIsAlive(id) {
+		return c.String(http.StatusNotFound, fmt.Sprintf("Post not found @%s", id))
+	}
+	has, n, err := react(id, typ, uname)
+	if err != nil {
+		return c.String(http.StatusInternalServerError, err.Error())
+	}
+	return c.JSON(http.StatusOK, struct {
+		Type    string `json:"type"`
+		Reacted bool   `json:"reacted"`
+		Count   int    `json:"count"`
+	}{
+		typ, has, n,
+	})
+}
+
+// @Title get reaction status for a post
+// @Summary get each reaction count and current login user's reactions for a post.
+// @Description
+// @Tags    Post
+// @Accept  json
+// @Produce json
+// @Param   id path string true "Post ID (event id) for checking reaction status"
+// @Success 200 "OK - get reaction status successfully"
+// @Failure 404 "Fail - Post not found"
+// @Failure 500 "Fail - internal error"
+// @Router /api/post/react/status/{id} [get]
+// @Security ApiKeyAuth
+func ReactionStatus(c echo.Context) error {
+	var (
+		userTkn = c.Get("user").(*jwt.Token)
+		claims  = userTkn.Claims.(*u.UserClaims)
+		uname   = claims.UName
+		id      = c.Param("id")
+	)
+	if !em.EventIsAlive(id) {
+		return c.String(http.StatusNotFound, fmt.Sprintf("Post not found @%s", id))
+	}
+	counts, mine, err := reactionStatus(id, uname)
+	if err != nil {
+		return c.String(http.StatusInternalServerError, err.Error())
+	}
+	return c.JSON(http.StatusOK, struct {
+		Counts map[string]int `json:"counts"`
+		Mine   []string       `json:"mine"`
+	}{
+		counts, mine,
+	})
+}
+
+// @Title get who reacted on a post
+// @Summary get user names for each reaction on a post, users blocked by current login user are excluded.
+// @Description
+// @Tags    Post
+// @Accept  json
+// @Produce json
+// @Param   id   path  string true  "Post ID (event id) for listing reactors"
+// @Param   type query string false "only this reaction type. if missing, all types are listed"
+// @Success 200 "OK - get reactors successfully"
+// @Failure 400 "Fail - unsupported reaction type"
+// @Failure 404 "Fail - Post not found"
+// @Failure 500 "Fail - internal error"
+// @Router /api/post/react/list/{id} [get]
+// @Security ApiKeyAuth
+func Reactors(c echo.Context) error {
+	var (
+		userTkn = c.Get("user").(*jwt.Token)
+		claims  = userTkn.Claims.(*u.UserClaims)
+		uname   = claims.UName
+		id      = c.Param("id")
+		types   = Reactions()
+	)
+	if typ := c.QueryParam("type"); len(typ) > 0 {
+		canonical, err := reactionType(typ)
+		if err != nil {
+			return c.String(http.StatusBadRequest, fmt.Sprintf("'type' must be one of %v", Reactions()))
+		}
+		types = []string{canonical}
+	}
+	if !em.EventIsAlive(id) {
+		return c.String(http.StatusNotFound, fmt.Sprintf("Post not found @%s", id))
+	}
+	m, err := reactors(id, uname, types...)
+	if err != nil {
+		return c.String(http.StatusInternalServerError, err.Error())
+	}
+	return c.JSON(http.StatusOK, m)
+}
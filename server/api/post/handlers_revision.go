@@ -0,0 +1,151 @@
+package post
+
+import (
+	"fmt"
+	"net/http"
+	"strconv"
+
+	lk "github.com/digisan/logkit"
+	u "github.com/digisan/user-mgr/user"
+	"github.com/golang-jwt/jwt/v4"
+	"github.com/labstack/echo/v4"
+	"github.com/wismed-web/wisite-api/server/api/admin"
+)
+
+// *** after implementing, register with path in 'post.go' *** //
+
+// @Title edit a Post
+// @Summary edit own Post by a filled Post template. earlier Post body is kept as a revision.
+// @Description
+// @Tags    Post
+// @Accept  json
+// @Produce json
+// @Param   id   path string true "Post ID for editing"
+// @Param   data body string true "filled Post template json file"
+// @Success 200 "OK - edit successfully, return archived revision meta"
+// @Header  200 {string} X-Sanitize-Report "json {count, removed} of removed unsafe html elements & attributes, only first few listed, only if any"
+// @Failure 400 "Fail - incorrect Post format"
+// @Failure 403 "Fail - not Post owner"
+// @Failure 404 "Fail - Post not found"
+// @Failure 500 "Fail - internal error"
+// @Router /api/post/edit/{id} [put]
+// @Security ApiKeyAuth
+func Edit(c echo.Context) error {
+	var (
+		userTkn = c.Get("user").(*jwt.Token)
+		claims  = userTkn.Claims.(*u.UserClaims)
+		uname   = claims.UName
+		id      = c.Param("id")
+	)
+
+	P := new(Post)
+	if err := c.Bind(P); err != nil {
+		lk.Warn("incorrect Edited Post format: " + err.Error())
+		return c.String(http.StatusBadRequest, "incorrect Post format: "+err.Error())
+	}
+	lk.Log("Editing ---> [%s] --- <%s> --- %v", uname, id, P)
+
+	if err := P.clean(uname); err != nil {
+		return c.String(http.StatusBadRequest, err.Error())
+	}
+	sanitizeReport(c, uname, P)
+
+	rev, err := editPost(id, uname, P)
+	switch {
+	case err == errPostMissing:
+		return c.String(http.StatusNotFound, fmt.Sprintf("Post not found @%s", id))
+	case err == errNotOwner:
+		return c.String(http.StatusForbidden, err.Error())
+	case err != nil:
+		return c.String(http.StatusInternalServerError, err.Error())
+	}
+
+	rev.Post = nil // only meta
+	return c.JSON(http.StatusOK, rev)
+}
+
+// @Title list revisions of a Post
+// @Summary list all earlier revision meta (seq, author, time) of a Post.
+// @Description
+// @Tags    Post
+// @Accept  json
+// @Produce json
+// @Param   id path string true "Post ID for its revisions"
+// @Success 200 "OK - list successfully"
+// @Failure 404 "Fail - Post not found"
+// @Failure 500 "Fail - internal error"
+// @Router /api/post/revision/list/{id} [get]
+// @Security ApiKeyAuth
+func RevisionList(c echo.Context) error {
+	var (
+		userTkn = c.Get("user").(*jwt.Token)
+		claims  = userTkn.Claims.(*u.UserClaims)
+		uname   = claims.UName
+		id      = c.Param("id")
+	)
+	event, err := visiblePost(id, uname)
+	if err != nil {
+		return c.String(http.StatusInternalServerError, err.Error())
+	}
+	if event == nil {
+		return c.String(http.StatusNotFound, fmt.Sprintf("Post not found @%s", id))
+	}
+	revs, err := Revisions(id)
+	if err != nil {
+		return c.String(http.StatusInternalServerError, err.Error())
+	}
+	for _, rev := range revs {
+		rev.Post = nil // only meta
+	}
+	return c.JSON(http.StatusOK, revs)
+}
+
+// @Title get one revision of a Post
+// @Summary get one earlier Post body by its revision seq. only Post owner or admin can get it.
+// @Description
+// @Tags    Post
+// @Accept  json
+// @Produce json
+// @Param   id  path  string true "Post ID"
+// @Param   seq query int    true "revision seq, 0 is the original body"
+// @Success 200 "OK - get revision successfully"
+// @Failure 400 "Fail - incorrect query param seq"
+// @Failure 403 "Fail - neither Post owner nor admin"
+// @Failure 404 "Fail - Post or revision not found"
+// @Failure 500 "Fail - internal error"
+// @Router /api/post/revision/one/{id} [get]
+// @Security ApiKeyAuth
+func RevisionOne(c echo.Context) error {
+	var (
+		userTkn = c.Get("user").(*jwt.Token)
+		claims  = userTkn.Claims.(*u.UserClaims)
+		uname   = claims.UName
+		id      = c.Param("id")
+	)
+	seq, err := strconv.Atoi(c.QueryParam("seq"))
+	if err != nil || seq < 0 {
+		return c.String(http.StatusBadRequest, "'seq' must be a valid non-negative number")
+	}
+	event, err := visiblePost(id, uname)
+	if err != nil {
+		return c.String(http.StatusInternalServerError, err.Error())
+	}
+	if event == nil {
+		return c.String(http.StatusNotFound, fmt.Sprintf("Post not found @%s", id))
+	}
+	isAdmin, err := admin.IsAdmin(uname)
+	if err != nil {
+		return c.String(http.StatusInternalServerError, err.Error())
+	}
+	if event.Owner != uname && !isAdmin {
+		return c.String(http.StatusForbidden, "only Post owner or admin can get its revision")
+	}
+	rev, err := FetchRevision(id, seq)
+	if err != nil {
+		return c.String(http.StatusInternalServerError, err.Error())
+	}
+	if rev == nil {
+		return c.String(http.StatusNotFound, fmt.Sprintf("revision #%d not found @%s", seq, id))
+	}
+	return c.JSON(http.StatusOK, rev)
+}
@@ -0,0 +1,97 @@
+package post
+
+import (
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/labstack/echo/v4"
+)
+
+// *** after implementing, register with path in 'post.go' *** //
+
+// accept 'yyyy-mm-dd' or RFC3339. for 'yyyy-mm-dd' as upper bound, the whole day is included
+func parseDate(s string, upper bool) (time.Time, error) {
+	if len(s) == 0 {
+		return time.Time{}, nil
+	}
+	if tm, err := time.Parse(time.RFC3339, s); err == nil {
+		return tm, nil
+	}
+	tm, err := time.ParseInLocation("2006-01-02", s, time.Local)
+	if err != nil {
+		return time.Time{}, fmt.Errorf("'%s' should be 'yyyy-mm-dd' or RFC3339", s)
+	}
+	if upper {
+		tm = tm.Add(24*time.Hour - time.Nanosecond)
+	}
+	return tm, nil
+}
+
+// @Title search Post
+// @Summary full-text search Post (including comment) by topic, keywords and paragraph text.
+// @Description words in double quotes are a phrase which must be matched. hits in keywords & topic rank higher.
+// @Tags    Post
+// @Accept  json
+// @Produce json
+// @Param   q     query string true  "search text, e.g. 'covid \"side effect\"' or '疫苗 副作用'"
+// @Param   owner query string false "only Post from this owner"
+// @Param   from  query string false "only Post created from this date, 'yyyy-mm-dd' or RFC3339"
+// @Param   to    query string false "only Post created until this date, 'yyyy-mm-dd' or RFC3339"
+// @Param   page  query int    false "page number, start from 1. default is 1"
+// @Param   size  query int    false "page size, default is 20, max is 100"
+// @Success 200 "OK - search successfully, return ranked hits of requested page & total count"
+// @Failure 400 "Fail - incorrect query param"
+// @Router /api/post/search [get]
+// @Security ApiKeyAuth
+func SearchPosts(c echo.Context) error {
+	var (
+		q     = strings.TrimSpace(c.QueryParam("q"))
+		owner = c.QueryParam("owner")
+		page  = 1
+		size  = defaultPageSize
+		err   error
+	)
+	if len(q) == 0 {
+		return c.String(http.StatusBadRequest, "'q' is invalid (cannot be empty)")
+	}
+	if s := c.QueryParam("page"); len(s) > 0 {
+		if page, err = strconv.Atoi(s); err != nil || page < 1 {
+			return c.String(http.StatusBadRequest, "'page' must be a positive integer")
+		}
+	}
+	if s := c.QueryParam("size"); len(s) > 0 {
+		if size, err = strconv.Atoi(s); err != nil || size < 1 || size > maxPageSize {
+			return c.String(http.StatusBadRequest, fmt.Sprintf("'size' must be an integer in [1, %d]", maxPageSize))
+		}
+	}
+	from, err := parseDate(c.QueryParam("from"), false)
+	if err != nil {
+		return c.String(http.StatusBadRequest, "'from' is invalid: "+err.Error())
+	}
+	to, err := parseDate(c.QueryParam("to"), true)
+	if err != nil {
+		return c.String(http.StatusBadRequest, "'to' is invalid: "+err.Error())
+	}
+
+	hits := Search(q, SearchOpt{Owner: owner, From: from, To: to})
+
+	start, end := (page-1)*size, page*size
+	if start > len(hits) {
+		start = len(hits)
+	}
+	if end > len(hits) {
+		end = len(hits)
+	}
+
+	return c.JSON(http.StatusOK, struct {
+		Total int   `json:"total"`
+		Page  int   `json:"page"`
+		Size  int   `json:"size"`
+		Hits  []Hit `json:"hits"`
+	}{
+		len(hits), page, size, hits[start:end],
+	})
+}
@@ -0,0 +1,69 @@
+package post
+
+import (
+	"fmt"
+	"net/http"
+
+	u "github.com/digisan/user-mgr/user"
+	"github.com/golang-jwt/jwt/v4"
+	"github.com/labstack/echo/v4"
+)
+
+// *** after implementing, register with path in 'post.go' *** //
+
+// @Title share one Post
+// @Summary share one Post to followers with optional comment. sharing a Share shares its original Post.
+// @Description
+// @Tags    Post
+// @Accept  json
+// @Produce json
+// @Param   id      path  string true  "original Post id"
+// @Param   comment query string false "optional comment, at most 500 characters"
+// @Success 200 "OK - share successfully, return share id"
+// @Failure 400 "Fail - invalid comment"
+// @Failure 404 "Fail - Post not found"
+// @Failure 500 "Fail - internal error"
+// @Router /api/post/share/{id} [post]
+// @Security ApiKeyAuth
+func SharePost(c echo.Context) error {
+	var (
+		userTkn = c.Get("user").(*jwt.Token)
+		claims  = userTkn.Claims.(*u.UserClaims)
+		uname   = claims.UName
+		id      = c.Param("id")
+	)
+	evt, err := sharePost(uname, id, c.QueryParam("comment"))
+	switch {
+	case err == errShareComment:
+		return c.String(http.StatusBadRequest, err.Error())
+	case err == errShareOrigin:
+		return c.String(http.StatusNotFound, fmt.Sprintf("Post not found @%s", id))
+	case err != nil:
+		return c.String(http.StatusInternalServerError, err.Error())
+	}
+	return c.JSON(http.StatusOK, evt.ID)
+}
+
+// @Title get Share ids of one Post
+// @Summary get ids of alive Shares of one Post, newest first, cursor paged. unshare by deleting the Share as one Post.
+// @Description
+// @Tags    Post
+// @Accept  json
+// @Produce json
+// @Param   id     path  string  true  "original Post id"
+// @Param   limit  query int     false "page size, default is 20, max is 100"
+// @Param   before query string  false "cursor ('next_cursor' of last page) for older ids"
+// @Param   after  query string  false "cursor ('prev_cursor' of last page) for newer ids"
+// @Param   compat query boolean false "true: return plain id array, no paging"
+// @Success 200 "OK - get successfully"
+// @Failure 400 "Fail - incorrect query param"
+// @Failure 500 "Fail - internal error"
+// @Router /api/post/share/ids/{id} [get]
+// @Security ApiKeyAuth
+func ShareList(c echo.Context) error {
+	ids, err := shareIDs(c.Param("id"))
+	if err != nil {
+		return c.String(http.StatusInternalServerError, err.Error())
+	}
+	return replyIDs(c, ids)
+}
@@ -0,0 +1,216 @@
+package post
+
+import (
+	"fmt"
+	"net/http"
+	"strconv"
+	"time"
+
+	lk "github.com/digisan/logkit"
+	"github.com/labstack/echo/v4"
+	"github.com/wismed-web/wisite-api/server/api/admin"
+)
+
+// *** after implementing, register with path in 'post.go' (admin ones in 'admin.go') *** //
+
+const (
+	defaultTrendingHours = 24
+	maxTrendingHours     = 24 * 30
+	defaultTrendingTop   = 10
+)
+
+// @Title list categories
+// @Summary list all admin-defined Post categories with their Post counts.
+// @Description
+// @Tags    Post
+// @Accept  json
+// @Produce json
+// @Success 200 "OK - list successfully"
+// @Failure 500 "Fail - internal error"
+// @Router /api/post/category/list [get]
+// @Security ApiKeyAuth
+func CategoryList(c echo.Context) error {
+	cats, err := Categories()
+	if err != nil {
+		return c.String(http.StatusInternalServerError, err.Error())
+	}
+	mCount := CategoryCounts()
+
+	type CategoryCount struct {
+		*Category
+		Count int `json:"count"`
+	}
+	list := []CategoryCount{}
+	for _, cat := range cats {
+		list = append(list, CategoryCount{cat, mCount[cat.ID]})
+	}
+	return c.JSON(http.StatusOK, list)
+}
+
+// @Title get Post ids of a category
+// @Summary get Post ids in one category, newest first, cursor paged.
+// @Description
+// @Tags    Post
+// @Accept  json
+// @Produce json
+// @Param   id     path  string  true  "category id"
+// @Param   limit  query int     false "page size, default is 20, max is 100"
+// @Param   before query string  false "cursor ('next_cursor' of last page) for older Post ids"
+// @Param   after  query string  false "cursor ('prev_cursor' of last page) for newer Post ids"
+// @Param   compat query boolean false "true: return plain id array, no paging"
+// @Success 200 "OK - get successfully"
+// @Failure 400 "Fail - incorrect query param"
+// @Failure 404 "Fail - category not found"
+// @Failure 500 "Fail - internal error"
+// @Router /api/post/category/ids/{id} [get]
+// @Security ApiKeyAuth
+func CategoryPosts(c echo.Context) error {
+	id := c.Param("id")
+	cat, err := FetchCategory(id)
+	if err != nil {
+		return c.String(http.StatusInternalServerError, err.Error())
+	}
+	if cat == nil {
+		return c.String(http.StatusNotFound, fmt.Sprintf("category not found @%s", id))
+	}
+	return replyIDs(c, CategoryIDs(id))
+}
+
+// @Title list tags
+// @Summary list tags with their Post counts, most used first.
+// @Description
+// @Tags    Post
+// @Accept  json
+// @Produce json
+// @Param   prefix query string false "only tags starting with this, e.g. for auto-completion"
+// @Param   limit  query int    false "max count of tags, default is 20, max is 100"
+// @Success 200 "OK - list successfully"
+// @Failure 400 "Fail - incorrect query param"
+// @Router /api/post/tag/list [get]
+// @Security ApiKeyAuth
+func TagList(c echo.Context) error {
+	limit := defaultPageSize
+	if s := c.QueryParam("limit"); len(s) > 0 {
+		n, err := strconv.Atoi(s)
+		if err != nil || n < 1 || n > maxPageSize {
+			return c.String(http.StatusBadRequest, fmt.Sprintf("'limit' must be an integer in [1, %d]", maxPageSize))
+		}
+		limit = n
+	}
+	counts := TagCounts(c.QueryParam("prefix"))
+	if len(counts) > limit {
+		counts = counts[:limit]
+	}
+	return c.JSON(http.StatusOK, counts)
+}
+
+// @Title get Post ids of a tag
+// @Summary get Post ids with one tag, newest first, cursor paged.
+// @Description
+// @Tags    Post
+// @Accept  json
+// @Produce json
+// @Param   tag    path  string  true  "tag, normalized as lower case with '-' for space"
+// @Param   limit  query int     false "page size, default is 20, max is 100"
+// @Param   before query string  false "cursor ('next_cursor' of last page) for older Post ids"
+// @Param   after  query string  false "cursor ('prev_cursor' of last page) for newer Post ids"
+// @Param   compat query boolean false "true: return plain id array, no paging"
+// @Success 200 "OK - get successfully"
+// @Failure 400 "Fail - incorrect query param"
+// @Failure 500 "Fail - internal error"
+// @Router /api/post/tag/ids/{tag} [get]
+// @Security ApiKeyAuth
+func TagPosts(c echo.Context) error {
+	return replyIDs(c, TaggedIDs(c.Param("tag")))
+}
+
+// @Title trending tags
+// @Summary most used tags of Posts created within a recent time window.
+// @Description
+// @Tags    Post
+// @Accept  json
+// @Produce json
+// @Param   hours query int false "time window in hours, default is 24, max is 720"
+// @Param   top   query int false "max count of tags, default is 10, max is 100"
+// @Success 200 "OK - get trending tags successfully"
+// @Failure 400 "Fail - incorrect query param"
+// @Router /api/post/tag/trending [get]
+// @Security ApiKeyAuth
+func TrendingTagList(c echo.Context) error {
+	var (
+		hours = defaultTrendingHours
+		top   = defaultTrendingTop
+		err   error
+	)
+	if s := c.QueryParam("hours"); len(s) > 0 {
+		if hours, err = strconv.Atoi(s); err != nil || hours < 1 || hours > maxTrendingHours {
+			return c.String(http.StatusBadRequest, fmt.Sprintf("'hours' must be an integer in [1, %d]", maxTrendingHours))
+		}
+	}
+	if s := c.QueryParam("top"); len(s) > 0 {
+		if top, err = strconv.Atoi(s); err != nil || top < 1 || top > maxPageSize {
+			return c.String(http.StatusBadRequest, fmt.Sprintf("'top' must be an integer in [1, %d]", maxPageSize))
+		}
+	}
+	return c.JSON(http.StatusOK, TrendingTags(time.Duration(hours)*time.Hour, top))
+}
+
+// @Title create or update a category
+// @Summary admin creates a Post category, or updates its name & description if id exists.
+// @Description
+// @Tags    Admin
+// @Accept  json
+// @Produce json
+// @Param   data body string true "category json, e.g. {'id': 'covid-19', 'name': 'COVID-19', 'desc': 'pandemic related'}"
+// @Success 200 "OK - save successfully"
+// @Failure 400 "Fail - incorrect category format or id"
+// @Failure 401 "Fail - unauthorized error"
+// @Failure 500 "Fail - internal error"
+// @Router /api/admin/category/save [post]
+// @Security ApiKeyAuth
+func SaveCategory(c echo.Context) error {
+	if ok, err := admin.Only(c); !ok {
+		return err
+	}
+
+	cat := new(Category)
+	if err := c.Bind(cat); err != nil {
+		lk.Warn("incorrect Category format: " + err.Error())
+		return c.String(http.StatusBadRequest, "incorrect category format: "+err.Error())
+	}
+	switch err := saveCategory(cat); {
+	case err == errCategoryID:
+		return c.String(http.StatusBadRequest, err.Error())
+	case err != nil:
+		return c.String(http.StatusInternalServerError, err.Error())
+	}
+	return c.JSON(http.StatusOK, cat)
+}
+
+// @Title delete a category
+// @Summary admin deletes a Post category. Posts in it are no longer listed by category.
+// @Description
+// @Tags    Admin
+// @Accept  json
+// @Produce json
+// @Param   id path string true "category id"
+// @Success 200 "OK - delete successfully"
+// @Failure 401 "Fail - unauthorized error"
+// @Failure 404 "Fail - category not found"
+// @Failure 500 "Fail - internal error"
+// @Router /api/admin/category/del/{id} [delete]
+// @Security ApiKeyAuth
+func DelCategory(c echo.Context) error {
+	if ok, err := admin.Only(c); !ok {
+		return err
+	}
+
+	id := c.Param("id")
+	switch err := deleteCategory(id); {
+	case err == errCategoryMissing:
+		return c.String(http.StatusNotFound, fmt.Sprintf("category not found @%s", id))
+	case err != nil:
+		return c.String(http.StatusInternalServerError, err.Error())
+	}
+	return c.JSON(http.StatusOK, fmt.Sprintf("category deleted @%s", id))
+}
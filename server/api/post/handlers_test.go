@@ -0,0 +1,200 @@
+package post
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"testing"
+
+	em "github.com/digisan/event-mgr"
+	u "github.com/digisan/user-mgr/user"
+	"github.com/golang-jwt/jwt/v4"
+	"github.com/google/uuid"
+	"github.com/labstack/echo/v4"
+	"github.com/wismed-web/wisite-api/server/api/admin"
+	"github.com/wismed-web/wisite-api/server/api/file/media"
+	"github.com/wismed-web/wisite-api/server/api/notify"
+)
+
+func TestMain(m *testing.M) {
+	// event & post dbs only for this test run, never in './data'
+	dir, err := os.MkdirTemp("", "post-test")
+	if err != nil {
+		panic(err)
+	}
+	Init(dir)
+	media.InitDB("")
+
+	// no user db in test, admin is 'admin'
+	admin.IsAdmin = func(uname string) (bool, error) {
+		return uname == "admin", nil
+	}
+	// no relation db in test, record notifications only
+	fnNotify = func(uname, typ, actor, target, text string) (*notify.Notification, error) {
+		n := &notify.Notification{Uname: uname, Type: typ, Actor: actor, Target: target, Text: text}
+		notified = append(notified, n)
+		return n, nil
+	}
+
+	code := m.Run()
+	os.RemoveAll(dir)
+	os.Exit(code)
+}
+
+// unique [name] in one test run, so records of earlier runs (e.g. by -count=2) are never met
+func uniq(name string) string {
+	return name + "-" + uuid.NewString()[:8]
+}
+
+// notifications sent in test
+var notified []*notify.Notification
+
+// last notification to [uname], nil if none
+func lastNotified(uname string) *notify.Notification {
+	for i := len(notified) - 1; i >= 0; i-- {
+		if notified[i].Uname == uname {
+			return notified[i]
+		}
+	}
+	return nil
+}
+
+// invoke [handler] as [uname] with [query]
+func invoke(handler echo.HandlerFunc, method, uname, query string) *httptest.ResponseRecorder {
+	e := echo.New()
+	req := httptest.NewRequest(method, "/?"+query, nil)
+	rec := httptest.NewRecorder()
+	c := e.NewContext(req, rec)
+	c.Set("user", &jwt.Token{Claims: &u.UserClaims{Core: u.Core{UName: uname}}})
+	if err := handler(c); err != nil {
+		panic(err)
+	}
+	return rec
+}
+
+func newTestPost(t *testing.T, owner string) string {
+	data, err := json.Marshal(Post{Category: "post", Topic: "test"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	evt := em.NewEvent("", owner, "Post", string(data), "")
+	if err := em.AddEvent(evt); err != nil {
+		t.Fatal(err)
+	}
+	if err := IndexEvent(evt); err != nil {
+		t.Fatal(err)
+	}
+	return evt.ID
+}
+
+func lastAudit(t *testing.T, target string) *Audit {
+	audits, err := AuditTrail(func(a *Audit) bool { return a.Target == target })
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(audits) == 0 {
+		t.Fatalf("no audit record for <%s>", target)
+	}
+	return audits[len(audits)-1]
+}
+
+func TestDelOne(t *testing.T) {
+
+	idOwn := newTestPost(t, "alice")
+	idOther := newTestPost(t, "alice")
+	idAdmin := newTestPost(t, "alice")
+
+	tests := []struct {
+		name   string
+		uname  string
+		id     string
+		status int
+		done   bool
+	}{
+		{"owner deletes own Post", "alice", idOwn, http.StatusOK, true},
+		{"other deletes Post", "bob", idOther, http.StatusForbidden, false},
+		{"admin deletes other's Post", "admin", idAdmin, http.StatusOK, true},
+		{"owner deletes deleted Post", "alice", idOwn, http.StatusNotFound, false},
+		{"owner deletes missing Post", "alice", "missing-id", http.StatusNotFound, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rec := invoke(DelOne, http.MethodDelete, tt.uname, "id="+tt.id)
+			if rec.Code != tt.status {
+				t.Fatalf("status: want %d, got %d (%s)", tt.status, rec.Code, rec.Body.String())
+			}
+			a := lastAudit(t, tt.id)
+			if a.Actor != tt.uname || a.Action != "delete" || a.Done != tt.done || len(a.Reason) == 0 {
+				t.Fatalf("unexpected audit: %v", a)
+			}
+			if alive := em.EventIsAlive(tt.id); tt.status != http.StatusNotFound && alive == tt.done {
+				t.Fatalf("alive status of <%s> is %v after deletion", tt.id, alive)
+			}
+		})
+	}
+
+	if rec := invoke(DelOne, http.MethodDelete, "alice", ""); rec.Code != http.StatusBadRequest {
+		t.Fatalf("empty id status: want %d, got %d", http.StatusBadRequest, rec.Code)
+	}
+}
+
+func TestEraseOne(t *testing.T) {
+
+	idOwn := newTestPost(t, "alice")
+	idAdmin := newTestPost(t, "alice")
+
+	tests := []struct {
+		name   string
+		uname  string
+		id     string
+		reason string
+		status int
+		done   bool
+	}{
+		{"owner erases own Post", "alice", idOwn, "", http.StatusForbidden, false},
+		{"other erases Post", "bob", idOwn, "", http.StatusForbidden, false},
+		{"admin erases Post", "admin", idAdmin, "spam", http.StatusOK, true},
+		{"admin erases erased Post", "admin", idAdmin, "", http.StatusNotFound, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rec := invoke(EraseOne, http.MethodDelete, tt.uname, "id="+tt.id+"&reason="+tt.reason)
+			if rec.Code != tt.status {
+				t.Fatalf("status: want %d, got %d (%s)", tt.status, rec.Code, rec.Body.String())
+			}
+			a := lastAudit(t, tt.id)
+			if a.Actor != tt.uname || a.Action != "erase" || a.Done != tt.done {
+				t.Fatalf("unexpected audit: %v", a)
+			}
+			if tt.done && a.Reason != tt.reason {
+				t.Fatalf("audit reason: want %s, got %s", tt.reason, a.Reason)
+			}
+		})
+	}
+
+	if evt, _ := em.FetchEvent(false, idOwn); evt == nil {
+		t.Fatalf("<%s> should not be erased by non-admin", idOwn)
+	}
+}
+
+func TestAuditList(t *testing.T) {
+	if rec := invoke(AuditList, http.MethodGet, "alice", ""); rec.Code != http.StatusUnauthorized {
+		t.Fatalf("non-admin status: want %d, got %d", http.StatusUnauthorized, rec.Code)
+	}
+	id := newTestPost(t, "alice")
+	invoke(DelOne, http.MethodDelete, "bob", "id="+id)
+	rec := invoke(AuditList, http.MethodGet, "admin", "target="+id)
+	if rec.Code != http.StatusOK {
+		t.Fatalf("admin status: want %d, got %d", http.StatusOK, rec.Code)
+	}
+	audits := []*Audit{}
+	if err := json.Unmarshal(rec.Body.Bytes(), &audits); err != nil {
+		t.Fatal(err)
+	}
+	if len(audits) != 1 || audits[0].Actor != "bob" || audits[0].Done {
+		t.Fatalf("unexpected audits: %v", audits)
+	}
+}
@@ -0,0 +1,62 @@
+package post
+
+import (
+	"fmt"
+	"net/http"
+	"strconv"
+
+	. "github.com/digisan/go-generics/v2"
+	u "github.com/digisan/user-mgr/user"
+	"github.com/golang-jwt/jwt/v4"
+	"github.com/labstack/echo/v4"
+)
+
+// *** after implementing, register with path in 'post.go' *** //
+
+// @Title get a Post reply tree
+// @Summary get the whole nested reply tree of a Post, with Post content inline.
+// @Description
+// @Tags    Post
+// @Accept  json
+// @Produce json
+// @Param   id     path  string  true  "root Post ID"
+// @Param   depth  query int     false "reply levels under root, default is 5, max is 20"
+// @Param   sort   query string  false "sort replies on each level by 'oldest' (default), 'newest' or 'liked' (most reactions of all types)"
+// @Success 200 "OK - get reply tree successfully"
+// @Failure 400 "Fail - incorrect query param"
+// @Failure 404 "Fail - not found"
+// @Failure 500 "Fail - internal error"
+// @Router /api/post/thread/{id} [get]
+// @Security ApiKeyAuth
+func Thread(c echo.Context) error {
+	var (
+		userTkn = c.Get("user").(*jwt.Token)
+		claims  = userTkn.Claims.(*u.UserClaims)
+		uname   = claims.UName
+		id      = c.Param("id")
+		order   = c.QueryParam("sort")
+		opt     = threadOpt{depth: defaultThreadDepth, order: "oldest", uname: uname, base: mediaBase(c)}
+		err     error
+	)
+
+	if len(order) > 0 {
+		if NotIn(order, "oldest", "newest", "liked") {
+			return c.String(http.StatusBadRequest, "'sort' must be one of [oldest, newest, liked]")
+		}
+		opt.order = order
+	}
+	if s := c.QueryParam("depth"); len(s) > 0 {
+		if opt.depth, err = strconv.Atoi(s); err != nil || opt.depth < 0 || opt.depth > maxThreadDepth {
+			return c.String(http.StatusBadRequest, fmt.Sprintf("'depth' must be an integer in [0, %d]", maxThreadDepth))
+		}
+	}
+
+	root, err := buildThread(id, opt)
+	if err != nil {
+		return c.String(http.StatusInternalServerError, err.Error())
+	}
+	if root == nil {
+		return c.String(http.StatusNotFound, fmt.Sprintf("Post not found @%s", id))
+	}
+	return c.JSON(http.StatusOK, root)
+}
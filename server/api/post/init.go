@@ -2,17 +2,47 @@ package post
 
 import (
 	"context"
+	"sync"
 
 	em "github.com/digisan/event-mgr"
+	lk "github.com/digisan/logkit"
 )
 
 var (
-	ctx    context.Context
-	Cancel context.CancelFunc
+	ctx          context.Context
+	Cancel       context.CancelFunc // stop background routines of post, return after they all exit
+	wgBackground sync.WaitGroup
 )
 
 func init() {
-	ctx, Cancel = context.WithCancel(context.Background())
-	em.InitDB("./data")
+	var cancel context.CancelFunc
+	ctx, cancel = context.WithCancel(context.Background())
+	Cancel = func() {
+		cancel()
+		wgBackground.Wait()
+	}
+}
+
+// open event & post dbs in [dir], then load moderation state & search index
+func Init(dir string) {
+	em.InitDB(dir)
 	em.InitEventSpan("MINUTE", ctx)
+	InitDB(dir)
+	lk.FailOnErr("%v", loadHidden())
+	lk.WarnOnErr("%v", RebuildIndex()) // before serving, so listings are complete and not racing with new posts
+}
+
+// start background routines of serving, i.e. draft scheduler & view flusher. stop them by 'Cancel'
+func Start() {
+	wgBackground.Add(1)
+	go func() {
+		defer wgBackground.Done()
+		runScheduler(ctx)
+	}()
+
+	wgBackground.Add(1)
+	go func() {
+		defer wgBackground.Done()
+		runViewFlusher(ctx)
+	}()
 }
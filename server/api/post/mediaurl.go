@@ -0,0 +1,64 @@
+package post
+
+import (
+	"fmt"
+	"net"
+	"net/url"
+	"strings"
+
+	"github.com/labstack/echo/v4"
+)
+
+// public base url for media src, e.g. "https://wismed.example.com" or "http://203.0.113.5:3323".
+// if empty, it is derived from each request, honouring X-Forwarded-* headers set by reverse proxy
+var publicURL = ""
+
+// set public base url for media src from config, empty means deriving from request
+func SetPublicURL(s string) error {
+	s = strings.TrimSpace(s)
+	if len(s) == 0 {
+		publicURL = ""
+		return nil
+	}
+	u, err := url.Parse(s)
+	if err != nil || (u.Scheme != "http" && u.Scheme != "https") || len(u.Host) == 0 {
+		return fmt.Errorf("public url '%s' is invalid, it should be like 'https://host[:port][/prefix]'", s)
+	}
+	publicURL = strings.TrimSuffix(u.String(), "/")
+	return nil
+}
+
+// first value of a comma separated forwarded header, e.g. "X-Forwarded-Host: a.com, proxy.local"
+func forwarded(c echo.Context, hdr string) string {
+	v, _, _ := strings.Cut(c.Request().Header.Get(hdr), ",")
+	return strings.TrimSpace(v)
+}
+
+// base url for media src of this request, without trailing '/'
+func mediaBase(c echo.Context) string {
+	if len(publicURL) > 0 {
+		return publicURL
+	}
+
+	scheme := c.Scheme() // echo already honours X-Forwarded-Proto, X-Forwarded-Ssl etc.
+	host := forwarded(c, "X-Forwarded-Host")
+	if len(host) == 0 {
+		host = c.Request().Host
+	}
+	if port := forwarded(c, "X-Forwarded-Port"); len(port) > 0 {
+		if _, _, err := net.SplitHostPort(host); err != nil && port != map[string]string{"http": "80", "https": "443"}[scheme] {
+			host = net.JoinHostPort(strings.Trim(host, "[]"), port)
+		}
+	}
+	prefix := strings.TrimSuffix(forwarded(c, "X-Forwarded-Prefix"), "/")
+	return scheme + "://" + host + prefix
+}
+
+// full media url of storage [path] like "uname/2022-05/image/a.png" under [base]
+func mediaURL(base, path string) string {
+	src, err := url.JoinPath(base, path)
+	if err != nil {
+		return base + "/" + strings.TrimPrefix(path, "/")
+	}
+	return src
+}
@@ -0,0 +1,79 @@
+package post
+
+import (
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/labstack/echo/v4"
+	clt "github.com/wismed-web/wisite-api/server/api/client"
+	"github.com/wismed-web/wisite-api/server/api/file/media"
+)
+
+func TestMediaBase(t *testing.T) {
+	defer SetPublicURL("")
+
+	tests := []struct {
+		public string
+		host   string
+		hdrs   map[string]string
+		want   string
+	}{
+		{"", "127.0.0.1:3323", nil, "http://127.0.0.1:3323"},
+		{"", "127.0.0.1:3323", map[string]string{"X-Forwarded-Proto": "https", "X-Forwarded-Host": "wismed.example.com, proxy.local"}, "https://wismed.example.com"},
+		{"", "127.0.0.1:3323", map[string]string{"X-Forwarded-Proto": "https", "X-Forwarded-Host": "wismed.example.com", "X-Forwarded-Port": "8443"}, "https://wismed.example.com:8443"},
+		{"", "127.0.0.1:3323", map[string]string{"X-Forwarded-Proto": "https", "X-Forwarded-Host": "wismed.example.com", "X-Forwarded-Port": "443"}, "https://wismed.example.com"},
+		{"", "127.0.0.1:3323", map[string]string{"X-Forwarded-Host": "wismed.example.com", "X-Forwarded-Prefix": "/api-server/"}, "http://wismed.example.com/api-server"},
+		{"https://cdn.example.com/media/", "127.0.0.1:3323", map[string]string{"X-Forwarded-Host": "wismed.example.com"}, "https://cdn.example.com/media"},
+	}
+	for _, tt := range tests {
+		if err := SetPublicURL(tt.public); err != nil {
+			t.Fatal(err)
+		}
+		req := httptest.NewRequest("GET", "/api/post/one", nil)
+		req.Host = tt.host
+		for k, v := range tt.hdrs {
+			req.Header.Set(k, v)
+		}
+		c := echo.New().NewContext(req, httptest.NewRecorder())
+		if got := mediaBase(c); got != tt.want {
+			t.Errorf("%+v: want %s, got %s", tt, tt.want, got)
+		}
+	}
+
+	for _, bad := range []string{"ftp://a.com", "a.com", "https://"} {
+		if err := SetPublicURL(bad); err == nil {
+			t.Errorf("'%s' should be invalid public url", bad)
+		}
+	}
+}
+
+func TestGenVFX(t *testing.T) {
+	clt.AddLayout("v-viewer", &clt.Layout{})
+
+	P := &Post{Content: []Paragraph{
+		{Text: "<b>hi</b>", Atch: Attachment{Path: "alice/2023-05/image/a b.png", Type: "image", MIME: "image/png", Size: "800,600", Variants: []media.Variant{
+			{Path: "alice/2023-05/image/variants/a b-w320.png", Width: 320, Height: 240},
+			{Path: "alice/2023-05/image/variants/a b-w640.png", Width: 640, Height: 480},
+		}}},
+		{Atch: Attachment{Path: "alice/2023-05/video/a.webm", Type: "video", MIME: "video/webm", Poster: "alice/2023-05/video/variants/a-poster.jpg"}},
+		{Atch: Attachment{Path: "alice/2023-05/audio/a.mp3", Type: "audio", MIME: "audio/mpeg"}},
+	}}
+	P.GenVFX("v-viewer", "https://wismed.example.com")
+
+	if ele := P.Content[0].Ele; ele.HP != "<p>&lt;b&gt;hi&lt;/b&gt;</p>" || !strings.Contains(ele.Image, `src="https://wismed.example.com/alice/2023-05/image/a%20b.png"`) {
+		t.Fatalf("unexpected image element: %+v", ele)
+	}
+	if ele := P.Content[0].Ele; !strings.Contains(ele.Image, `srcset="https://wismed.example.com/alice/2023-05/image/variants/a%20b-w320.png 320w, https://wismed.example.com/alice/2023-05/image/variants/a%20b-w640.png 640w, https://wismed.example.com/alice/2023-05/image/a%20b.png 800w"`) {
+		t.Fatalf("unexpected image srcset: %+v", ele)
+	}
+	if ele := P.Content[1].Ele; !strings.Contains(ele.Video, `poster="https://wismed.example.com/alice/2023-05/video/variants/a-poster.jpg"`) {
+		t.Fatalf("unexpected video poster: %+v", ele)
+	}
+	if ele := P.Content[1].Ele; !strings.Contains(ele.Video, `<source src="https://wismed.example.com/alice/2023-05/video/a.webm" type="video/webm">`) {
+		t.Fatalf("unexpected video element: %+v", ele)
+	}
+	if ele := P.Content[2].Ele; !strings.Contains(ele.Audio, `<source src="https://wismed.example.com/alice/2023-05/audio/a.mp3" type="audio/mpeg">`) {
+		t.Fatalf("unexpected audio element: %+v", ele)
+	}
+}
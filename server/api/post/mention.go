@@ -0,0 +1,153 @@
+package post
+
+import (
+	"encoding/json"
+	"fmt"
+	"regexp"
+	"strings"
+	"time"
+
+	"github.com/dgraph-io/badger/v3"
+	bh "github.com/digisan/db-helper/badger"
+	em "github.com/digisan/event-mgr"
+	. "github.com/digisan/go-generics/v2"
+	lk "github.com/digisan/logkit"
+	r "github.com/digisan/user-mgr/relation"
+	u "github.com/digisan/user-mgr/user"
+	"github.com/wismed-web/wisite-api/server/api/notify"
+)
+
+// @uname mentions in Post text. mentioned users are validated when Post is published or edited,
+// kept in Post [Mentions], and each mentioned user gets a mention record & a notification
+
+const maxMentions = 20 // per Post, extra ones are ignored
+
+var (
+	// '@' not following a word char, so email address is not a mention
+	rMention = regexp.MustCompile(`(^|[^0-9A-Za-z_@.])@([0-9A-Za-z_][0-9A-Za-z_.-]*)`)
+
+	// active user check, variable for replacing in test
+	fnUserExists = func(uname string) bool {
+		return u.UserExists(uname, "", true)
+	}
+)
+
+// key: mentioned uname ^ post id;
+// value: json of one mention
+type Mention struct {
+	Uname  string    `json:"uname"` // mentioned user
+	PostID string    `json:"postId"`
+	Author string    `json:"author"`
+	Tm     time.Time `json:"tm"`
+}
+
+func (m Mention) String() string {
+	return fmt.Sprintf("%s mentioned by %s in %s @%v", m.Uname, m.Author, m.PostID, m.Tm)
+}
+
+func (m *Mention) BadgerDB() *badger.DB {
+	return DbGrp.Mention
+}
+
+func (m *Mention) Key() []byte {
+	return []byte(m.Uname + SEP + m.PostID)
+}
+
+func (m *Mention) Marshal(at any) (forKey, forValue []byte) {
+	forKey = m.Key()
+	forValue, err := json.Marshal(m)
+	lk.FailOnErr("%v", err)
+	return
+}
+
+func (m *Mention) Unmarshal(dbKey, dbVal []byte) (any, error) {
+	if err := json.Unmarshal(dbVal, m); err != nil {
+		return nil, err
+	}
+	return m, nil
+}
+
+// candidate unames after '@' in [text], in order & without duplicates
+func parseMentions(text string) []string {
+	names := []string{}
+	for _, match := range rMention.FindAllStringSubmatch(text, -1) {
+		if name := strings.TrimRight(match[2], ".-"); len(name) > 0 && NotIn(name, names...) {
+			names = append(names, name)
+		}
+	}
+	return names
+}
+
+// set [Mentions] from Text & RichText of [author]'s Post. only existing active users are kept,
+// self, users blocked by [author] and users blocking [author] are dropped
+func (p *Post) mention(author string) error {
+	names := []string{}
+	for _, para := range p.Content {
+		for _, name := range append(parseMentions(para.Text), parseMentions(para.RichText)...) {
+			if NotIn(name, names...) {
+				names = append(names, name)
+			}
+		}
+	}
+	p.Mentions = []string{}
+	if len(names) == 0 {
+		return nil
+	}
+
+	blocked, err := fnListRel(author, r.BLOCKED)
+	if err != nil {
+		return err
+	}
+	for _, name := range names {
+		if len(p.Mentions) == maxMentions {
+			break
+		}
+		if name == author || In(name, blocked...) || !fnUserExists(name) {
+			continue
+		}
+		blocking, err := fnListRel(name, r.BLOCKED)
+		if err != nil {
+			return err
+		}
+		if NotIn(author, blocking...) {
+			p.Mentions = append(p.Mentions, name)
+		}
+	}
+	return nil
+}
+
+// save mention records of Post [event] for [cur] mentions, and remove those only in [prev] ones.
+// newly mentioned users are notified
+func saveMentions(event *em.Event, prev, cur []string) error {
+	for _, name := range prev {
+		if NotIn(name, cur...) {
+			if _, err := bh.DeleteOneObject[Mention]((&Mention{Uname: name, PostID: event.ID}).Key()); err != nil {
+				return err
+			}
+		}
+	}
+	for _, name := range cur {
+		m := &Mention{Uname: name, PostID: event.ID, Author: event.Owner, Tm: time.Now()}
+		existing, err := bh.GetOneObject[Mention](m.Key())
+		if err != nil {
+			return err
+		}
+		if existing != nil {
+			continue
+		}
+		if err := bh.UpsertOneObject(m); err != nil {
+			return err
+		}
+		notifyUser(name, notify.Mention, event.Owner, event.ID, "")
+	}
+	return nil
+}
+
+// mentions of [uname], without those from users [uname] blocks now
+func Mentions(uname string) ([]*Mention, error) {
+	blocked, err := fnListRel(uname, r.BLOCKED)
+	if err != nil {
+		return nil, err
+	}
+	return bh.GetObjects[Mention]([]byte(uname+SEP), func(m *Mention) bool { return NotIn(m.Author, blocked...) })
+}
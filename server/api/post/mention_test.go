@@ -0,0 +1,100 @@
+package post
+
+import (
+	"encoding/json"
+	"net/http"
+	"reflect"
+	"testing"
+
+	em "github.com/digisan/event-mgr"
+	r "github.com/digisan/user-mgr/relation"
+)
+
+func TestParseMentions(t *testing.T) {
+	tests := []struct {
+		text string
+		want []string
+	}{
+		{"hi @bob and @carol.", []string{"bob", "carol"}},
+		{"<p>@bob</p> @bob again", []string{"bob"}},
+		{"mail me at alice@example.com", []string{}},
+		{"@@bob, @ alone, @a_b-c", []string{"a_b-c"}},
+	}
+	for _, tt := range tests {
+		if got := parseMentions(tt.text); !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("parseMentions(%q) = %v, want %v", tt.text, got, tt.want)
+		}
+	}
+}
+
+func TestMention(t *testing.T) {
+	var (
+		alice = uniq("mt-alice")
+		bob   = uniq("mt-bob")
+		carol = uniq("mt-carol")
+		dave  = uniq("mt-dave")
+		erin  = uniq("mt-erin")
+		ghost = uniq("mt-ghost")
+	)
+	fnUserExists = func(uname string) bool {
+		return uname != ghost
+	}
+	fnListRel = func(uname string, flag int) ([]string, error) {
+		switch {
+		case uname == alice && flag == r.BLOCKED:
+			return []string{dave}, nil
+		case uname == erin && flag == r.BLOCKED:
+			return []string{alice}, nil
+		}
+		return []string{}, nil
+	}
+
+	P := &Post{Content: []Paragraph{
+		{Text: "@" + bob + " @" + ghost + " @" + alice},
+		{RichText: "<b>@" + carol + "</b> @" + dave + " @" + erin + " @" + bob},
+	}}
+	evt, err := publish(alice, "", P)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []string{bob, carol}
+	if !reflect.DeepEqual(P.Mentions, want) {
+		t.Fatalf("want mentions %v, got %v", want, P.Mentions)
+	}
+	stored := &Post{}
+	if err := json.Unmarshal([]byte(evt.RawJSON), stored); err != nil || !reflect.DeepEqual(stored.Mentions, want) {
+		t.Fatalf("mentions should be stored with event, got %v", stored.Mentions)
+	}
+
+	if n := lastNotified(carol); n == nil || n.Type != "mention" || n.Target != evt.ID {
+		t.Fatalf("carol should be notified, got %v", n)
+	}
+
+	rec := invoke(MentionList, http.MethodGet, bob, "compat=true")
+	ids := []string{}
+	if err := json.Unmarshal(rec.Body.Bytes(), &ids); err != nil || !reflect.DeepEqual(ids, []string{evt.ID}) {
+		t.Fatalf("unexpected mention list: %s", rec.Body.String())
+	}
+
+	// editing drops carol, adds erin who blocks author so is ignored
+	edited := &Post{Content: []Paragraph{{Text: "@" + bob + " @" + erin}}}
+	if _, err := editPost(evt.ID, alice, edited); err != nil {
+		t.Fatal(err)
+	}
+	if ms, err := Mentions(carol); err != nil || len(ms) != 0 {
+		t.Fatalf("carol should not be mentioned after editing, got %v %v", ms, err)
+	}
+	if ms, err := Mentions(bob); err != nil || len(ms) != 1 || ms[0].Author != alice {
+		t.Fatalf("bob should still be mentioned, got %v %v", ms, err)
+	}
+
+	// deleted Post leaves listing
+	if _, err := em.DelEvent(evt.ID); err != nil {
+		t.Fatal(err)
+	}
+	rec = invoke(MentionList, http.MethodGet, bob, "")
+	page := &IdPage{}
+	if err := json.Unmarshal(rec.Body.Bytes(), page); err != nil || len(page.IDs) != 0 {
+		t.Fatalf("deleted Post should not be listed: %s", rec.Body.String())
+	}
+}
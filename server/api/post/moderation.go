@@ -0,0 +1,356 @@
+package post
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/dgraph-io/badger/v3"
+	bh "github.com/digisan/db-helper/badger"
+	em "github.com/digisan/event-mgr"
+	. "github.com/digisan/go-generics/v2"
+	lk "github.com/digisan/logkit"
+	u "github.com/digisan/user-mgr/user"
+	"github.com/wismed-web/wisite-api/server/api/admin"
+	"github.com/wismed-web/wisite-api/server/api/notify"
+)
+
+// user reports on Post & admin moderation. hidden Post is excluded from every listing,
+// search & taxonomy index, and only its owner or admin can still fetch it
+
+var (
+	reportReasons = []string{"spam", "abuse", "misinformation", "illegal", "other"}
+
+	// deactivate user account. variable for replacing in test
+	fnActivateUser = func(uname string, flag bool) error {
+		_, _, err := u.ActivateUser(uname, flag)
+		return err
+	}
+
+	errReportReason = fmt.Errorf("reason must be one of %v", reportReasons)
+	errReportSelf   = errors.New("cannot report own Post")
+
+	mtxHidden = &sync.RWMutex{}
+	mHidden   = map[string]struct{}{} // cache of hidden Post ids, loaded at init
+)
+
+// key: post id ^ reporter;
+// value: json of one user report on a Post. reporting again replaces earlier one
+type Report struct {
+	PostID   string    `json:"postId"`
+	Reporter string    `json:"reporter"`
+	Reason   string    `json:"reason"` // one of [reportReasons]
+	Note     string    `json:"note"`
+	Tm       time.Time `json:"tm"`
+}
+
+func (r Report) String() string {
+	return fmt.Sprintf("%s reported by %s for %s @%v: %s", r.PostID, r.Reporter, r.Reason, r.Tm, r.Note)
+}
+
+func (r *Report) BadgerDB() *badger.DB {
+	return DbGrp.Report
+}
+
+func (r *Report) Key() []byte {
+	return []byte(r.PostID + SEP + r.Reporter)
+}
+
+func (r *Report) Marshal(at any) (forKey, forValue []byte) {
+	forKey = r.Key()
+	forValue, err := json.Marshal(r)
+	lk.FailOnErr("%v", err)
+	return
+}
+
+func (r *Report) Unmarshal(dbKey, dbVal []byte) (any, error) {
+	if err := json.Unmarshal(dbVal, r); err != nil {
+		return nil, err
+	}
+	return r, nil
+}
+
+// key: post id;
+// value: json of admin moderation result on a Post
+type Moderation struct {
+	PostID   string    `json:"postId"`
+	Owner    string    `json:"owner"`
+	Status   string    `json:"status"` // "hidden", "restored" or "removed"
+	Actor    string    `json:"actor"`
+	Note     string    `json:"note"`
+	Tm       time.Time `json:"tm"`
+	Reviewed time.Time `json:"reviewed"` // reports before this are handled
+}
+
+func (m Moderation) String() string {
+	return fmt.Sprintf("%s(%s) %s by %s @%v: %s", m.PostID, m.Owner, m.Status, m.Actor, m.Tm, m.Note)
+}
+
+func (m *Moderation) BadgerDB() *badger.DB {
+	return DbGrp.Moderation
+}
+
+func (m *Moderation) Key() []byte {
+	return []byte(m.PostID)
+}
+
+func (m *Moderation) Marshal(at any) (forKey, forValue []byte) {
+	forKey = m.Key()
+	forValue, err := json.Marshal(m)
+	lk.FailOnErr("%v", err)
+	return
+}
+
+func (m *Moderation) Unmarshal(dbKey, dbVal []byte) (any, error) {
+	if err := json.Unmarshal(dbVal, m); err != nil {
+		return nil, err
+	}
+	return m, nil
+}
+
+// key: uname ^ time(unix nano);
+// value: json of one admin warning to a Post author
+type Warning struct {
+	Uname  string    `json:"uname"`
+	PostID string    `json:"postId"`
+	Actor  string    `json:"actor"`
+	Note   string    `json:"note"`
+	Tm     time.Time `json:"tm"`
+}
+
+func (w Warning) String() string {
+	return fmt.Sprintf("%s warned by %s on %s @%v: %s", w.Uname, w.Actor, w.PostID, w.Tm, w.Note)
+}
+
+func (w *Warning) BadgerDB() *badger.DB {
+	return DbGrp.Warning
+}
+
+func (w *Warning) Key() []byte {
+	return []byte(fmt.Sprintf("%s%s%019d", w.Uname, SEP, w.Tm.UnixNano()))
+}
+
+func (w *Warning) Marshal(at any) (forKey, forValue []byte) {
+	forKey = w.Key()
+	forValue, err := json.Marshal(w)
+	lk.FailOnErr("%v", err)
+	return
+}
+
+func (w *Warning) Unmarshal(dbKey, dbVal []byte) (any, error) {
+	if err := json.Unmarshal(dbVal, w); err != nil {
+		return nil, err
+	}
+	return w, nil
+}
+
+// load hidden Post ids into cache
+func loadHidden() error {
+	mods, err := bh.GetObjects[Moderation](nil, func(m *Moderation) bool { return m.Status == "hidden" })
+	if err != nil {
+		return err
+	}
+	mtxHidden.Lock()
+	defer mtxHidden.Unlock()
+	for _, m := range mods {
+		mHidden[m.PostID] = struct{}{}
+	}
+	return nil
+}
+
+func isHidden(id string) bool {
+	mtxHidden.RLock()
+	defer mtxHidden.RUnlock()
+	_, ok := mHidden[id]
+	return ok
+}
+
+func setHidden(id string, flag bool) {
+	mtxHidden.Lock()
+	defer mtxHidden.Unlock()
+	if flag {
+		mHidden[id] = struct{}{}
+	} else {
+		delete(mHidden, id)
+	}
+}
+
+// [ids] without hidden ones
+func visibleIDs(ids []string) []string {
+	return Filter(ids, func(i int, id string) bool { return !isHidden(id) })
+}
+
+// hidden Post of [owner] is invisible to [uname], unless [uname] is owner or admin
+func hiddenFor(id, owner, uname string) (bool, error) {
+	if !isHidden(id) || owner == uname {
+		return false, nil
+	}
+	isAdmin, err := admin.IsAdmin(uname)
+	return !isAdmin, err
+}
+
+// [reporter] reports alive Post [event] for [reason]
+func report(event *em.Event, reporter, reason, note string) error {
+	reason = strings.ToLower(reason)
+	if NotIn(reason, reportReasons...) {
+		return errReportReason
+	}
+	if event.Owner == reporter {
+		return errReportSelf
+	}
+	return bh.UpsertOneObject(&Report{
+		PostID:   event.ID,
+		Reporter: reporter,
+		Reason:   reason,
+		Note:     note,
+		Tm:       time.Now(),
+	})
+}
+
+func Reports(id string) ([]*Report, error) {
+	return bh.GetObjects[Report]([]byte(id+SEP), nil)
+}
+
+// nil if not moderated yet
+func FetchModeration(id string) (*Moderation, error) {
+	return bh.GetOneObject[Moderation]([]byte(id))
+}
+
+// one reported Post in moderation queue
+type QueueItem struct {
+	PostID   string         `json:"postId"`
+	Owner    string         `json:"owner"`
+	Status   string         `json:"status"`  // "open" if not moderated after latest report, otherwise moderation status
+	Count    int            `json:"count"`   // reports after last review
+	Total    int            `json:"total"`   // all reports
+	Reasons  map[string]int `json:"reasons"` // reports after last review by reason
+	LastTm   time.Time      `json:"lastTm"`  // latest report time
+	Reviewed time.Time      `json:"reviewed"`
+}
+
+// reported Posts, most reported open ones first. [status] can be "open", "hidden", "restored", "removed" or empty for all
+func moderationQueue(status string) ([]*QueueItem, error) {
+	reports, err := bh.GetObjects[Report](nil, nil)
+	if err != nil {
+		return nil, err
+	}
+	mItem := map[string]*QueueItem{}
+	mMod := map[string]*Moderation{}
+	items := []*QueueItem{}
+	for _, r := range reports {
+		item, ok := mItem[r.PostID]
+		if !ok {
+			mod, err := FetchModeration(r.PostID)
+			if err != nil {
+				return nil, err
+			}
+			item = &QueueItem{PostID: r.PostID, Status: "open", Reasons: map[string]int{}}
+			if mod != nil {
+				item.Owner, item.Reviewed = mod.Owner, mod.Reviewed
+			}
+			mItem[r.PostID], mMod[r.PostID] = item, mod
+			items = append(items, item)
+		}
+		item.Total++
+		if r.Tm.After(item.Reviewed) {
+			item.Count++
+			item.Reasons[r.Reason]++
+		}
+		if r.Tm.After(item.LastTm) {
+			item.LastTm = r.Tm
+		}
+	}
+
+	for _, item := range items {
+		if mod := mMod[item.PostID]; mod != nil && item.Count == 0 {
+			item.Status = mod.Status
+		}
+		if len(item.Owner) == 0 {
+			if event, err := em.FetchEvent(false, item.PostID); err == nil && event != nil {
+				item.Owner = event.Owner
+			}
+		}
+	}
+
+	items = Filter(items, func(i int, item *QueueItem) bool { return len(status) == 0 || item.Status == status })
+	sort.SliceStable(items, func(i, j int) bool {
+		if items[i].Count != items[j].Count {
+			return items[i].Count > items[j].Count
+		}
+		return items[i].LastTm.After(items[j].LastTm)
+	})
+	return items, nil
+}
+
+// record moderation [status] of Post [event] by admin [actor], pending reports are reviewed
+func moderate(event *em.Event, actor, status, note string) error {
+	now := time.Now()
+	mod := &Moderation{
+		PostID:   event.ID,
+		Owner:    event.Owner,
+		Status:   status,
+		Actor:    actor,
+		Note:     note,
+		Tm:       now,
+		Reviewed: now,
+	}
+	if err := bh.UpsertOneObject(mod); err != nil {
+		return err
+	}
+	lk.WarnOnErr("%v", addAudit(actor, status, event.ID, event.Owner, true, note))
+	notifyUser(event.Owner, notify.Admin, actor, event.ID, fmt.Sprintf("Post %s: %s", status, note))
+
+	switch status {
+	case "hidden":
+		setHidden(event.ID, true)
+		UnindexEvent(event.ID)
+	case "restored":
+		setHidden(event.ID, false)
+		if !event.Deleted {
+			return IndexEvent(event)
+		}
+	case "removed":
+		setHidden(event.ID, false)
+		UnindexEvent(event.ID)
+		if _, err := em.DelEvent(event.ID); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// admin [actor] warns [event] owner, who can list warnings & gets a notification
+func warn(event *em.Event, actor, note string) (*Warning, error) {
+	w := &Warning{
+		Uname:  event.Owner,
+		PostID: event.ID,
+		Actor:  actor,
+		Note:   note,
+		Tm:     time.Now(),
+	}
+	if err := bh.UpsertOneObject(w); err != nil {
+		return nil, err
+	}
+	lk.WarnOnErr("%v", addAudit(actor, "warn", event.ID, event.Owner, true, note))
+	notifyUser(event.Owner, notify.Admin, actor, event.ID, "warning: "+note)
+	return w, nil
+}
+
+// warnings to [uname], ordered by time
+func Warnings(uname string) ([]*Warning, error) {
+	return bh.GetObjects[Warning]([]byte(uname+SEP), nil)
+}
+
+// admin [actor] deactivates [event] owner account
+func deactivate(event *em.Event, actor, note string) error {
+	if err := fnActivateUser(event.Owner, false); err != nil {
+		lk.WarnOnErr("%v", addAudit(actor, "deactivate", event.ID, event.Owner, false, err.Error()))
+		return err
+	}
+	notifyUser(event.Owner, notify.Admin, actor, event.ID, "account deactivated: "+note)
+	return addAudit(actor, "deactivate", event.ID, event.Owner, true, note)
+}
@@ -0,0 +1,175 @@
+package post
+
+import (
+	"encoding/json"
+	"net/http"
+	"testing"
+
+	em "github.com/digisan/event-mgr"
+	. "github.com/digisan/go-generics/v2"
+	"github.com/labstack/echo/v4"
+	clt "github.com/wismed-web/wisite-api/server/api/client"
+)
+
+// invoke [handler] with path param id
+func invokeID(handler echo.HandlerFunc, method, uname, id, query string) int {
+	return invoke(func(c echo.Context) error {
+		c.SetParamNames("id")
+		c.SetParamValues(id)
+		return handler(c)
+	}, method, uname, query).Code
+}
+
+func queueItem(t *testing.T, status, id string) *QueueItem {
+	items, err := moderationQueue(status)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, item := range items {
+		if item.PostID == id {
+			return item
+		}
+	}
+	return nil
+}
+
+func TestReportPost(t *testing.T) {
+	var (
+		alice = uniq("md-alice")
+		bob   = uniq("md-bob")
+		carol = uniq("md-carol")
+	)
+	id := newTestPost(t, alice)
+
+	tests := []struct {
+		name   string
+		uname  string
+		id     string
+		query  string
+		status int
+	}{
+		{"report with reason", bob, id, "reason=spam", http.StatusOK},
+		{"report again replaces", bob, id, "reason=Abuse&note=rude", http.StatusOK},
+		{"another reporter", carol, id, "reason=abuse", http.StatusOK},
+		{"unknown reason", carol, id, "reason=boring", http.StatusBadRequest},
+		{"own Post", alice, id, "reason=spam", http.StatusBadRequest},
+		{"missing Post", bob, "missing-id", "reason=spam", http.StatusNotFound},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if status := invokeID(ReportPost, http.MethodPost, tt.uname, tt.id, tt.query); status != tt.status {
+				t.Fatalf("want %d, got %d", tt.status, status)
+			}
+		})
+	}
+
+	item := queueItem(t, "open", id)
+	if item == nil || item.Owner != alice || item.Count != 2 || item.Reasons["abuse"] != 2 {
+		t.Fatalf("unexpected queue item: %+v", item)
+	}
+	if status := invoke(ModerationQueue, http.MethodGet, bob, "").Code; status != http.StatusUnauthorized {
+		t.Fatalf("non-admin should not list queue, got %d", status)
+	}
+}
+
+func TestModeration(t *testing.T) {
+	var (
+		dave = uniq("md-dave")
+		erin = uniq("md-erin")
+	)
+	for _, uname := range []string{dave, "admin"} {
+		clt.AddLayout(uname, &clt.Layout{})
+	}
+	id := newTestPost(t, dave)
+	other := newTestPost(t, dave)
+	for _, pid := range []string{id, other} {
+		event, err := em.FetchEvent(true, pid)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if err := IndexEvent(event); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if status := invokeID(ReportPost, http.MethodPost, erin, id, "reason=misinformation"); status != http.StatusOK {
+		t.Fatalf("report failed: %d", status)
+	}
+
+	if status := invokeID(HidePost, http.MethodPut, erin, id, ""); status != http.StatusUnauthorized {
+		t.Fatalf("non-admin should not hide, got %d", status)
+	}
+	if status := invokeID(HidePost, http.MethodPut, "admin", id, "note=fake"); status != http.StatusOK {
+		t.Fatalf("hide failed: %d", status)
+	}
+	if a := lastAudit(t, id); a.Action != "hidden" || a.Actor != "admin" {
+		t.Fatalf("unexpected audit: %v", a)
+	}
+	if item := queueItem(t, "hidden", id); item == nil || item.Count != 0 || item.Total != 1 {
+		t.Fatalf("hidden Post should be reviewed in queue, got %+v", item)
+	}
+
+	// hidden Post disappears from listing & search, except for owner & admin
+	if ids := visibleIDs([]string{id, other}); !(len(ids) == 1 && ids[0] == other) {
+		t.Fatalf("hidden Post should be filtered out, got %v", ids)
+	}
+	if hits := Search("test", SearchOpt{}); In(id, Map(hits, func(i int, h Hit) string { return h.ID })...) {
+		t.Fatal("hidden Post should not be searchable")
+	}
+	for uname, status := range map[string]int{erin: http.StatusNotFound, dave: http.StatusOK, "admin": http.StatusOK} {
+		if rec := invoke(GetOne, http.MethodGet, uname, "id="+id); rec.Code != status {
+			t.Fatalf("%s gets hidden Post: want %d, got %d", uname, status, rec.Code)
+		}
+	}
+	if item := fetchOne(id, erin, ""); item.Status != "hidden" || item.Post != nil {
+		t.Fatalf("batch item of hidden Post: %+v", item)
+	}
+	rec := invoke(IdAll, http.MethodGet, erin, "compat=true")
+	ids := []string{}
+	if err := json.Unmarshal(rec.Body.Bytes(), &ids); err != nil || In(id, ids...) || NotIn(other, ids...) {
+		t.Fatalf("unexpected listing: %s", rec.Body.String())
+	}
+
+	// restore
+	if status := invokeID(RestorePost, http.MethodPut, "admin", id, ""); status != http.StatusOK {
+		t.Fatalf("restore failed: %d", status)
+	}
+	if isHidden(id) || len(Search("test", SearchOpt{})) == 0 {
+		t.Fatal("restored Post should be visible & searchable")
+	}
+
+	// warn & deactivate author
+	if status := invokeID(WarnAuthor, http.MethodPut, "admin", id, ""); status != http.StatusBadRequest {
+		t.Fatalf("warning without note: %d", status)
+	}
+	if status := invokeID(WarnAuthor, http.MethodPut, "admin", id, "note=check+facts"); status != http.StatusOK {
+		t.Fatalf("warn failed: %d", status)
+	}
+	if n := lastNotified(dave); n == nil || n.Type != "admin" || n.Text != "warning: check facts" {
+		t.Fatalf("author should be notified of warning, got %v", n)
+	}
+	if ws, err := Warnings(dave); err != nil || len(ws) != 1 || ws[0].Note != "check facts" {
+		t.Fatalf("unexpected warnings: %v %v", ws, err)
+	}
+
+	deactivated := ""
+	fnActivateUser = func(uname string, flag bool) error {
+		if !flag {
+			deactivated = uname
+		}
+		return nil
+	}
+	if status := invokeID(DeactivateAuthor, http.MethodPut, "admin", id, ""); status != http.StatusOK || deactivated != dave {
+		t.Fatalf("deactivate failed: %d, %s", status, deactivated)
+	}
+
+	// remove
+	if status := invokeID(RemovePost, http.MethodDelete, "admin", id, ""); status != http.StatusOK {
+		t.Fatalf("remove failed: %d", status)
+	}
+	if event, err := em.FetchEvent(true, id); err != nil || event != nil {
+		t.Fatalf("removed Post should be deleted: %v %v", event, err)
+	}
+	if status := invokeID(RemovePost, http.MethodDelete, "admin", id, ""); status != http.StatusNotFound {
+		t.Fatalf("remove again: %d", status)
+	}
+}
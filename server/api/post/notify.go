@@ -0,0 +1,15 @@
+package post
+
+import (
+	lk "github.com/digisan/logkit"
+	"github.com/wismed-web/wisite-api/server/api/notify"
+)
+
+// record & push notification, variable for replacing in test
+var fnNotify = notify.Push
+
+// notify [uname] of [typ] caused by [actor], failure is only logged
+func notifyUser(uname, typ, actor, target, text string) {
+	_, err := fnNotify(uname, typ, actor, target, text)
+	lk.WarnOnErr("%v", err)
+}
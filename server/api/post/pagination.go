@@ -0,0 +1,208 @@
+package post
+
+import (
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	em "github.com/digisan/event-mgr"
+	. "github.com/digisan/go-generics/v2"
+	"github.com/labstack/echo/v4"
+)
+
+const (
+	defaultPageSize = 20
+	maxPageSize     = 100
+)
+
+var errCursor = errors.New("invalid cursor")
+
+// position of one event in listing. order is score descending (0 for chronological listing),
+// then newest first, then ID descending when time is equal
+type cursor struct {
+	score float64
+	tm    time.Time
+	id    string
+}
+
+// true if [c] is listed before [other]
+func (c cursor) ahead(other cursor) bool {
+	switch {
+	case c.score != other.score:
+		return c.score > other.score
+	case !c.tm.Equal(other.tm):
+		return c.tm.After(other.tm)
+	default:
+		return c.id > other.id
+	}
+}
+
+func (c cursor) encode() string {
+	raw := strconv.FormatFloat(c.score, 'g', -1, 64) + SEP + strconv.FormatInt(c.tm.UnixNano(), 10) + SEP + c.id
+	return base64.RawURLEncoding.EncodeToString([]byte(raw))
+}
+
+func decodeCursor(s string) (cursor, error) {
+	data, err := base64.RawURLEncoding.DecodeString(s)
+	if err != nil {
+		return cursor{}, errCursor
+	}
+	ss := strings.SplitN(string(data), SEP, 3)
+	if len(ss) != 3 || len(ss[2]) == 0 {
+		return cursor{}, errCursor
+	}
+	score, err := strconv.ParseFloat(ss[0], 64)
+	if err != nil {
+		return cursor{}, errCursor
+	}
+	nano, err := strconv.ParseInt(ss[1], 10, 64)
+	if err != nil {
+		return cursor{}, errCursor
+	}
+	return cursor{score, time.Unix(0, nano), ss[2]}, nil
+}
+
+// one page of Post ids. use 'next_cursor' as 'before' for older page, 'prev_cursor' as 'after' for newer page.
+// empty cursor means no more page in that direction
+type IdPage struct {
+	IDs        []string `json:"ids"`
+	NextCursor string   `json:"next_cursor"`
+	PrevCursor string   `json:"prev_cursor"`
+}
+
+// chronological cursors of listed Post & share events of [ids], from in-memory listing without fetching events
+func listedCursors(ids []string) []cursor {
+	idx.RLock()
+	defer idx.RUnlock()
+	all := make([]cursor, 0, len(ids))
+	for _, id := range Settify(ids...) {
+		if l, ok := idx.mListed[id]; ok {
+			all = append(all, cursor{0, l.tm, id})
+		}
+	}
+	return all
+}
+
+// page alive Post & share events of [ids] chronologically by [limit], only those listed after [before] or listed ahead of [after] (at most one of them)
+func paginate(ids []string, limit int, before, after string) (*IdPage, error) {
+	return pageAlive(func() ([]cursor, error) { return listedCursors(ids), nil }, limit, before, after)
+}
+
+// page listed cursors from [fn] like 'pageCursors', but only events in page window are fetched.
+// if some of them are gone but still listed, they are unlisted and the page is made again
+func pageAlive(fn func() ([]cursor, error), limit int, before, after string) (*IdPage, error) {
+	for {
+		all, err := fn()
+		if err != nil {
+			return nil, err
+		}
+		page, err := pageCursors(all, limit, before, after)
+		if err != nil {
+			return nil, err
+		}
+		evts, err := em.FetchEvents(true, page.IDs...)
+		if err != nil {
+			return nil, err
+		}
+		if len(evts) == len(page.IDs) {
+			return page, nil
+		}
+		alive := FilterMap(evts, nil, func(i int, evt *em.Event) string { return evt.ID })
+		UnindexEvent(Filter(page.IDs, func(i int, id string) bool { return NotIn(id, alive...) })...)
+	}
+}
+
+// page [all] cursors by [limit], only those listed after [before] or listed ahead of [after] (at most one of them)
+func pageCursors(all []cursor, limit int, before, after string) (*IdPage, error) {
+	sort.Slice(all, func(i, j int) bool { return all[i].ahead(all[j]) })
+
+	start, end := 0, len(all)
+	switch {
+	case len(before) > 0:
+		cur, err := decodeCursor(before)
+		if err != nil {
+			return nil, err
+		}
+		start = sort.Search(len(all), func(i int) bool { return cur.ahead(all[i]) })
+		if end = start + limit; end > len(all) {
+			end = len(all)
+		}
+	case len(after) > 0:
+		cur, err := decodeCursor(after)
+		if err != nil {
+			return nil, err
+		}
+		end = sort.Search(len(all), func(i int) bool { return !all[i].ahead(cur) })
+		if start = end - limit; start < 0 {
+			start = 0
+		}
+	default:
+		if end = limit; end > len(all) {
+			end = len(all)
+		}
+	}
+
+	page := &IdPage{IDs: []string{}}
+	for _, c := range all[start:end] {
+		page.IDs = append(page.IDs, c.id)
+	}
+	if end < len(all) && end > 0 {
+		page.NextCursor = all[end-1].encode()
+	}
+	if start > 0 && start < len(all) {
+		page.PrevCursor = all[start].encode()
+	}
+	return page, nil
+}
+
+// parse 'limit', 'before' & 'after' query params
+func pageParams(c echo.Context) (limit int, before, after string, err error) {
+	before, after, limit = c.QueryParam("before"), c.QueryParam("after"), defaultPageSize
+	if len(before) > 0 && len(after) > 0 {
+		return 0, "", "", errors.New("only one of 'before' and 'after' can be applied")
+	}
+	if s := c.QueryParam("limit"); len(s) > 0 {
+		n, err := strconv.Atoi(s)
+		if err != nil || n < 1 || n > maxPageSize {
+			return 0, "", "", fmt.Errorf("'limit' must be an integer in [1, %d]", maxPageSize)
+		}
+		limit = n
+	}
+	return
+}
+
+// listed Post & share ids of [ids] in order. other events (e.g. Vote) share the event stream, but are not listed as Post
+func postIDs(ids []string) []string {
+	idx.RLock()
+	defer idx.RUnlock()
+	return Filter(ids, func(i int, id string) bool {
+		_, ok := idx.mListed[id]
+		return ok
+	})
+}
+
+// reply [ids] as a cursor paged envelope by query params 'limit', 'before' & 'after'.
+// hidden Post ids & non-Post events are excluded. if query param 'compat' is true, reply [ids] as the old plain array without paging
+func replyIDs(c echo.Context, ids []string) error {
+	ids = visibleIDs(ids)
+	if compat, _ := strconv.ParseBool(c.QueryParam("compat")); compat {
+		return c.JSON(http.StatusOK, postIDs(ids))
+	}
+	limit, before, after, err := pageParams(c)
+	if err != nil {
+		return c.String(http.StatusBadRequest, err.Error())
+	}
+	page, err := paginate(ids, limit, before, after)
+	switch {
+	case err == errCursor:
+		return c.String(http.StatusBadRequest, "'before' or 'after' is an invalid cursor")
+	case err != nil:
+		return c.String(http.StatusInternalServerError, err.Error())
+	}
+	return c.JSON(http.StatusOK, page)
+}
@@ -0,0 +1,66 @@
+package post
+
+import (
+	"testing"
+	"time"
+
+	em "github.com/digisan/event-mgr"
+	. "github.com/digisan/go-generics/v2"
+)
+
+func TestPaginate(t *testing.T) {
+
+	// same second for some events, order falls back to ID
+	ids := []string{}
+	for i := 0; i < 5; i++ {
+		ids = append(ids, newTestPost(t, "pager"))
+	}
+	evt := em.NewEvent("", "pager", "Post", "{}", "")
+	evt.Tm = evt.Tm.Add(-time.Hour)
+	vote := em.NewEvent("", "pager", "Vote", "{}", "") // other event in the same stream is not listed
+	for _, e := range []*em.Event{evt, vote} {
+		if err := em.AddEvent(e); err != nil {
+			t.Fatal(err)
+		}
+		if err := IndexEvent(e); err != nil {
+			t.Fatal(err)
+		}
+	}
+	gone := newTestPost(t, "pager") // deleted but still listed, dropped when its page is made
+	if _, err := em.DelEvent(gone); err != nil {
+		t.Fatal(err)
+	}
+	ids = append(ids, evt.ID, "missing-id", ids[0], vote.ID, gone)
+
+	// walk older pages, every page is full except the last one
+	got, before := []string{}, ""
+	for n := 0; ; n++ {
+		page, err := paginate(ids, 2, before, "")
+		if err != nil {
+			t.Fatal(err)
+		}
+		if len(page.IDs) != 2 || n > 2 {
+			t.Fatalf("unexpected page: %v", page)
+		}
+		got = append(got, page.IDs...)
+		if before = page.NextCursor; len(before) == 0 {
+			break
+		}
+	}
+	if len(got) != 6 || len(Settify(got...)) != 6 || In("missing-id", got...) || In(vote.ID, got...) || got[5] != evt.ID {
+		t.Fatalf("unexpected walked ids: %v", got)
+	}
+
+	// walk back newer page from the last one
+	page, err := paginate(ids, 2, "", (cursor{0, evt.Tm, evt.ID}).encode())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(page.IDs) != 2 || page.IDs[0] != got[3] || page.IDs[1] != got[4] || len(page.PrevCursor) == 0 {
+		t.Fatalf("unexpected newer page: %v", page)
+	}
+
+	if _, err := paginate(ids, 2, "bad-cursor", ""); err != errCursor {
+		t.Fatalf("bad cursor: want %v, got %v", errCursor, err)
+	}
+}
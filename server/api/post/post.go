@@ -1,18 +1,30 @@
 package post
 
 import (
+	"encoding/json"
+	"errors"
 	"fmt"
+	"html"
+	"path/filepath"
 	"strings"
 
+	em "github.com/digisan/event-mgr"
+	"github.com/digisan/file-mgr/fdb"
 	. "github.com/digisan/go-generics/v2"
-	nt "github.com/digisan/gotk/net-tool"
+	fd "github.com/digisan/gotk/filedir"
+	lk "github.com/digisan/logkit"
 	clt "github.com/wismed-web/wisite-api/server/api/client"
+	"github.com/wismed-web/wisite-api/server/api/file/media"
+	"github.com/wismed-web/wisite-api/server/api/notify"
 )
 
 type Post struct {
 	Category string      `json:"category"`
 	Topic    string      `json:"topic"`
 	Keywords string      `json:"keywords"`
+	CatID    string      `json:"catId"`    // admin-defined category id, optional
+	Tags     []string    `json:"tags"`     // normalized from Keywords at server side
+	Mentions []string    `json:"mentions"` // validated @uname in text at server side
 	Content  []Paragraph `json:"content"`
 }
 
@@ -24,9 +36,14 @@ type Paragraph struct {
 }
 
 type Attachment struct {
-	Path string `json:"path"`
-	Type string `json:"type"`
-	Size string `json:"size"` // real dimension "width,height"
+	Path     string          `json:"path"`
+	Type     string          `json:"type"`
+	Size     string          `json:"size"`     // real dimension "width,height"
+	Duration float64         `json:"duration"` // seconds, only for video
+	Bytes    int64           `json:"bytes"`
+	MIME     string          `json:"mime"`               // e.g. "video/webm", "audio/mpeg"
+	Variants []media.Variant `json:"variants,omitempty"` // resized image versions, narrowest first
+	Poster   string          `json:"poster,omitempty"`   // poster frame path of video
 }
 
 type Element struct {
@@ -50,39 +67,204 @@ func (p Post) String() string {
 	return sb.String()
 }
 
-func (p *Post) GenVFX(uname string, remoteMode bool) {
+var (
+	errPostMissing = errors.New("Post is not existing")
+	errNotOwner    = errors.New("only Post owner can do this")
+)
+
+// get rid of empty paragraph & validate each attachment path of [uname], then classify by category & tags
+func (p *Post) clean(uname string) error {
+
+	FilterFast(&p.Content, func(i int, e Paragraph) bool {
+		return len(e.Text) > 0 || len(e.Atch.Path) > 0
+	})
+
+	paths := []string{}
+	for _, item := range p.Content {
+		if len(item.Atch.Path) > 0 {
+			path := filepath.Join("data/user-space", uname, item.Atch.Path)
+			paths = append(paths, path)
+		}
+	}
+	if ok, epath := fd.AllExistAsWhole(paths...); !ok {
+		return fmt.Errorf("'%s' is invalid storage at server", filepath.Base(epath))
+	}
+	return p.classify()
+}
+
+// publish cleaned [P] from [uname] as a new Post event, as a comment if [flwee] is not empty
+func publish(uname, flwee string, P *Post) (*em.Event, error) {
+
+	// set P Category
+	//
+	switch {
+	case len(flwee) > 0:
+		P.Category = "comment"
+	default:
+		P.Category = "post"
+	}
+
+	if err := P.mention(uname); err != nil {
+		return nil, err
+	}
+
+	// save P as JSON for event
+	//
+	data, err := json.Marshal(P)
+	if err != nil {
+		return nil, err
+	}
+
+	evt := em.NewEvent("", uname, "Post", string(data), flwee)
+	if len(evt.ID) > 0 {
+		if err = em.AddEvent(evt); err != nil {
+			return nil, err
+		}
+		lk.WarnOnErr("%v", IndexEvent(evt))
+		lk.WarnOnErr("%v", saveMentions(evt, nil, P.Mentions))
+
+		// DEBUG
+		// gio.MustAppendFile("./debug.txt", []byte(evt.ID), true)
+
+		// FOLLOWING...
+		if len(flwee) > 0 {
+			ef, err := em.FetchFollow(flwee)
+			if err != nil {
+				return nil, err
+			}
+			if ef == nil {
+				if ef, err = em.NewEventFollow(flwee, true); err != nil {
+					return nil, err
+				}
+			}
+			if err := ef.AddFollower(evt.ID); err != nil {
+				return nil, err
+			}
+			if fe, err := em.FetchEvent(true, flwee); err == nil && fe != nil {
+				notifyUser(fe.Owner, notify.Comment, uname, evt.ID, flwee)
+			}
+		}
+	}
+	return evt, nil
+}
+
+// update each attachment path for remote access, fill its type & area size etc., then generate html elements for [uname].
+// media src is under [base] url, see [mediaBase]
+func (p *Post) present(owner, uname, base string) error {
+
+	// RichText stored before sanitizing at uploading is sanitized for rendering
+	p.sanitize()
+
+	for i, para := range p.Content {
+
+		// originally, path start with yyyy-mm
+		path := para.Atch.Path
+
+		// 1) update path for remote access
+		p.Content[i].Atch.Path = filepath.Join(owner, path)
+
+		// 2) update type, area size etc. from media metadata stored at uploading.
+		//    failed probe only degrades this attachment, e.g. no area size
+		fpath := filepath.Join("data", "user-space", owner, path)
+		m, err := media.Load(fpath)
+		p.Content[i].Atch.Variants, p.Content[i].Atch.Poster = nil, ""
+		if err != nil {
+			lk.Warn("load media metadata error %v @ %s", err, fpath)
+			p.Content[i].Atch.Type = fdb.GetFileType(fpath)
+			continue
+		}
+		p.Content[i].Atch.Type = m.Type
+		p.Content[i].Atch.Size = m.Size
+		p.Content[i].Atch.Duration = m.Duration
+		p.Content[i].Atch.Bytes = m.Bytes
+		p.Content[i].Atch.MIME = m.MIME
+
+		// 3) variants & poster for remote access, their storage paths are under "data/user-space"
+		for _, v := range m.Variants {
+			v.Path = spacePath(v.Path)
+			p.Content[i].Atch.Variants = append(p.Content[i].Atch.Variants, v)
+		}
+		if len(m.Poster) > 0 {
+			p.Content[i].Atch.Poster = spacePath(m.Poster)
+		}
+	}
+
+	p.GenVFX(uname, base)
+	return nil
+}
+
+// storage path "data/user-space/uname/..." to remote access path "uname/..."
+func spacePath(fpath string) string {
+	if rel, err := filepath.Rel(filepath.Join("data", "user-space"), fpath); err == nil {
+		return rel
+	}
+	return fpath
+}
+
+// 'srcset' of image variants & original image in [size] "width,height", for responsive loading
+func srcset(base string, variants []media.Variant, src, size string) string {
+	if len(variants) == 0 {
+		return ""
+	}
+	escape := func(s string) string { return strings.ReplaceAll(s, ",", "%2C") }
+	cands := []string{}
+	for _, v := range variants {
+		cands = append(cands, fmt.Sprintf("%s %dw", escape(mediaURL(base, v.Path)), v.Width))
+	}
+	if w, _, ok := strings.Cut(size, ","); ok {
+		cands = append(cands, fmt.Sprintf("%s %sw", escape(src), w))
+	}
+	return strings.Join(cands, ", ")
+}
+
+// generate html elements of each paragraph for [uname], media src is under [base] url.
+// image has 'srcset' of its variants & video has 'poster', so that small screen downloads small version
+func (p *Post) GenVFX(uname, base string) {
 
 	lo := clt.GetLayout(uname)
 	width := lo.PostWidth() / 2
 	height := lo.PostContentHeight() / 2
-	ip := IF(remoteMode, nt.PublicIP(), "127.0.0.1")
-	port := 1323
 
-	for _, para := range p.Content {
+	for i, para := range p.Content {
 
 		ele := para.Ele
 
 		if len(para.RichText) != 0 {
 			ele.HP = para.RichText
 		} else if len(para.Text) != 0 {
-			ele.HP = fmt.Sprintf(`<p>%s</p>`, para.Text)
+			ele.HP = fmt.Sprintf(`<p>%s</p>`, html.EscapeString(para.Text))
 		}
 
-		src := fmt.Sprintf(`http://%s:%d%s`, ip, port, para.Atch.Path)
+		rawSrc := mediaURL(base, para.Atch.Path)
+		src := html.EscapeString(rawSrc)
+		typ := ""
+		if len(para.Atch.MIME) > 0 {
+			typ = fmt.Sprintf(` type="%s"`, html.EscapeString(para.Atch.MIME))
+		}
 
 		switch para.Atch.Type {
 		case "image":
-			ele.Image = fmt.Sprintf(`<img src="%s" alt="" width="%d" height="%d">`, src, width, height)
+			responsive := ""
+			if set := srcset(base, para.Atch.Variants, rawSrc, para.Atch.Size); len(set) > 0 {
+				responsive = fmt.Sprintf(` srcset="%s" sizes="(max-width: %dpx) 100vw, %dpx"`, html.EscapeString(set), width, width)
+			}
+			ele.Image = fmt.Sprintf(`<img src="%s"%s alt="" width="%d" height="%d">`, src, responsive, width, height)
 		case "video":
-			ele.Video = fmt.Sprintf(`<video width="%d" height="%d" controls autoplay muted>
-				<source src="%s" type="video/mp4">
-			</video>`, width, height, src)
-		case "":
+			poster := ""
+			if len(para.Atch.Poster) > 0 {
+				poster = fmt.Sprintf(` poster="%s"`, html.EscapeString(mediaURL(base, para.Atch.Poster)))
+			}
+			ele.Video = fmt.Sprintf(`<video width="%d" height="%d"%s controls autoplay muted>
+				<source src="%s"%s>
+			</video>`, width, height, poster, src, typ)
+		case "audio":
 			ele.Audio = fmt.Sprintf(`<audio controls>
-				<source src="%s" type="audio/ogg">
-			</audio>`, src)
+				<source src="%s"%s>
+			</audio>`, src, typ)
 		default:
 
 		}
+
+		p.Content[i].Ele = ele
 	}
 }
@@ -0,0 +1,139 @@
+package post
+
+import (
+	"errors"
+	"strings"
+	"sync"
+
+	em "github.com/digisan/event-mgr"
+	. "github.com/digisan/go-generics/v2"
+	r "github.com/digisan/user-mgr/relation"
+	"github.com/wismed-web/wisite-api/server/api/notify"
+)
+
+// reaction type is participation category of event. "ThumbsUp" keeps the original thumbs-up category
+var reactions = []string{"ThumbsUp", "Insightful", "Agree", "Question", "Thanks"}
+
+var (
+	mtxReact = &sync.Mutex{} // guard participation read-modify-write, all reaction types of an event are stored together
+
+	errReactionType = errors.New("unsupported reaction type")
+)
+
+// replace reaction set, e.g. from config file. empty [types] keeps current set. call it before serving
+func SetReactions(types ...string) {
+	types = Settify(Filter(types, func(i int, e string) bool { return len(strings.TrimSpace(e)) > 0 })...)
+	if len(types) > 0 {
+		reactions = types
+	}
+}
+
+func Reactions() []string {
+	return reactions
+}
+
+// canonical reaction type of [typ], case-insensitive
+func reactionType(typ string) (string, error) {
+	for _, reaction := range reactions {
+		if strings.EqualFold(reaction, typ) {
+			return reaction, nil
+		}
+	}
+	return "", errReactionType
+}
+
+func nonEmpty(i int, e string) bool {
+	return len(e) > 0
+}
+
+// toggle [uname]'s reaction [typ] on event [id], return if reacted after toggling & reaction count
+func react(id, typ, uname string) (bool, int, error) {
+	has, ptps, err := togglePtp(id, typ, uname)
+	if err != nil {
+		return false, 0, err
+	}
+	if has {
+		if event, err := em.FetchEvent(true, id); err == nil && event != nil {
+			notifyUser(event.Owner, notify.Reaction, uname, id, typ)
+		}
+	}
+	return has, len(Filter(ptps, nonEmpty)), nil
+}
+
+// toggle [uname] in participants [typ] of event [id], return if [uname] is in after toggling & participants
+func togglePtp(id, typ, uname string) (bool, []string, error) {
+	mtxReact.Lock()
+	defer mtxReact.Unlock()
+
+	ep, err := em.NewEventParticipate(id, true)
+	if err != nil {
+		return false, nil, err
+	}
+	has, err := ep.TogglePtp(typ, uname)
+	if err != nil {
+		return false, nil, err
+	}
+	ptps, err := ep.Ptps(typ)
+	if err != nil {
+		return false, nil, err
+	}
+	return has, ptps, nil
+}
+
+// total count of all reaction types on event [id]
+func reactionCount(id string) (int, error) {
+	ep, err := em.NewEventParticipate(id, true)
+	if err != nil {
+		return 0, err
+	}
+	n := 0
+	for _, typ := range reactions {
+		ptps, err := ep.Ptps(typ)
+		if err != nil {
+			return 0, err
+		}
+		n += len(Filter(ptps, nonEmpty))
+	}
+	return n, nil
+}
+
+// each reaction count on event [id], and reactions from [uname]
+func reactionStatus(id, uname string) (map[string]int, []string, error) {
+	ep, err := em.NewEventParticipate(id, true)
+	if err != nil {
+		return nil, nil, err
+	}
+	counts, mine := map[string]int{}, []string{}
+	for _, typ := range reactions {
+		ptps, err := ep.Ptps(typ)
+		if err != nil {
+			return nil, nil, err
+		}
+		counts[typ] = len(Filter(ptps, nonEmpty))
+		if In(uname, ptps...) {
+			mine = append(mine, typ)
+		}
+	}
+	return counts, mine, nil
+}
+
+// who reacted on event [id] for each of [types], users blocked by [viewer] are filtered out
+func reactors(id, viewer string, types ...string) (map[string][]string, error) {
+	blocked, err := fnListRel(viewer, r.BLOCKED)
+	if err != nil {
+		return nil, err
+	}
+	ep, err := em.NewEventParticipate(id, true)
+	if err != nil {
+		return nil, err
+	}
+	m := map[string][]string{}
+	for _, typ := range types {
+		ptps, err := ep.Ptps(typ)
+		if err != nil {
+			return nil, err
+		}
+		m[typ] = Filter(ptps, func(i int, e string) bool { return len(e) > 0 && NotIn(e, blocked...) })
+	}
+	return m, nil
+}
@@ -0,0 +1,103 @@
+package post
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"reflect"
+	"sync"
+	"testing"
+
+	r "github.com/digisan/user-mgr/relation"
+)
+
+func TestReaction(t *testing.T) {
+	fnListRel = func(uname string, flag int) ([]string, error) {
+		if uname == "rc-alice" && flag == r.BLOCKED {
+			return []string{"rc-carol"}, nil
+		}
+		return []string{}, nil
+	}
+
+	if typ, err := reactionType("insightful"); err != nil || typ != "Insightful" {
+		t.Fatalf("reaction type should be case-insensitive, got %v, %v", typ, err)
+	}
+	if _, err := reactionType("angry"); err != errReactionType {
+		t.Fatalf("unsupported reaction type: want %v, got %v", errReactionType, err)
+	}
+
+	id := newTestPost(t, "rc-alice")
+	for _, rc := range []struct{ uname, typ string }{
+		{"rc-alice", "Agree"},
+		{"rc-bob", "Agree"},
+		{"rc-carol", "Agree"},
+		{"rc-bob", "Thanks"},
+		{"rc-bob", "Thanks"}, // toggle off
+	} {
+		if _, _, err := react(id, rc.typ, rc.uname); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	if n := lastNotified("rc-alice"); n == nil || n.Type != "reaction" || n.Actor != "rc-bob" || n.Text != "Thanks" {
+		t.Fatalf("Post owner should be notified of reaction, got %v", n)
+	}
+
+	counts, mine, err := reactionStatus(id, "rc-bob")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if counts["Agree"] != 3 || counts["Thanks"] != 0 || !reflect.DeepEqual(mine, []string{"Agree"}) {
+		t.Fatalf("unexpected status: %v, %v", counts, mine)
+	}
+
+	m, err := reactors(id, "rc-alice", "Agree", "Thanks")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(m["Agree"], []string{"rc-alice", "rc-bob"}) || len(m["Thanks"]) != 0 {
+		t.Fatalf("blocked reactor should be filtered: %v", m)
+	}
+
+	if rec := invoke(React, http.MethodPatch, "rc-bob", "type=angry"); rec.Code != http.StatusBadRequest {
+		t.Fatalf("unsupported type status: want %d, got %d", http.StatusBadRequest, rec.Code)
+	}
+	if rec := invoke(ReactionTypes, http.MethodGet, "rc-bob", ""); rec.Code != http.StatusOK {
+		t.Fatalf("types status: want %d, got %d", http.StatusOK, rec.Code)
+	} else {
+		types := []string{}
+		if err := json.Unmarshal(rec.Body.Bytes(), &types); err != nil || !reflect.DeepEqual(types, Reactions()) {
+			t.Fatalf("unexpected types: %v, %v", types, err)
+		}
+	}
+}
+
+func TestReactionConcurrent(t *testing.T) {
+	id := newTestPost(t, uniq("rc-owner"))
+
+	// reactions of all types are stored together, none is lost
+	var (
+		n  = 20
+		wg sync.WaitGroup
+	)
+	for i := 0; i < n; i++ {
+		for _, typ := range []string{"Agree", "Thanks"} {
+			wg.Add(1)
+			go func(uname, typ string) {
+				defer wg.Done()
+				if _, _, err := react(id, typ, uname); err != nil {
+					t.Error(err)
+				}
+			}(fmt.Sprintf("rc-user%d", i), typ)
+		}
+	}
+	wg.Wait()
+
+	counts, _, err := reactionStatus(id, "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if counts["Agree"] != n || counts["Thanks"] != n {
+		t.Fatalf("concurrent reactions should all be kept, got %v", counts)
+	}
+}
@@ -0,0 +1,158 @@
+package post
+
+import (
+	"encoding/json"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/dgraph-io/badger/v3"
+	bh "github.com/digisan/db-helper/badger"
+	em "github.com/digisan/event-mgr"
+	lk "github.com/digisan/logkit"
+)
+
+const (
+	SEP = "^"
+)
+
+var (
+	mtxEdit = &sync.Mutex{} // one edit at a time, keep revision sequence continuous
+)
+
+// key: post id ^ seq;
+// value: json of earlier Post body with its meta
+type Revision struct {
+	PostID   string    `json:"postId"`
+	Seq      int       `json:"seq"`      // 0 is the original body
+	Author   string    `json:"author"`   // who wrote this body
+	Tm       time.Time `json:"tm"`       // when this body was published
+	Replaced time.Time `json:"replaced"` // when this body was replaced by an edit
+	Post     *Post     `json:"post,omitempty"`
+}
+
+func (rev Revision) String() string {
+	return fmt.Sprintf("%s #%d by %s @%v, replaced @%v", rev.PostID, rev.Seq, rev.Author, rev.Tm, rev.Replaced)
+}
+
+func (rev *Revision) BadgerDB() *badger.DB {
+	return DbGrp.Revision
+}
+
+func (rev *Revision) Key() []byte {
+	return []byte(fmt.Sprintf("%s%s%06d", rev.PostID, SEP, rev.Seq))
+}
+
+func (rev *Revision) Marshal(at any) (forKey, forValue []byte) {
+	forKey = rev.Key()
+	forValue, err := json.Marshal(rev)
+	lk.FailOnErr("%v", err)
+	return
+}
+
+func (rev *Revision) Unmarshal(dbKey, dbVal []byte) (any, error) {
+	if err := json.Unmarshal(dbVal, rev); err != nil {
+		return nil, err
+	}
+	return rev, nil
+}
+
+// all revisions of a Post, ordered by seq
+func Revisions(id string) ([]*Revision, error) {
+	return bh.GetObjects[Revision]([]byte(id+SEP), nil)
+}
+
+// nil if not found
+func FetchRevision(id string, seq int) (*Revision, error) {
+	return bh.GetOneObject[Revision]((&Revision{PostID: id, Seq: seq}).Key())
+}
+
+func eraseRevisions(id string) (int, error) {
+	return bh.DeleteObjects[Revision]([]byte(id + SEP))
+}
+
+// if Post was edited, return last edit time
+func LastEdit(id string) (bool, time.Time, error) {
+	revs, err := Revisions(id)
+	if err != nil {
+		return false, time.Time{}, err
+	}
+	if n := len(revs); n > 0 {
+		return true, revs[n-1].Replaced, nil
+	}
+	return false, time.Time{}, nil
+}
+
+// alive Post [id] visible to [uname], nil if it is deleted, hidden for [uname] or not a Post
+func visiblePost(id, uname string) (*em.Event, error) {
+	event, err := em.FetchEvent(true, id)
+	if err != nil || event == nil || event.EvtType != "Post" {
+		return nil, err
+	}
+	hidden, err := hiddenFor(id, event.Owner, uname)
+	if err != nil || hidden {
+		return nil, err
+	}
+	return event, nil
+}
+
+// archive current Post body as a revision, then replace it with [P]. event must be alive & owned by [editor]
+func editPost(id, editor string, P *Post) (*Revision, error) {
+	mtxEdit.Lock()
+	defer mtxEdit.Unlock()
+
+	event, err := em.FetchEvent(true, id)
+	if err != nil {
+		return nil, err
+	}
+	if event == nil || event.EvtType != "Post" { // other events (e.g. Vote, share) are not editable as Post
+		return nil, errPostMissing
+	}
+	if event.Owner != editor {
+		return nil, errNotOwner
+	}
+
+	prev := &Post{}
+	if err := json.Unmarshal([]byte(event.RawJSON), prev); err != nil {
+		return nil, fmt.Errorf("convert RawJSON to [Post] Unmarshal error")
+	}
+
+	revs, err := Revisions(id)
+	if err != nil {
+		return nil, err
+	}
+	tm := event.Tm
+	if n := len(revs); n > 0 {
+		tm = revs[n-1].Replaced
+	}
+
+	rev := &Revision{
+		PostID:   id,
+		Seq:      len(revs),
+		Author:   event.Owner,
+		Tm:       tm,
+		Replaced: time.Now().Truncate(time.Second),
+		Post:     prev,
+	}
+
+	P.Category = prev.Category
+	if err := P.mention(editor); err != nil {
+		return nil, err
+	}
+	data, err := json.Marshal(P)
+	if err != nil {
+		return nil, err
+	}
+	if err := bh.UpsertOneObject(rev); err != nil {
+		return nil, err
+	}
+	event.RawJSON = string(data)
+	if err := event.Publish(event.Public); err != nil { // Publish stores whole event
+		_, errDel := bh.DeleteOneObject[Revision](rev.Key()) // no orphan revision for failed edit
+		lk.WarnOnErr("%v", errDel)
+		return nil, err
+	}
+	lk.WarnOnErr("%v", IndexEvent(event))
+	lk.WarnOnErr("%v", saveMentions(event, prev.Mentions, P.Mentions))
+	return rev, nil
+}
@@ -0,0 +1,101 @@
+package post
+
+import (
+	"encoding/json"
+	"net/http"
+	"testing"
+
+	em "github.com/digisan/event-mgr"
+)
+
+func TestEditPost(t *testing.T) {
+	var (
+		alice = uniq("rv-alice")
+		bob   = uniq("rv-bob")
+	)
+	id := newTestPost(t, alice)
+
+	if _, err := editPost(id, bob, &Post{Topic: "by bob"}); err != errNotOwner {
+		t.Fatalf("non-owner edit should fail, got %v", err)
+	}
+	for i, topic := range []string{"first", "second"} {
+		rev, err := editPost(id, alice, &Post{Topic: topic})
+		if err != nil || rev.Seq != i || rev.Author != alice {
+			t.Fatalf("edit #%d: %v %v", i, rev, err)
+		}
+	}
+
+	revs, err := Revisions(id)
+	if err != nil || len(revs) != 2 || revs[0].Post.Topic != "test" || revs[1].Post.Topic != "first" {
+		t.Fatalf("unexpected revisions: %v %v", revs, err)
+	}
+	if edited, tm, err := LastEdit(id); err != nil || !edited || !tm.Equal(revs[1].Replaced) {
+		t.Fatalf("unexpected last edit: %v %v %v", edited, tm, err)
+	}
+	evt, err := em.FetchEvent(true, id)
+	if err != nil {
+		t.Fatal(err)
+	}
+	P := &Post{}
+	if err := json.Unmarshal([]byte(evt.RawJSON), P); err != nil || P.Topic != "second" || P.Category != "post" {
+		t.Fatalf("unexpected edited Post: %+v %v", P, err)
+	}
+
+	// other events are not editable as Post, nothing archived
+	vote := em.NewEvent("", alice, "Vote", "{}", "")
+	if err := em.AddEvent(vote); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := editPost(vote.ID, alice, &Post{Topic: "vote"}); err != errPostMissing {
+		t.Fatalf("editing Vote should fail, got %v", err)
+	}
+	if revs, err := Revisions(vote.ID); err != nil || len(revs) > 0 {
+		t.Fatalf("failed edit should not archive revision: %v %v", revs, err)
+	}
+}
+
+func TestRevisionAccess(t *testing.T) {
+	var (
+		alice = uniq("rv-alice")
+		bob   = uniq("rv-bob")
+	)
+	id := newTestPost(t, alice)
+	if _, err := editPost(id, alice, &Post{Topic: "edited"}); err != nil {
+		t.Fatal(err)
+	}
+
+	for _, tt := range []struct {
+		uname      string
+		list, body int
+	}{
+		{alice, http.StatusOK, http.StatusOK},
+		{"admin", http.StatusOK, http.StatusOK},
+		{bob, http.StatusOK, http.StatusForbidden},
+	} {
+		if code := invokeID(RevisionList, http.MethodGet, tt.uname, id, ""); code != tt.list {
+			t.Fatalf("revision list for %s: want %d, got %d", tt.uname, tt.list, code)
+		}
+		if code := invokeID(RevisionOne, http.MethodGet, tt.uname, id, "seq=0"); code != tt.body {
+			t.Fatalf("revision body for %s: want %d, got %d", tt.uname, tt.body, code)
+		}
+	}
+
+	// hidden Post is only revealed to owner & admin
+	setHidden(id, true)
+	if code := invokeID(RevisionList, http.MethodGet, bob, id, ""); code != http.StatusNotFound {
+		t.Fatalf("revisions of hidden Post should not be found, got %d", code)
+	}
+	if code := invokeID(RevisionOne, http.MethodGet, alice, id, "seq=0"); code != http.StatusOK {
+		t.Fatalf("owner should get revision of own hidden Post, got %d", code)
+	}
+	setHidden(id, false)
+
+	if _, err := em.DelEvent(id); err != nil {
+		t.Fatal(err)
+	}
+	for _, uname := range []string{alice, "admin"} {
+		if code := invokeID(RevisionOne, http.MethodGet, uname, id, "seq=0"); code != http.StatusNotFound {
+			t.Fatalf("revision of deleted Post should not be found, got %d", code)
+		}
+	}
+}
@@ -0,0 +1,216 @@
+package post
+
+import (
+	"encoding/json"
+	"net/url"
+	"strings"
+
+	. "github.com/digisan/go-generics/v2"
+	lk "github.com/digisan/logkit"
+	"github.com/labstack/echo/v4"
+	"golang.org/x/net/html"
+	"golang.org/x/net/html/atom"
+)
+
+// allow-list HTML sanitizer for Paragraph RichText
+
+// response header for sanitizing report, json of [SanitizeReport]
+const HdrSanitizeReport = "X-Sanitize-Report"
+
+var (
+	// allowed elements with their allowed attributes
+	mAllowed = map[string][]string{
+		"p": {}, "br": {}, "hr": {}, "div": {}, "span": {},
+		"b": {}, "strong": {}, "i": {}, "em": {}, "u": {}, "s": {}, "strike": {}, "del": {}, "ins": {},
+		"sub": {}, "sup": {}, "small": {}, "mark": {},
+		"h1": {}, "h2": {}, "h3": {}, "h4": {}, "h5": {}, "h6": {},
+		"ul": {}, "ol": {"start"}, "li": {},
+		"blockquote": {}, "pre": {}, "code": {},
+		"table": {}, "thead": {}, "tbody": {}, "tfoot": {}, "tr": {}, "th": {"colspan", "rowspan"}, "td": {"colspan", "rowspan"},
+		"a":   {"href", "title"},
+		"img": {"src", "alt", "title", "width", "height"},
+	}
+
+	// elements removed with all their content. other disallowed elements are unwrapped, i.e. only their content is kept
+	dropWithContent = []string{
+		"script", "style", "iframe", "frame", "frameset", "object", "embed", "applet", "svg", "math",
+		"template", "noscript", "noembed", "noframes", "form", "input", "button", "textarea", "select",
+		"option", "link", "meta", "base", "title", "xml", "head",
+	}
+
+	voidElements   = []string{"br", "hr", "img"}
+	linkSchemes    = []string{"http", "https", "mailto"}
+	maxReportSize  = 64   // max length of removed value in report
+	maxReportItems = 10   // max removed items listed in report header
+	maxReportBytes = 2048 // max report header size, proxies reject large headers, e.g. nginx 4-8KB for all
+)
+
+// sanitizing report in response header, only first few removed items are listed
+type SanitizeReport struct {
+	Count   int       `json:"count"`   // count of all removed items
+	Removed []Removed `json:"removed"` // first removed items
+}
+
+// one removed element or attribute in a sanitized Paragraph
+type Removed struct {
+	Paragraph int    `json:"paragraph"`           // Paragraph index in Post Content
+	Element   string `json:"element"`             // tag name, "!--" for comment
+	Attribute string `json:"attribute,omitempty"` // empty if whole element is removed
+	Value     string `json:"value,omitempty"`     // removed attribute value, truncated
+}
+
+// control chars & spaces are ignored by browsers when parsing url scheme, e.g. "jav&#x09;ascript:"
+func urlForCheck(v string) string {
+	return strings.Map(func(r rune) rune {
+		if r <= 0x20 || r == 0x7f {
+			return -1
+		}
+		return r
+	}, v)
+}
+
+// link is relative, or its scheme is allowed
+func safeLink(v string) bool {
+	u, err := url.Parse(urlForCheck(v))
+	if err != nil {
+		return false
+	}
+	return u.Scheme == "" || In(strings.ToLower(u.Scheme), linkSchemes...)
+}
+
+// image source must be our own storage, i.e. relative path like "/uname/2022-05/image/a.png"
+func ownStorage(v string) bool {
+	u, err := url.Parse(urlForCheck(v))
+	if err != nil {
+		return false
+	}
+	return u.Scheme == "" && u.Host == "" && len(u.Path) > 0 && !strings.HasPrefix(v, "//") && !strings.Contains(u.Path, "..")
+}
+
+func truncate(s string) string {
+	if rs := []rune(s); len(rs) > maxReportSize {
+		return string(rs[:maxReportSize]) + "..."
+	}
+	return s
+}
+
+type sanitizer struct {
+	para    int
+	sb      strings.Builder
+	removed []Removed
+}
+
+func (s *sanitizer) remove(element, attribute, value string) {
+	s.removed = append(s.removed, Removed{s.para, element, attribute, truncate(value)})
+}
+
+func (s *sanitizer) walk(n *html.Node) {
+	switch n.Type {
+	case html.TextNode:
+		s.sb.WriteString(html.EscapeString(n.Data))
+
+	case html.CommentNode:
+		s.remove("!--", "", n.Data)
+
+	case html.ElementNode:
+		tag := strings.ToLower(n.Data)
+		attrs, ok := mAllowed[tag]
+		switch {
+		case In(tag, dropWithContent...) || n.Namespace != "":
+			s.remove(tag, "", "")
+			return
+		case !ok:
+			s.remove(tag, "", "") // unwrap
+			s.children(n)
+			return
+		}
+
+		s.sb.WriteString("<" + tag)
+		for _, attr := range n.Attr {
+			key := strings.ToLower(attr.Key)
+			switch {
+			case attr.Namespace != "" || NotIn(key, attrs...):
+				s.remove(tag, key, attr.Val)
+				continue
+			case key == "href" && !safeLink(attr.Val):
+				s.remove(tag, key, attr.Val)
+				continue
+			case key == "src" && !ownStorage(attr.Val):
+				s.remove(tag, key, attr.Val)
+				continue
+			}
+			s.sb.WriteString(" " + key + `="` + html.EscapeString(attr.Val) + `"`)
+		}
+		s.sb.WriteString(">")
+
+		if In(tag, voidElements...) {
+			return
+		}
+		s.children(n)
+		s.sb.WriteString("</" + tag + ">")
+
+	default:
+		s.children(n)
+	}
+}
+
+func (s *sanitizer) children(n *html.Node) {
+	for c := n.FirstChild; c != nil; c = c.NextSibling {
+		s.walk(c)
+	}
+}
+
+// sanitize one piece of rich text, [para] is Paragraph index for report
+func sanitizeHTML(richText string, para int) (string, []Removed) {
+	ctx := &html.Node{Type: html.ElementNode, Data: "div", DataAtom: atom.Div}
+	nodes, err := html.ParseFragment(strings.NewReader(richText), ctx)
+	if err != nil {
+		// unparsable content is kept as plain text
+		return html.EscapeString(richText), []Removed{{Paragraph: para, Element: "#unparsable", Value: truncate(richText)}}
+	}
+	s := &sanitizer{para: para}
+	for _, n := range nodes {
+		s.walk(n)
+	}
+	return s.sb.String(), s.removed
+}
+
+// sanitize each Paragraph RichText by allow-list, return what was removed
+func (p *Post) sanitize() []Removed {
+	removed := []Removed{}
+	for i, para := range p.Content {
+		if len(para.RichText) == 0 {
+			continue
+		}
+		var r []Removed
+		p.Content[i].RichText, r = sanitizeHTML(para.RichText, i)
+		removed = append(removed, r...)
+	}
+	return removed
+}
+
+// sanitize [P] RichText uploaded by [uname], then report what was removed in response header
+func sanitizeReport(c echo.Context, uname string, P *Post) {
+	removed := P.sanitize()
+	if len(removed) == 0 {
+		return
+	}
+	lk.Warn("sanitized Post from [%s], removed: %v", uname, removed)
+	c.Response().Header().Set(HdrSanitizeReport, reportHeader(removed))
+}
+
+// header value of [removed], capped by 'maxReportItems' & 'maxReportBytes'
+func reportHeader(removed []Removed) string {
+	report := SanitizeReport{Count: len(removed), Removed: removed}
+	if len(removed) > maxReportItems {
+		report.Removed = removed[:maxReportItems]
+	}
+	for {
+		data, err := json.Marshal(report)
+		lk.WarnOnErr("%v", err)
+		if len(data) <= maxReportBytes || len(report.Removed) == 0 {
+			return string(data)
+		}
+		report.Removed = report.Removed[:len(report.Removed)-1]
+	}
+}
@@ -0,0 +1,202 @@
+package post
+
+import (
+	"encoding/json"
+	"strings"
+	"testing"
+
+	em "github.com/digisan/event-mgr"
+	clt "github.com/wismed-web/wisite-api/server/api/client"
+	"golang.org/x/net/html"
+	"golang.org/x/net/html/atom"
+)
+
+// known XSS vectors, mostly from OWASP XSS filter evasion cheat sheet
+var xssCorpus = []string{
+	`<script>alert(1)</script>`,
+	`<SCRIPT SRC=http://xss.rocks/xss.js></SCRIPT>`,
+	`<scr<script>ipt>alert(1)</script>`,
+	`<<script>alert(1);//<</script>`,
+	`<img src=x onerror=alert(1)>`,
+	`<IMG SRC="javascript:alert('XSS');">`,
+	`<IMG SRC=JaVaScRiPt:alert('XSS')>`,
+	`<IMG SRC="jav	ascript:alert('XSS');">`,
+	`<IMG SRC="jav&#x09;ascript:alert('XSS');">`,
+	`<IMG SRC=" &#14;  javascript:alert('XSS');">`,
+	`<IMG SRC=&#106;&#97;&#118;&#97;&#115;&#99;&#114;&#105;&#112;&#116;&#58;&#97;&#108;&#101;&#114;&#116;&#40;&#39;&#88;&#83;&#83;&#39;&#41;>`,
+	`<IMG """><SCRIPT>alert("XSS")</SCRIPT>">`,
+	`<img src="data:image/svg+xml;base64,PHN2ZyBvbmxvYWQ9YWxlcnQoMSk+">`,
+	`<img src="https://evil.example/x.png">`,
+	`<img src="//evil.example/x.png">`,
+	`<a href="javascript:alert(1)">x</a>`,
+	`<a href=" javascript:alert(1)">x</a>`,
+	`<a href="&#106;avascript:alert(1)">x</a>`,
+	`<a href="JaVaScRiPt&colon;alert(1)">x</a>`,
+	`<a href="vbscript:msgbox(1)">x</a>`,
+	`<a href="data:text/html;base64,PHNjcmlwdD5hbGVydCgxKTwvc2NyaXB0Pg==">x</a>`,
+	`<a href="https://example.com" onmouseover="alert(1)">x</a>`,
+	`<p onclick="alert(1)">x</p>`,
+	`<div style="background-image: url(javascript:alert(1))">x</div>`,
+	`<svg onload=alert(1)>`,
+	`<svg><script>alert(1)</script></svg>`,
+	`<math><mi xlink:href="javascript:alert(1)">x</mi></math>`,
+	`<iframe src="javascript:alert(1)"></iframe>`,
+	`<iframe srcdoc="<script>alert(1)</script>"></iframe>`,
+	`<object data="javascript:alert(1)"></object>`,
+	`<embed src="javascript:alert(1)">`,
+	`<body onload=alert(1)>`,
+	`<form action="javascript:alert(1)"><input type="submit"></form>`,
+	`<button formaction="javascript:alert(1)">x</button>`,
+	`<meta http-equiv="refresh" content="0;url=javascript:alert(1)">`,
+	`<link rel="stylesheet" href="javascript:alert(1)">`,
+	`<base href="javascript:alert(1)//">`,
+	`<style>@import 'javascript:alert(1)';</style>`,
+	`<details open ontoggle=alert(1)>`,
+	`<video><source onerror="alert(1)"></video>`,
+	`<input autofocus onfocus=alert(1)>`,
+	`<marquee onstart=alert(1)>x</marquee>`,
+	`<!--<img src="--><img src=x onerror=alert(1)//">`,
+	`<noscript><p title="</noscript><img src=x onerror=alert(1)>">`,
+	`<table background="javascript:alert(1)"><tr><td>x</td></tr></table>`,
+	`<textarea><script>alert(1)</script></textarea>`,
+	`<title><img src=x onerror=alert(1)></title>`,
+	`<a href="#" onclick="alert(1)">x</a>`,
+}
+
+var dangerous = []string{
+	"<script", "javascript", "vbscript", "data:", "onerror", "onload", "onclick", "onmouseover", "onfocus",
+	"ontoggle", "onstart", "<iframe", "<svg", "<math", "<object", "<embed", "<form", "<input", "<button",
+	"<meta", "<link", "<base", "<style", "style=", "formaction", "srcdoc", "evil.example", "background=",
+}
+
+// elements & attributes of sanitized html as "<tag key=value ...>", entities decoded.
+// text is left out, as escaped text like "&lt;script&gt;" is harmless
+func markup(t *testing.T, out string) string {
+	ctx := &html.Node{Type: html.ElementNode, Data: "div", DataAtom: atom.Div}
+	nodes, err := html.ParseFragment(strings.NewReader(out), ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	sb := strings.Builder{}
+	var walk func(n *html.Node)
+	walk = func(n *html.Node) {
+		if n.Type == html.ElementNode {
+			sb.WriteString("<" + n.Data)
+			for _, a := range n.Attr {
+				sb.WriteString(" " + a.Key + "=" + a.Val)
+			}
+			sb.WriteString(">")
+		}
+		for c := n.FirstChild; c != nil; c = c.NextSibling {
+			walk(c)
+		}
+	}
+	for _, n := range nodes {
+		walk(n)
+	}
+	return strings.ToLower(sb.String())
+}
+
+func TestSanitizeXSS(t *testing.T) {
+	for _, vector := range xssCorpus {
+		out, removed := sanitizeHTML(vector, 0)
+		lower, tags := strings.ToLower(out), markup(t, out)
+		for _, d := range dangerous {
+			// raw '<' only appears in output as markup, so tag tokens are also checked on raw output
+			if strings.Contains(tags, d) || (strings.HasPrefix(d, "<") && strings.Contains(lower, d)) {
+				t.Errorf("%q\n\tsanitized to %q, still contains %q", vector, out, d)
+			}
+		}
+		// e.g. <body> is dropped by html parser in fragment context, nothing left to report
+		if len(removed) == 0 && len(out) > 0 {
+			t.Errorf("%q\n\tsanitized to %q, nothing reported as removed", vector, out)
+		}
+	}
+}
+
+func TestSanitizeKeepSafe(t *testing.T) {
+	tests := []string{
+		`<p>Hello <b>world</b> &amp; <i>friends</i></p>`,
+		`<a href="https://example.com/a?b=1&amp;c=2" title="t">link</a>`,
+		`<a href="/alice/2023-05/document/a.pdf">doc</a>`,
+		`<a href="mailto:a@example.com">mail</a>`,
+		`<img src="/alice/2023-05/image/a.png" alt="a" width="10" height="10">`,
+		`<ul><li>one</li><li>two</li></ul><ol start="3"><li>three</li></ol>`,
+		`<table><tbody><tr><td colspan="2">cell</td></tr></tbody></table>`,
+		`<h2>title</h2><blockquote>quote</blockquote><pre><code>x &lt; y</code></pre><br><hr>`,
+	}
+	for _, tt := range tests {
+		if out, removed := sanitizeHTML(tt, 0); out != tt || len(removed) > 0 {
+			t.Errorf("%q\n\tsanitized to %q, removed %v", tt, out, removed)
+		}
+	}
+}
+
+func TestSanitizeReport(t *testing.T) {
+	P := &Post{Content: []Paragraph{
+		{RichText: `<p>safe</p>`},
+		{RichText: `<p onclick="alert(1)">x</p><script>alert(2)</script><blink>y</blink>`},
+	}}
+	removed := P.sanitize()
+	want := []Removed{
+		{1, "p", "onclick", "alert(1)"},
+		{1, "script", "", ""},
+		{1, "blink", "", ""},
+	}
+	if len(removed) != len(want) {
+		t.Fatalf("want %v, got %v", want, removed)
+	}
+	for i := range want {
+		if removed[i] != want[i] {
+			t.Fatalf("want %v, got %v", want, removed)
+		}
+	}
+	if P.Content[1].RichText != `<p>x</p>y` {
+		t.Fatalf("unexpected sanitized rich text: %q", P.Content[1].RichText)
+	}
+}
+
+func TestSanitizeReportHeader(t *testing.T) {
+	// large document with many unsafe attributes keeps report header small
+	P := &Post{}
+	for i := 0; i < 500; i++ {
+		P.Content = append(P.Content, Paragraph{RichText: `<p onclick="` + strings.Repeat("<x>", 50) + `">x</p>`})
+	}
+	hdr := reportHeader(P.sanitize())
+	if len(hdr) > maxReportBytes {
+		t.Fatalf("report header should be capped, got %d bytes", len(hdr))
+	}
+	report := SanitizeReport{}
+	if err := json.Unmarshal([]byte(hdr), &report); err != nil || report.Count != 500 || len(report.Removed) == 0 || len(report.Removed) > maxReportItems {
+		t.Fatalf("unexpected report: %v %v", report, err)
+	}
+	if report.Removed[0].Paragraph != 0 || report.Removed[0].Attribute != "onclick" {
+		t.Fatalf("report should list first removed items, got %v", report.Removed[0])
+	}
+}
+
+func TestSanitizeStored(t *testing.T) {
+	viewer := uniq("s-viewer")
+	clt.AddLayout(viewer, &clt.Layout{})
+
+	// stored before sanitizing at uploading
+	data, err := json.Marshal(Post{Category: "post", Topic: "old", Content: []Paragraph{{RichText: `<p onclick="alert(1)">ok</p><script>alert(2)</script>`}}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	evt := em.NewEvent("", uniq("s-alice"), "Post", string(data), "")
+	if err := em.AddEvent(evt); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := presentEvent(evt, viewer, "http://127.0.0.1:3323"); err != nil {
+		t.Fatal(err)
+	}
+	P := &Post{}
+	if err := json.Unmarshal([]byte(evt.RawJSON), P); err != nil {
+		t.Fatal(err)
+	}
+	if P.Content[0].RichText != `<p>ok</p>` {
+		t.Fatalf("stored rich text should be served sanitized, got %q", P.Content[0].RichText)
+	}
+}
@@ -0,0 +1,460 @@
+package post
+
+import (
+	"encoding/json"
+	"html"
+	"math"
+	"regexp"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+	"unicode"
+
+	em "github.com/digisan/event-mgr"
+	lk "github.com/digisan/logkit"
+)
+
+// embedded in-memory inverted index over Post topic, keywords & paragraph text, with listing time of Post & share
+// events for paging. it is updated on upload, share, edit, delete & erase, and rebuilt from event store on startup.
+
+const (
+	fldTopic    = "topic"
+	fldKeywords = "keywords"
+	fldText     = "text"
+	paraGap     = 8 // position gap between paragraphs, a phrase cannot cross paragraphs
+)
+
+var (
+	// keyword-field boosting
+	mFldBoost = map[string]float64{
+		fldKeywords: 3.0,
+		fldTopic:    2.0,
+		fldText:     1.0,
+	}
+	rTag = regexp.MustCompile(`<[^>]*>`)
+	idx  = newIndex()
+)
+
+type token struct {
+	term    string
+	pos     int
+	primary bool // primary token is used for query. Han run uses bigram, single Han uses unigram
+}
+
+// English (and other alphabetic) text is split into lower-case words.
+// Chinese (Han) text is split into overlapped bigrams plus unigrams, e.g. "世界和" => "世界" "界和" & "世" "界" "和"
+func tokenize(s string, pos int) ([]token, int) {
+	var (
+		tokens = []token{}
+		word   = []rune{}
+		han    = []rune{}
+	)
+	flushWord := func() {
+		if len(word) > 0 {
+			tokens = append(tokens, token{strings.ToLower(string(word)), pos, true})
+			pos++
+			word = word[:0]
+		}
+	}
+	flushHan := func() {
+		switch n := len(han); {
+		case n == 1:
+			tokens = append(tokens, token{string(han), pos, true})
+		case n > 1:
+			for i := 0; i < n; i++ {
+				tokens = append(tokens, token{string(han[i]), pos + i, false})
+				if i < n-1 {
+					tokens = append(tokens, token{string(han[i : i+2]), pos + i, true})
+				}
+			}
+		}
+		pos += len(han)
+		han = han[:0]
+	}
+	for _, r := range s {
+		switch {
+		case unicode.Is(unicode.Han, r):
+			flushWord()
+			han = append(han, r)
+		case unicode.IsLetter(r) || unicode.IsDigit(r):
+			flushHan()
+			word = append(word, r)
+		default:
+			flushWord()
+			flushHan()
+		}
+	}
+	flushWord()
+	flushHan()
+	return tokens, pos
+}
+
+func plainText(richText string) string {
+	return html.UnescapeString(rTag.ReplaceAllString(richText, " "))
+}
+
+type docMeta struct {
+	owner    string
+	tm       time.Time
+	category string
+	topic    string
+	catID    string
+	tags     []string
+	terms    []string // for removing
+}
+
+func newDocMeta(event *em.Event, P *Post) *docMeta {
+	return &docMeta{
+		owner:    event.Owner,
+		tm:       event.Tm,
+		category: P.Category,
+		topic:    P.Topic,
+		catID:    P.CatID,
+		tags:     normTags(P.Keywords), // Post before taxonomy has no stored tags
+	}
+}
+
+// listed Post or share event, enough for ordering listings without fetching event
+type listing struct {
+	owner string
+	tm    time.Time
+	reply bool // comment of another Post, not in feed
+}
+
+type index struct {
+	sync.RWMutex
+	mTermDoc map[string]map[string]map[string][]int // term : doc id : field : positions
+	mDoc     map[string]*docMeta                    // doc id : meta
+	mCatDoc  map[string]map[string]struct{}         // category id : doc ids
+	mTagDoc  map[string]map[string]struct{}         // tag : doc ids
+	mListed  map[string]listing                     // Post & share id : owner & time
+}
+
+func newIndex() *index {
+	return &index{
+		mTermDoc: make(map[string]map[string]map[string][]int),
+		mDoc:     make(map[string]*docMeta),
+		mCatDoc:  make(map[string]map[string]struct{}),
+		mTagDoc:  make(map[string]map[string]struct{}),
+		mListed:  make(map[string]listing),
+	}
+}
+
+func link(m map[string]map[string]struct{}, key, id string) {
+	if len(key) == 0 {
+		return
+	}
+	if _, ok := m[key]; !ok {
+		m[key] = make(map[string]struct{})
+	}
+	m[key][id] = struct{}{}
+}
+
+func unlink(m map[string]map[string]struct{}, key, id string) {
+	if mDoc, ok := m[key]; ok {
+		delete(mDoc, id)
+		if len(mDoc) == 0 {
+			delete(m, key)
+		}
+	}
+}
+
+func (ix *index) remove(id string) {
+	meta, ok := ix.mDoc[id]
+	if !ok {
+		return
+	}
+	for _, term := range meta.terms {
+		if mDoc, ok := ix.mTermDoc[term]; ok {
+			delete(mDoc, id)
+			if len(mDoc) == 0 {
+				delete(ix.mTermDoc, term)
+			}
+		}
+	}
+	unlink(ix.mCatDoc, meta.catID, id)
+	for _, tag := range meta.tags {
+		unlink(ix.mTagDoc, tag, id)
+	}
+	delete(ix.mDoc, id)
+}
+
+func (ix *index) add(id string, meta *docMeta, P *Post) {
+	ix.remove(id)
+
+	mFldTokens := map[string][]token{}
+	mFldTokens[fldTopic], _ = tokenize(P.Topic, 0)
+	mFldTokens[fldKeywords], _ = tokenize(P.Keywords, 0)
+	pos := 0
+	for _, para := range P.Content {
+		var tokens []token
+		tokens, pos = tokenize(para.Text+" "+plainText(para.RichText), pos)
+		mFldTokens[fldText] = append(mFldTokens[fldText], tokens...)
+		pos += paraGap
+	}
+
+	for fld, tokens := range mFldTokens {
+		for _, tk := range tokens {
+			mDoc, ok := ix.mTermDoc[tk.term]
+			if !ok {
+				mDoc = make(map[string]map[string][]int)
+				ix.mTermDoc[tk.term] = mDoc
+			}
+			mFld, ok := mDoc[id]
+			if !ok {
+				mFld = make(map[string][]int)
+				mDoc[id] = mFld
+				meta.terms = append(meta.terms, tk.term)
+			}
+			mFld[fld] = append(mFld[fld], tk.pos)
+		}
+	}
+	link(ix.mCatDoc, meta.catID, id)
+	for _, tag := range meta.tags {
+		link(ix.mTagDoc, tag, id)
+	}
+	ix.mDoc[id] = meta
+}
+
+func (ix *index) idf(term string) float64 {
+	return math.Log(1.0 + float64(len(ix.mDoc))/float64(len(ix.mTermDoc[term])+1))
+}
+
+// occurrence count of a phrase in a field of a doc
+func (ix *index) phraseCount(id, fld string, phrase []token) int {
+	first, ok := ix.mTermDoc[phrase[0].term][id]
+	if !ok {
+		return 0
+	}
+	n := 0
+NEXT:
+	for _, p := range first[fld] {
+		for _, tk := range phrase[1:] {
+			positions := ix.mTermDoc[tk.term][id][fld]
+			want := p + tk.pos - phrase[0].pos
+			if i := sort.SearchInts(positions, want); i == len(positions) || positions[i] != want {
+				continue NEXT
+			}
+		}
+		n++
+	}
+	return n
+}
+
+func fieldScore(boost float64, n int) float64 {
+	if n == 0 {
+		return 0
+	}
+	return boost * (1.0 + math.Log(float64(n)))
+}
+
+type SearchOpt struct {
+	Owner string
+	From  time.Time // zero means no lower bound
+	To    time.Time // zero means no upper bound
+}
+
+type Hit struct {
+	ID       string    `json:"id"`
+	Score    float64   `json:"score"`
+	Owner    string    `json:"owner"`
+	Tm       time.Time `json:"tm"`
+	Category string    `json:"category"`
+	Topic    string    `json:"topic"`
+}
+
+// split query into bare terms & "quoted phrases"
+func parseQuery(q string) (terms []string, phrases [][]token) {
+	segs := strings.Split(q, `"`)
+	for i, seg := range segs {
+		tokens, _ := tokenize(seg, 0)
+		primaries := []token{}
+		for _, tk := range tokens {
+			if tk.primary {
+				primaries = append(primaries, tk)
+			}
+		}
+		if len(primaries) == 0 {
+			continue
+		}
+		// odd segment is inside quotes. if quote is not closed, last segment is treated as bare terms
+		if i%2 == 1 && i < len(segs)-1 && len(primaries) > 1 {
+			phrases = append(phrases, primaries)
+			continue
+		}
+		for _, tk := range primaries {
+			terms = append(terms, tk.term)
+		}
+	}
+	return
+}
+
+// ranked hits. every phrase must match; if no phrase, at least one term must match
+func (ix *index) search(q string, opt SearchOpt) []Hit {
+	ix.RLock()
+	defer ix.RUnlock()
+
+	terms, phrases := parseQuery(q)
+	if len(terms) == 0 && len(phrases) == 0 {
+		return []Hit{}
+	}
+
+	mScore := map[string]float64{}
+
+	if len(phrases) > 0 {
+		for id := range ix.mTermDoc[phrases[0][0].term] {
+			score := 0.0
+			for _, phrase := range phrases {
+				idf := 0.0
+				for _, tk := range phrase {
+					idf += ix.idf(tk.term)
+				}
+				matched := false
+				for fld, boost := range mFldBoost {
+					if n := ix.phraseCount(id, fld, phrase); n > 0 {
+						score += idf * fieldScore(boost, n)
+						matched = true
+					}
+				}
+				if !matched {
+					score = -1
+					break
+				}
+			}
+			if score > 0 {
+				mScore[id] = score
+			}
+		}
+	}
+
+	for _, term := range terms {
+		idf := ix.idf(term)
+		for id, mFld := range ix.mTermDoc[term] {
+			if _, ok := mScore[id]; !ok && len(phrases) > 0 {
+				continue
+			}
+			for fld, positions := range mFld {
+				mScore[id] += idf * fieldScore(mFldBoost[fld], len(positions))
+			}
+		}
+	}
+
+	hits := []Hit{}
+	for id, score := range mScore {
+		meta := ix.mDoc[id]
+		switch {
+		case len(opt.Owner) > 0 && meta.owner != opt.Owner:
+			continue
+		case !opt.From.IsZero() && meta.tm.Before(opt.From):
+			continue
+		case !opt.To.IsZero() && meta.tm.After(opt.To):
+			continue
+		}
+		hits = append(hits, Hit{
+			ID:       id,
+			Score:    math.Round(score*1000) / 1000,
+			Owner:    meta.owner,
+			Tm:       meta.tm,
+			Category: meta.category,
+			Topic:    meta.topic,
+		})
+	}
+	sort.SliceStable(hits, func(i, j int) bool {
+		switch {
+		case hits[i].Score != hits[j].Score:
+			return hits[i].Score > hits[j].Score
+		case !hits[i].Tm.Equal(hits[j].Tm):
+			return hits[i].Tm.After(hits[j].Tm)
+		default:
+			return hits[i].ID < hits[j].ID
+		}
+	})
+	return hits
+}
+
+// index or re-index a Post event, share event is only listed. other or hidden event is ignored
+func IndexEvent(event *em.Event) error {
+	if event == nil || (event.EvtType != "Post" && event.EvtType != shareType) || isHidden(event.ID) {
+		return nil
+	}
+	P := &Post{}
+	if event.EvtType == "Post" {
+		if err := json.Unmarshal([]byte(event.RawJSON), P); err != nil {
+			return err
+		}
+	}
+	idx.Lock()
+	defer idx.Unlock()
+	idx.mListed[event.ID] = listing{event.Owner, event.Tm, len(event.Flwee) > 0}
+	if event.EvtType == "Post" {
+		idx.add(event.ID, newDocMeta(event, P), P)
+	}
+	return nil
+}
+
+// remove events from index & listing
+func UnindexEvent(ids ...string) {
+	idx.Lock()
+	defer idx.Unlock()
+	for _, id := range ids {
+		idx.remove(id)
+		delete(idx.mListed, id)
+	}
+}
+
+func Search(q string, opt SearchOpt) []Hit {
+	return idx.search(q, opt)
+}
+
+// rebuild whole index from all living Post & share events, including comments.
+// index is locked during rebuilding, so updates meanwhile are not lost
+func RebuildIndex() error {
+	defer func(start time.Time) { lk.Log("search index rebuilt in %v", time.Since(start)) }(time.Now())
+
+	idx.Lock()
+	defer idx.Unlock()
+
+	fresh := newIndex()
+	idx.mTermDoc, idx.mDoc, idx.mCatDoc, idx.mTagDoc, idx.mListed = fresh.mTermDoc, fresh.mDoc, fresh.mCatDoc, fresh.mTagDoc, fresh.mListed
+
+	ids, err := em.FetchEvtIDs(nil)
+	if err != nil {
+		return err
+	}
+
+	done := map[string]struct{}{}
+	for len(ids) > 0 {
+		id := ids[0]
+		ids = ids[1:]
+		if _, ok := done[id]; ok {
+			continue
+		}
+		done[id] = struct{}{}
+
+		flwers, err := em.Followers(id)
+		if err != nil {
+			return err
+		}
+		ids = append(ids, flwers...)
+
+		event, err := em.FetchEvent(true, id)
+		if err != nil {
+			return err
+		}
+		if event == nil || (event.EvtType != "Post" && event.EvtType != shareType) || isHidden(id) {
+			continue
+		}
+		idx.mListed[id] = listing{event.Owner, event.Tm, len(event.Flwee) > 0}
+		if event.EvtType == shareType {
+			continue
+		}
+		P := &Post{}
+		if err := json.Unmarshal([]byte(event.RawJSON), P); err != nil {
+			lk.Warn("Unmarshal Post Error when indexing, event is %v", event)
+			continue
+		}
+		idx.add(id, newDocMeta(event, P), P)
+	}
+	return nil
+}
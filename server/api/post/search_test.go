@@ -0,0 +1,71 @@
+package post
+
+import (
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestTokenize(t *testing.T) {
+	tests := []struct {
+		text string
+		want []token
+	}{
+		{"Hello, World!", []token{{"hello", 0, true}, {"world", 1, true}}},
+		{"疫苗", []token{{"疫", 0, false}, {"疫苗", 0, true}, {"苗", 1, false}}},
+		{"新冠vaccine 好", []token{{"新", 0, false}, {"新冠", 0, true}, {"冠", 1, false}, {"vaccine", 2, true}, {"好", 3, true}}},
+	}
+	for _, tt := range tests {
+		if got, _ := tokenize(tt.text, 0); !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("tokenize(%q): want %v, got %v", tt.text, tt.want, got)
+		}
+	}
+}
+
+func TestSearch(t *testing.T) {
+	ix := newIndex()
+	day := time.Date(2023, 5, 1, 0, 0, 0, 0, time.Local)
+	add := func(id, owner string, tm time.Time, P *Post) {
+		ix.add(id, &docMeta{owner: owner, tm: tm, category: "post", topic: P.Topic}, P)
+	}
+	add("a", "alice", day, &Post{Topic: "vaccine side effect", Keywords: "", Content: []Paragraph{{Text: "some notes"}}})
+	add("b", "bob", day.AddDate(0, 0, 1), &Post{Topic: "notes", Keywords: "vaccine", Content: []Paragraph{{Text: "effect of side dishes"}}})
+	add("c", "alice", day.AddDate(0, 0, 2), &Post{Topic: "新冠疫苗副作用", Content: []Paragraph{{RichText: "<p>接种<b>疫苗</b>后的反应</p>"}}})
+	add("d", "carol", day.AddDate(0, 0, 3), &Post{Topic: "unrelated", Content: []Paragraph{{Text: "side"}, {Text: "effect"}}})
+
+	ids := func(hits []Hit) (ids []string) {
+		for _, h := range hits {
+			ids = append(ids, h.ID)
+		}
+		return
+	}
+
+	tests := []struct {
+		name string
+		q    string
+		opt  SearchOpt
+		want []string
+	}{
+		{"keyword field ranks higher", "vaccine", SearchOpt{}, []string{"b", "a"}},
+		{"phrase must be adjacent", `"side effect"`, SearchOpt{}, []string{"a"}},
+		{"phrase does not cross paragraphs", `"side effect"`, SearchOpt{Owner: "carol"}, nil},
+		{"chinese bigram", "疫苗", SearchOpt{}, []string{"c"}},
+		{"chinese phrase", `"疫苗副作用"`, SearchOpt{}, []string{"c"}},
+		{"chinese in rich text", "接种", SearchOpt{}, []string{"c"}},
+		{"owner filter", "vaccine 疫苗", SearchOpt{Owner: "alice"}, []string{"c", "a"}},
+		{"date filter", "vaccine", SearchOpt{From: day.AddDate(0, 0, 1)}, []string{"b"}},
+		{"no hit", "nothing", SearchOpt{}, nil},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := ids(ix.search(tt.q, tt.opt)); !reflect.DeepEqual(got, tt.want) {
+				t.Fatalf("search(%q): want %v, got %v", tt.q, tt.want, got)
+			}
+		})
+	}
+
+	ix.remove("b")
+	if got := ids(ix.search("vaccine", SearchOpt{})); !reflect.DeepEqual(got, []string{"a"}) {
+		t.Fatalf("after removing: want [a], got %v", got)
+	}
+}
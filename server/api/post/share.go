@@ -0,0 +1,159 @@
+package post
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/dgraph-io/badger/v3"
+	bh "github.com/digisan/db-helper/badger"
+	em "github.com/digisan/event-mgr"
+	lk "github.com/digisan/logkit"
+	"github.com/wismed-web/wisite-api/server/api/notify"
+)
+
+// share is a lightweight "Share" event in the same event stream, referencing an original Post.
+// it shows in feeds like a Post; if the original is deleted or hidden, a tombstone is presented instead
+
+const (
+	shareType       = "Share"
+	maxShareComment = 500 // in runes
+)
+
+var (
+	errShareOrigin  = errors.New("only an existing Post can be shared")
+	errShareComment = fmt.Errorf("share comment is at most %d characters", maxShareComment)
+)
+
+// RawJSON of a share event
+type Share struct {
+	Origin  string `json:"origin"`  // original Post id
+	Comment string `json:"comment"` // optional plain text from sharer
+}
+
+// key: original post id ^ share event id;
+// value: json of share record, for counting & listing shares of a Post
+type ShareRec struct {
+	Origin  string    `json:"origin"`
+	ShareID string    `json:"shareId"`
+	Sharer  string    `json:"sharer"`
+	Tm      time.Time `json:"tm"`
+}
+
+func (s ShareRec) String() string {
+	return fmt.Sprintf("%s shared as %s by %s @%v", s.Origin, s.ShareID, s.Sharer, s.Tm)
+}
+
+func (s *ShareRec) BadgerDB() *badger.DB {
+	return DbGrp.Share
+}
+
+func (s *ShareRec) Key() []byte {
+	return []byte(s.Origin + SEP + s.ShareID)
+}
+
+func (s *ShareRec) Marshal(at any) (forKey, forValue []byte) {
+	forKey = s.Key()
+	forValue, err := json.Marshal(s)
+	lk.FailOnErr("%v", err)
+	return
+}
+
+func (s *ShareRec) Unmarshal(dbKey, dbVal []byte) (any, error) {
+	if err := json.Unmarshal(dbVal, s); err != nil {
+		return nil, err
+	}
+	return s, nil
+}
+
+// [uname] shares Post [origin] with optional [comment]. sharing a share means sharing its original Post
+func sharePost(uname, origin, comment string) (*em.Event, error) {
+	comment = strings.TrimSpace(comment)
+	if len([]rune(comment)) > maxShareComment {
+		return nil, errShareComment
+	}
+
+	event, err := em.FetchEvent(true, origin)
+	if err != nil {
+		return nil, err
+	}
+	if event != nil && event.EvtType == shareType {
+		s := &Share{}
+		if err := json.Unmarshal([]byte(event.RawJSON), s); err != nil {
+			return nil, err
+		}
+		if event, err = em.FetchEvent(true, s.Origin); err != nil {
+			return nil, err
+		}
+	}
+	if event == nil || event.EvtType != "Post" || isHidden(event.ID) {
+		return nil, errShareOrigin
+	}
+
+	data, err := json.Marshal(&Share{Origin: event.ID, Comment: comment})
+	if err != nil {
+		return nil, err
+	}
+	evt := em.NewEvent("", uname, shareType, string(data), "")
+	if err := em.AddEvent(evt); err != nil {
+		return nil, err
+	}
+	lk.WarnOnErr("%v", IndexEvent(evt))
+	if err := bh.UpsertOneObject(&ShareRec{Origin: event.ID, ShareID: evt.ID, Sharer: uname, Tm: evt.Tm}); err != nil {
+		return nil, err
+	}
+	notifyUser(event.Owner, notify.Share, uname, event.ID, comment)
+	return evt, nil
+}
+
+// alive share event ids of Post [origin]
+func shareIDs(origin string) ([]string, error) {
+	recs, err := bh.GetObjects[ShareRec]([]byte(origin+SEP), nil)
+	if err != nil {
+		return nil, err
+	}
+	ids := []string{}
+	for _, rec := range recs {
+		ids = append(ids, rec.ShareID)
+	}
+	evts, err := em.FetchEvents(true, ids...)
+	if err != nil {
+		return nil, err
+	}
+	ids = ids[:0]
+	for _, evt := range evts {
+		ids = append(ids, evt.ID)
+	}
+	return ids, nil
+}
+
+// present share [event] for [uname] with its original Post, or tombstone if original is deleted or hidden
+func viewShare(event *em.Event, uname, base string) (*PostView, error) {
+	s := &Share{}
+	if err := json.Unmarshal([]byte(event.RawJSON), s); err != nil {
+		return nil, fmt.Errorf("convert RawJSON to [Share] Unmarshal error")
+	}
+	view := &PostView{Event: event}
+	origin, err := em.FetchEvent(false, s.Origin)
+	if err != nil {
+		return nil, err
+	}
+	if origin == nil || origin.Deleted {
+		view.Tombstone = "deleted"
+		return view, nil
+	}
+	hidden, err := hiddenFor(origin.ID, origin.Owner, uname)
+	if err != nil {
+		return nil, err
+	}
+	if hidden {
+		view.Tombstone = "hidden"
+		return view, nil
+	}
+	if view.Origin, err = viewPost(origin, uname, base); err != nil {
+		return nil, err
+	}
+	return view, nil
+}
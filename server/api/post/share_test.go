@@ -0,0 +1,100 @@
+package post
+
+import (
+	"net/http"
+	"testing"
+	"time"
+
+	em "github.com/digisan/event-mgr"
+	. "github.com/digisan/go-generics/v2"
+	r "github.com/digisan/user-mgr/relation"
+	clt "github.com/wismed-web/wisite-api/server/api/client"
+)
+
+func TestShare(t *testing.T) {
+	fnListRel = func(uname string, flag int) ([]string, error) {
+		if uname == "s-carol" && flag == r.FOLLOWING {
+			return []string{"s-bob"}, nil
+		}
+		return []string{}, nil
+	}
+	clt.AddLayout("s-carol", &clt.Layout{})
+
+	id := newTestPost(t, "s-alice")
+	if code := invokeID(SharePost, http.MethodPost, "s-bob", "nothing", ""); code != http.StatusNotFound {
+		t.Fatalf("sharing missing Post should be 404, got %d", code)
+	}
+	evt, err := sharePost("s-bob", id, "  worth reading  ")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if n := lastNotified("s-alice"); n == nil || n.Type != "share" || n.Actor != "s-bob" || n.Text != "worth reading" {
+		t.Fatalf("author should be notified, got %v", n)
+	}
+
+	// sharing a Share shares the original
+	again, err := sharePost("s-carol", evt.ID, "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if ids, err := shareIDs(id); err != nil || len(ids) != 2 || NotIn(again.ID, ids...) {
+		t.Fatalf("want 2 shares of original, got %v %v", ids, err)
+	}
+
+	// Share shows in follower feed
+	all, err := feedCursors("s-carol", false, time.Time{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	page, err := pageCursors(all, 10, "", "")
+	if err != nil || NotIn(evt.ID, page.IDs...) {
+		t.Fatalf("Share should be in follower feed, got %v %v", page, err)
+	}
+
+	view := func() *PostView {
+		event, err := em.FetchEvent(true, evt.ID)
+		if err != nil {
+			t.Fatal(err)
+		}
+		v, err := viewShare(event, "s-carol", "")
+		if err != nil {
+			t.Fatal(err)
+		}
+		return v
+	}
+	if v := view(); v.Tombstone != "" || v.Origin == nil || v.Origin.ID != id || v.Origin.Shares != 2 {
+		t.Fatalf("unexpected Share view: %+v", v)
+	}
+
+	// unshare
+	if _, err := em.DelEvent(again.ID); err != nil {
+		t.Fatal(err)
+	}
+	if ids, err := shareIDs(id); err != nil || len(ids) != 1 {
+		t.Fatalf("want 1 share after unsharing, got %v %v", ids, err)
+	}
+
+	// hidden & deleted original give tombstone
+	origin, err := em.FetchEvent(true, id)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := moderate(origin, "admin", "hidden", "spam"); err != nil {
+		t.Fatal(err)
+	}
+	if v := view(); v.Tombstone != "hidden" || v.Origin != nil {
+		t.Fatalf("want hidden tombstone, got %+v", v)
+	}
+	if _, err := sharePost("s-carol", id, ""); err != errShareOrigin {
+		t.Fatalf("hidden Post should not be shared, got %v", err)
+	}
+	if err := moderate(origin, "admin", "restored", ""); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := em.DelEvent(id); err != nil {
+		t.Fatal(err)
+	}
+	if v := view(); v.Tombstone != "deleted" || v.Origin != nil {
+		t.Fatalf("want deleted tombstone, got %+v", v)
+	}
+}
@@ -0,0 +1,225 @@
+package post
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"regexp"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/dgraph-io/badger/v3"
+	bh "github.com/digisan/db-helper/badger"
+	. "github.com/digisan/go-generics/v2"
+	lk "github.com/digisan/logkit"
+)
+
+// managed taxonomy: admin-defined categories & user tags normalized from Post Keywords.
+// Post listing by category or tag is served from search index, see [index]
+
+const maxTagLen = 32 // in runes
+
+var (
+	rKeywordSep = regexp.MustCompile(`[,;，；、|#\r\n\t]+`)
+	rSpaces     = regexp.MustCompile(`\s+`)
+	rCategoryID = regexp.MustCompile(`^[a-z0-9][a-z0-9-]{0,31}$`)
+
+	errCategoryMissing = errors.New("category is not existing")
+	errCategoryID      = errors.New("category id must be 1-32 lower-case letters, digits or '-', and start with letter or digit")
+)
+
+// normalize one tag, e.g. " Side  Effect " => "side-effect". empty if nothing left
+func normTag(s string) string {
+	s = strings.Trim(strings.ToLower(strings.TrimSpace(s)), "#-")
+	s = rSpaces.ReplaceAllString(s, "-")
+	if rs := []rune(s); len(rs) > maxTagLen {
+		s = strings.TrimRight(string(rs[:maxTagLen]), "-")
+	}
+	return s
+}
+
+// tags from free-form [keywords]. keywords are separated by comma, semicolon, '#' etc.;
+// if there is no such separator, by white space
+func normTags(keywords string) []string {
+	parts := rKeywordSep.Split(keywords, -1)
+	if len(parts) == 1 {
+		parts = strings.Fields(keywords)
+	}
+	tags := []string{}
+	for _, part := range parts {
+		if tag := normTag(part); len(tag) > 0 && NotIn(tag, tags...) {
+			tags = append(tags, tag)
+		}
+	}
+	return tags
+}
+
+// key: category id;
+// value: json of one admin-defined Post category
+type Category struct {
+	ID   string    `json:"id"` // e.g. "covid-19"
+	Name string    `json:"name"`
+	Desc string    `json:"desc"`
+	Tm   time.Time `json:"tm"`
+}
+
+func (cat Category) String() string {
+	return fmt.Sprintf("%s [%s] %s @%v", cat.ID, cat.Name, cat.Desc, cat.Tm)
+}
+
+func (cat *Category) BadgerDB() *badger.DB {
+	return DbGrp.Category
+}
+
+func (cat *Category) Key() []byte {
+	return []byte(cat.ID)
+}
+
+func (cat *Category) Marshal(at any) (forKey, forValue []byte) {
+	forKey = cat.Key()
+	forValue, err := json.Marshal(cat)
+	lk.FailOnErr("%v", err)
+	return
+}
+
+func (cat *Category) Unmarshal(dbKey, dbVal []byte) (any, error) {
+	if err := json.Unmarshal(dbVal, cat); err != nil {
+		return nil, err
+	}
+	return cat, nil
+}
+
+// all categories, ordered by id
+func Categories() ([]*Category, error) {
+	return bh.GetObjects[Category](nil, nil)
+}
+
+// nil if not found
+func FetchCategory(id string) (*Category, error) {
+	return bh.GetOneObject[Category]([]byte(id))
+}
+
+// create or update category [cat]
+func saveCategory(cat *Category) error {
+	if !rCategoryID.MatchString(cat.ID) {
+		return errCategoryID
+	}
+	if len(strings.TrimSpace(cat.Name)) == 0 {
+		cat.Name = cat.ID
+	}
+	cat.Tm = time.Now()
+	return bh.UpsertOneObject(cat)
+}
+
+// Posts already in this category keep its id, but they are not listed until it is created again
+func deleteCategory(id string) error {
+	n, err := bh.DeleteOneObject[Category]([]byte(id))
+	if err == nil && n == 0 {
+		return errCategoryMissing
+	}
+	return err
+}
+
+// normalize tags from Keywords, validate category. called when cleaning Post
+func (p *Post) classify() error {
+	p.Tags = normTags(p.Keywords)
+	if len(p.CatID) == 0 {
+		return nil
+	}
+	cat, err := FetchCategory(p.CatID)
+	if err != nil {
+		return err
+	}
+	if cat == nil {
+		return fmt.Errorf("category '%s' is not existing", p.CatID)
+	}
+	return nil
+}
+
+// tag or category with its indexed Post count
+type TaxonCount struct {
+	ID    string `json:"id"`
+	Count int    `json:"count"`
+}
+
+func sortCounts(counts []TaxonCount) []TaxonCount {
+	sort.Slice(counts, func(i, j int) bool {
+		if counts[i].Count != counts[j].Count {
+			return counts[i].Count > counts[j].Count
+		}
+		return counts[i].ID < counts[j].ID
+	})
+	return counts
+}
+
+func (ix *index) counts(m map[string]map[string]struct{}, prefix string) []TaxonCount {
+	ix.RLock()
+	defer ix.RUnlock()
+	counts := []TaxonCount{}
+	for id, mDoc := range m {
+		if strings.HasPrefix(id, prefix) {
+			counts = append(counts, TaxonCount{id, len(mDoc)})
+		}
+	}
+	return sortCounts(counts)
+}
+
+func (ix *index) docs(m map[string]map[string]struct{}, id string) []string {
+	ix.RLock()
+	defer ix.RUnlock()
+	ids := []string{}
+	for doc := range m[id] {
+		ids = append(ids, doc)
+	}
+	return ids
+}
+
+// all tags starting with [prefix], most used first
+func TagCounts(prefix string) []TaxonCount {
+	return idx.counts(idx.mTagDoc, normTag(prefix))
+}
+
+// Post count of each category id, including deleted category
+func CategoryCounts() map[string]int {
+	m := map[string]int{}
+	for _, c := range idx.counts(idx.mCatDoc, "") {
+		m[c.ID] = c.Count
+	}
+	return m
+}
+
+// indexed Post ids with [tag]
+func TaggedIDs(tag string) []string {
+	return idx.docs(idx.mTagDoc, normTag(tag))
+}
+
+// indexed Post ids in category [id]
+func CategoryIDs(id string) []string {
+	return idx.docs(idx.mCatDoc, id)
+}
+
+// most used [top] tags of Posts created within past [window], by in-memory index without scanning event spans
+func TrendingTags(window time.Duration, top int) []TaxonCount {
+	since := time.Now().Add(-window)
+
+	idx.RLock()
+	mCount := map[string]int{}
+	for _, meta := range idx.mDoc {
+		if !meta.tm.Before(since) {
+			for _, tag := range meta.tags {
+				mCount[tag]++
+			}
+		}
+	}
+	idx.RUnlock()
+
+	counts := []TaxonCount{}
+	for tag, n := range mCount {
+		counts = append(counts, TaxonCount{tag, n})
+	}
+	if counts = sortCounts(counts); len(counts) > top {
+		counts = counts[:top]
+	}
+	return counts
+}
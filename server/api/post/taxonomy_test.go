@@ -0,0 +1,128 @@
+package post
+
+import (
+	"encoding/json"
+	"net/http"
+	"reflect"
+	"sort"
+	"strings"
+	"testing"
+	"time"
+
+	em "github.com/digisan/event-mgr"
+	. "github.com/digisan/go-generics/v2"
+	"github.com/labstack/echo/v4"
+)
+
+func TestNormTags(t *testing.T) {
+	tests := []struct {
+		keywords string
+		want     []string
+	}{
+		{"", []string{}},
+		{"Covid, Side  Effect; #vaccine", []string{"covid", "side-effect", "vaccine"}},
+		{"covid vaccine Covid", []string{"covid", "vaccine"}},
+		{"疫苗，副作用、疫苗", []string{"疫苗", "副作用"}},
+		{" , ;# ", []string{}},
+	}
+	for _, tt := range tests {
+		if got := normTags(tt.keywords); !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("normTags(%q): want %v, got %v", tt.keywords, tt.want, got)
+		}
+	}
+}
+
+func newTaggedPost(t *testing.T, owner, catID, keywords string) string {
+	P := &Post{Topic: "taxonomy", Keywords: keywords, CatID: catID}
+	if err := P.clean(owner); err != nil {
+		t.Fatal(err)
+	}
+	data, err := json.Marshal(P)
+	if err != nil {
+		t.Fatal(err)
+	}
+	evt := em.NewEvent("", owner, "Post", string(data), "")
+	if err := em.AddEvent(evt); err != nil {
+		t.Fatal(err)
+	}
+	if err := IndexEvent(evt); err != nil {
+		t.Fatal(err)
+	}
+	return evt.ID
+}
+
+func TestTaxonomy(t *testing.T) {
+	tx := uniq("tx") // prefix of ids & tags in this run
+
+	if err := saveCategory(&Category{ID: "Bad ID"}); err != errCategoryID {
+		t.Fatalf("invalid category id should be refused, got %v", err)
+	}
+	if err := saveCategory(&Category{ID: tx + "-news", Name: "News"}); err != nil {
+		t.Fatal(err)
+	}
+	if err := (&Post{CatID: tx + "-missing"}).classify(); err == nil {
+		t.Fatal("missing category should be refused")
+	}
+
+	id1 := newTaggedPost(t, tx+"-alice", tx+"-news", tx+"-covid, "+tx+"-vaccine")
+	id2 := newTaggedPost(t, tx+"-bob", tx+"-news", tx+"-covid")
+	id3 := newTaggedPost(t, tx+"-bob", "", strings.ToUpper(tx)+"-Covid, "+tx+"-mask")
+
+	ids := CategoryIDs(tx + "-news")
+	sort.Strings(ids)
+	want := []string{id1, id2}
+	sort.Strings(want)
+	if !reflect.DeepEqual(ids, want) || CategoryCounts()[tx+"-news"] != 2 {
+		t.Fatalf("unexpected category ids: %v, counts: %v", ids, CategoryCounts())
+	}
+	if n := len(TaggedIDs(strings.ToUpper(tx) + "-Covid")); n != 3 {
+		t.Fatalf("want 3 Posts tagged 'tx-covid', got %d", n)
+	}
+	if counts := TagCounts(tx + "-"); len(counts) != 3 || counts[0] != (TaxonCount{tx + "-covid", 3}) {
+		t.Fatalf("unexpected tag counts: %v", counts)
+	}
+
+	// Post created before window is not trending
+	old := em.NewEvent("", tx+"-carol", "Post", `{"keywords":"`+tx+`-old"}`, "")
+	old.Tm = time.Now().Add(-2 * time.Hour)
+	if err := IndexEvent(old); err != nil {
+		t.Fatal(err)
+	}
+	defer UnindexEvent(old.ID)
+
+	if trending := TrendingTags(time.Hour, 2); len(trending) != 2 {
+		t.Fatalf("want top 2 trending tags, got %v", trending)
+	}
+	trending := TrendingTags(time.Hour, 100)
+	trending = Filter(trending, func(i int, tc TaxonCount) bool { return strings.HasPrefix(tc.ID, tx+"-") }) // tags of this run
+	if len(trending) != 3 || trending[0] != (TaxonCount{tx + "-covid", 3}) || len(TaggedIDs(tx+"-old")) != 1 {
+		t.Fatalf("unexpected trending tags: %v", trending)
+	}
+
+	// deleted Post leaves listing & counts
+	if _, err := em.DelEvent(id3); err != nil {
+		t.Fatal(err)
+	}
+	UnindexEvent(id3)
+	if counts := TagCounts(tx + "-m"); len(counts) != 0 {
+		t.Fatalf("tag of deleted Post should be gone, got %v", counts)
+	}
+
+	// listing is paged
+	rec := invoke(func(c echo.Context) error {
+		c.SetParamNames("tag")
+		c.SetParamValues(tx + "-covid")
+		return TagPosts(c)
+	}, http.MethodGet, tx+"-viewer", "limit=1")
+	page := &IdPage{}
+	if err := json.Unmarshal(rec.Body.Bytes(), page); err != nil || rec.Code != http.StatusOK || len(page.IDs) != 1 || len(page.NextCursor) == 0 {
+		t.Fatalf("unexpected tag page: %d %s", rec.Code, rec.Body.String())
+	}
+
+	if err := deleteCategory(tx + "-news"); err != nil {
+		t.Fatal(err)
+	}
+	if err := deleteCategory(tx + "-news"); err != errCategoryMissing {
+		t.Fatalf("deleted category should be missing, got %v", err)
+	}
+}
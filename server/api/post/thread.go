@@ -0,0 +1,114 @@
+package post
+
+import (
+	"fmt"
+	"sort"
+
+	em "github.com/digisan/event-mgr"
+)
+
+const (
+	defaultThreadDepth = 5
+	maxThreadDepth     = 20
+)
+
+// one Post in reply tree. deleted reply is not in tree, as its replies cannot be fetched from event store
+type ThreadNode struct {
+	*em.Event
+	Reactions  int           `json:"reactions"`  // count of all reaction types
+	ChildCount int           `json:"childCount"` // alive direct replies count, even if they are beyond depth limit
+	Children   []*ThreadNode `json:"children"`
+}
+
+type threadOpt struct {
+	depth int    // reply levels under root
+	order string // oldest, newest, liked (most reactions)
+	uname string // viewer
+	base  string // media src base url
+}
+
+// build reply tree of [id]. return nil if root Post is missing, deleted or hidden from viewer
+func buildThread(id string, opt threadOpt) (*ThreadNode, error) {
+	event, err := em.FetchEvent(true, id)
+	if err != nil || event == nil {
+		return nil, err
+	}
+	if hidden, err := hiddenFor(event.ID, event.Owner, opt.uname); err != nil || hidden {
+		return nil, err
+	}
+	if event.EvtType != "Post" {
+		return nil, fmt.Errorf("<%s> is not a Post", id)
+	}
+	return threadNode(event, 0, opt, map[string]struct{}{})
+}
+
+func threadNode(event *em.Event, level int, opt threadOpt, visited map[string]struct{}) (*ThreadNode, error) {
+	visited[event.ID] = struct{}{}
+
+	node := &ThreadNode{Event: event, Children: []*ThreadNode{}}
+	if len(event.RawJSON) > 0 {
+		if err := presentEvent(event, opt.uname, opt.base); err != nil {
+			return nil, err
+		}
+	}
+
+	var err error
+	if node.Reactions, err = reactionCount(event.ID); err != nil {
+		return nil, err
+	}
+
+	flwers, err := em.Followers(event.ID)
+	if err != nil {
+		return nil, err
+	}
+	children := []*em.Event{}
+	for _, id := range flwers {
+		if _, ok := visited[id]; ok || len(id) == 0 {
+			continue
+		}
+		child, err := em.FetchEvent(true, id)
+		if err != nil {
+			return nil, err
+		}
+		if child == nil {
+			continue
+		}
+		hidden, err := hiddenFor(child.ID, child.Owner, opt.uname)
+		if err != nil {
+			return nil, err
+		}
+		if !hidden {
+			children = append(children, child)
+		}
+	}
+	node.ChildCount = len(children)
+
+	if level >= opt.depth {
+		return node, nil
+	}
+	for _, child := range children {
+		childNode, err := threadNode(child, level+1, opt, visited)
+		if err != nil {
+			return nil, err
+		}
+		node.Children = append(node.Children, childNode)
+	}
+	sortThread(node.Children, opt.order)
+	return node, nil
+}
+
+func sortThread(nodes []*ThreadNode, order string) {
+	sort.SliceStable(nodes, func(i, j int) bool {
+		left, right := nodes[i], nodes[j]
+		switch {
+		case order == "liked" && left.Reactions != right.Reactions:
+			return left.Reactions > right.Reactions
+		case order == "newest" && !left.Tm.Equal(right.Tm):
+			return left.Tm.After(right.Tm)
+		case !left.Tm.Equal(right.Tm):
+			return left.Tm.Before(right.Tm)
+		default:
+			return left.ID < right.ID
+		}
+	})
+}
@@ -0,0 +1,74 @@
+package post
+
+import (
+	"testing"
+
+	em "github.com/digisan/event-mgr"
+	clt "github.com/wismed-web/wisite-api/server/api/client"
+)
+
+func newTestReply(t *testing.T, owner, flwee string) string {
+	evt := em.NewEvent("", owner, "Post", `{"category":"comment","topic":"re"}`, flwee)
+	if err := em.AddEvent(evt); err != nil {
+		t.Fatal(err)
+	}
+	if err := IndexEvent(evt); err != nil {
+		t.Fatal(err)
+	}
+	ef, err := em.NewEventFollow(flwee, true)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := ef.AddFollower(evt.ID); err != nil {
+		t.Fatal(err)
+	}
+	return evt.ID
+}
+
+func TestThread(t *testing.T) {
+	clt.AddLayout("t-viewer", &clt.Layout{})
+
+	root := newTestPost(t, "t-alice")
+	r1 := newTestReply(t, "t-bob", root)
+	r2 := newTestReply(t, "t-carol", root)
+	r3 := newTestReply(t, "t-carol", root)
+	r11 := newTestReply(t, "t-alice", r1)
+	newTestReply(t, "t-bob", r11)
+
+	// all reaction types count for 'liked', not only thumbs-up
+	for _, rc := range []struct{ id, typ, uname string }{
+		{r1, "Insightful", "t-alice"},
+		{r1, "Agree", "t-carol"},
+		{r2, "ThumbsUp", "t-alice"},
+	} {
+		ep, err := em.NewEventParticipate(rc.id, true)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if err := ep.AddPtps(rc.typ, rc.uname); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if _, err := em.DelEvent(r3); err != nil {
+		t.Fatal(err)
+	}
+
+	tree, err := buildThread(root, threadOpt{depth: 2, order: "liked", uname: "t-viewer"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if tree.ChildCount != 2 || len(tree.Children) != 2 {
+		t.Fatalf("deleted reply should not be in tree: %+v", tree)
+	}
+	liked := tree.Children[0]
+	if liked.ID != r1 || liked.Reactions != 2 || len(liked.Children) != 1 {
+		t.Fatalf("most liked reply should be first: %+v", liked)
+	}
+	if deepest := liked.Children[0]; deepest.ChildCount != 1 || len(deepest.Children) != 0 {
+		t.Fatalf("depth limit should keep child count only: %+v", deepest)
+	}
+
+	if tree, err := buildThread("missing-id", threadOpt{}); err != nil || tree != nil {
+		t.Fatalf("missing root: want nil, got %v, %v", tree, err)
+	}
+}
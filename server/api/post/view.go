@@ -0,0 +1,56 @@
+package post
+
+import (
+	"encoding/json"
+	"fmt"
+	"time"
+
+	em "github.com/digisan/event-mgr"
+	lk "github.com/digisan/logkit"
+)
+
+// Post event with its content presented for viewing
+type PostView struct {
+	*em.Event
+	Edited bool      `json:"edited"`
+	EditTm time.Time `json:"editTm"`
+	Shares int       `json:"shares"`
+
+	// only for Share event, original Post, or tombstone ("deleted" / "hidden") if it is unavailable
+	Origin    *PostView `json:"origin,omitempty"`
+	Tombstone string    `json:"tombstone,omitempty"`
+}
+
+// replace Post [event] RawJSON with its content presented for [uname], media src is under [base] url
+func presentEvent(event *em.Event, uname, base string) error {
+	P := &Post{}
+	if err := json.Unmarshal([]byte(event.RawJSON), P); err != nil {
+		lk.Warn("Unmarshal Post Error, event is %v", event)
+		return fmt.Errorf("convert RawJSON to [Post] Unmarshal error")
+	}
+	if err := P.present(event.Owner, uname, base); err != nil {
+		return err
+	}
+	data, err := json.Marshal(P)
+	if err != nil {
+		return err
+	}
+	event.RawJSON = string(data)
+	return nil
+}
+
+// present Post [event] for [uname] with its last edit status
+func viewPost(event *em.Event, uname, base string) (*PostView, error) {
+	if err := presentEvent(event, uname, base); err != nil {
+		return nil, err
+	}
+	edited, editTm, err := LastEdit(event.ID)
+	if err != nil {
+		return nil, err
+	}
+	ids, err := shareIDs(event.ID)
+	if err != nil {
+		return nil, err
+	}
+	return &PostView{Event: event, Edited: edited, EditTm: editTm, Shares: len(ids)}, nil
+}
@@ -9,6 +9,7 @@ import (
 	u "github.com/digisan/user-mgr/user"
 	"github.com/golang-jwt/jwt/v4"
 	"github.com/labstack/echo/v4"
+	"github.com/wismed-web/wisite-api/server/api/notify"
 )
 
 // *** after implementing, register with path in 'rel.go' *** //
@@ -58,6 +59,10 @@ func Action(c echo.Context) error {
 	if err := r.RelAction(uname, flag, whom); err != nil {
 		return c.String(http.StatusInternalServerError, err.Error())
 	}
+	if flag == r.FOLLOW {
+		_, err := notify.Push(whom, notify.Follow, uname, uname, "")
+		lk.WarnOnErr("%v", err)
+	}
 	return c.JSON(http.StatusOK, fmt.Sprintf("%s %s successfully now", action, whom))
 }
 
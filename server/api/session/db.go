@@ -0,0 +1,53 @@
+package session
+
+import (
+	"path/filepath"
+	"sync"
+
+	"github.com/dgraph-io/badger/v3"
+	. "github.com/digisan/go-generics/v2"
+	lk "github.com/digisan/logkit"
+)
+
+type DBGrp struct {
+	sync.Mutex
+	Session *badger.DB // session id : login session
+}
+
+var (
+	onceDB sync.Once // do once
+	DbGrp  *DBGrp    // global, for keeping single instance
+)
+
+func open(dir string) *badger.DB {
+	opt := badger.DefaultOptions("").WithInMemory(true)
+	if dir != "" {
+		opt = badger.DefaultOptions(dir)
+		opt.Logger = nil
+	}
+	db, err := badger.Open(opt)
+	lk.FailOnErr("%v", err)
+	return db
+}
+
+// init global 'DbGrp'. if [dir] is empty, use in-memory db
+func InitDB(dir string) *DBGrp {
+	if DbGrp == nil {
+		onceDB.Do(func() {
+			DbGrp = &DBGrp{
+				Session: open(IF(dir == "", "", filepath.Join(dir, "session"))),
+			}
+		})
+	}
+	return DbGrp
+}
+
+func CloseDB() {
+	DbGrp.Lock()
+	defer DbGrp.Unlock()
+
+	if DbGrp.Session != nil {
+		lk.FailOnErr("%v", DbGrp.Session.Close())
+		DbGrp.Session = nil
+	}
+}
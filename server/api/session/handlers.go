@@ -0,0 +1,157 @@
+package session
+
+import (
+	"fmt"
+	"net/http"
+
+	u "github.com/digisan/user-mgr/user"
+	"github.com/golang-jwt/jwt/v4"
+	"github.com/labstack/echo/v4"
+	"github.com/wismed-web/wisite-api/server/api/admin"
+)
+
+// *** after implementing, register with path in 'session.go' or 'admin.go' *** //
+
+// @Title list my sessions
+// @Summary list own alive login sessions with device, ip and last seen, latest first. the caller's one is marked 'current'.
+// @Description
+// @Tags    Session
+// @Accept  json
+// @Produce json
+// @Success 200 "OK - list successfully"
+// @Failure 500 "Fail - internal error"
+// @Router /api/session/list [get]
+// @Security ApiKeyAuth
+func MyList(c echo.Context) error {
+	var (
+		userTkn = c.Get("user").(*jwt.Token)
+		claims  = userTkn.Claims.(*u.UserClaims)
+		uname   = claims.UName
+	)
+	ss, err := List(uname, claims.ID)
+	if err != nil {
+		return c.String(http.StatusInternalServerError, err.Error())
+	}
+	return c.JSON(http.StatusOK, ss)
+}
+
+// @Title revoke one of my sessions
+// @Summary revoke one own session, its access & refresh token become invalid at once. revoking current one signs out this device.
+// @Description
+// @Tags    Session
+// @Accept  json
+// @Produce json
+// @Param   id path string true "session id"
+// @Success 200 "OK - revoke successfully"
+// @Failure 404 "Fail - session not found"
+// @Failure 500 "Fail - internal error"
+// @Router /api/session/revoke/{id} [delete]
+// @Security ApiKeyAuth
+func MyRevoke(c echo.Context) error {
+	var (
+		userTkn = c.Get("user").(*jwt.Token)
+		claims  = userTkn.Claims.(*u.UserClaims)
+		uname   = claims.UName
+		id      = c.Param("id")
+	)
+	switch err := Revoke(uname, id); {
+	case err == errSessionMissing:
+		return c.String(http.StatusNotFound, fmt.Sprintf("session not found @%s", id))
+	case err != nil:
+		return c.String(http.StatusInternalServerError, err.Error())
+	}
+	return c.JSON(http.StatusOK, fmt.Sprintf("session revoked @%s", id))
+}
+
+// @Title revoke my other sessions
+// @Summary revoke all own sessions except the caller's one.
+// @Description
+// @Tags    Session
+// @Accept  json
+// @Produce json
+// @Success 200 "OK - revoke successfully, return count of revoked"
+// @Failure 500 "Fail - internal error"
+// @Router /api/session/others [delete]
+// @Security ApiKeyAuth
+func MyRevokeOthers(c echo.Context) error {
+	var (
+		userTkn = c.Get("user").(*jwt.Token)
+		claims  = userTkn.Claims.(*u.UserClaims)
+		uname   = claims.UName
+	)
+	n, err := RevokeAll(uname, claims.ID)
+	if err != nil {
+		return c.String(http.StatusInternalServerError, err.Error())
+	}
+	return c.JSON(http.StatusOK, n)
+}
+
+// @Title list user sessions
+// @Summary list alive login sessions of one user, latest first.
+// @Description
+// @Tags    Admin
+// @Accept  json
+// @Produce json
+// @Param   uname query string true "user name"
+// @Success 200 "OK - list successfully"
+// @Failure 400 "Fail - missing uname"
+// @Failure 401 "Fail - unauthorized error"
+// @Failure 500 "Fail - internal error"
+// @Router /api/admin/sessions [get]
+// @Security ApiKeyAuth
+func UserList(c echo.Context) error {
+	if ok, err := admin.Only(c); !ok {
+		return err
+	}
+	uname := c.QueryParam("uname")
+	if len(uname) == 0 {
+		return c.String(http.StatusBadRequest, "'uname' is required")
+	}
+	ss, err := List(uname, "")
+	if err != nil {
+		return c.String(http.StatusInternalServerError, err.Error())
+	}
+	return c.JSON(http.StatusOK, ss)
+}
+
+// @Title revoke user sessions
+// @Summary revoke one session of a user, or all of the user's sessions if id is empty.
+// @Description
+// @Tags    Admin
+// @Accept  json
+// @Produce json
+// @Param   uname query string true  "user name"
+// @Param   id    query string false "session id, empty for all sessions of the user"
+// @Success 200 "OK - revoke successfully, return count of revoked"
+// @Failure 400 "Fail - missing uname"
+// @Failure 401 "Fail - unauthorized error"
+// @Failure 404 "Fail - session not found"
+// @Failure 500 "Fail - internal error"
+// @Router /api/admin/sessions [delete]
+// @Security ApiKeyAuth
+func UserRevoke(c echo.Context) error {
+	if ok, err := admin.Only(c); !ok {
+		return err
+	}
+	var (
+		uname = c.QueryParam("uname")
+		id    = c.QueryParam("id")
+	)
+	if len(uname) == 0 {
+		return c.String(http.StatusBadRequest, "'uname' is required")
+	}
+	if len(id) > 0 {
+		switch err := Revoke(uname, id); {
+		case err == errSessionMissing:
+			return c.String(http.StatusNotFound, fmt.Sprintf("session not found @%s", id))
+		case err != nil:
+			return c.String(http.StatusInternalServerError, err.Error())
+		}
+		return c.JSON(http.StatusOK, 1)
+	}
+	n, err := RevokeAll(uname, "")
+	if err != nil {
+		return c.String(http.StatusInternalServerError, err.Error())
+	}
+	return c.JSON(http.StatusOK, n)
+}
@@ -0,0 +1,9 @@
+package session
+
+import "time"
+
+// open session db in [dir] and purge expired sessions hourly. if [dir] is empty, use in-memory db
+func Init(dir string) {
+	InitDB(dir)
+	go purgeExpired(time.Hour)
+}
@@ -0,0 +1,278 @@
+package session
+
+import (
+	"crypto/rand"
+	"crypto/sha256"
+	"crypto/subtle"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/dgraph-io/badger/v3"
+	bh "github.com/digisan/db-helper/badger"
+	. "github.com/digisan/go-generics/v2"
+	lk "github.com/digisan/logkit"
+	u "github.com/digisan/user-mgr/user"
+	"github.com/golang-jwt/jwt/v4"
+	"github.com/google/uuid"
+)
+
+// login session of one device. access token is a short-lived jwt carrying session id, so revoking
+// session invalidates its access token at once. refresh token is "uname^session id.secret", rotated on
+// each refresh; presenting a rotated-out secret means token is stolen & reused, then session is revoked.
+// any other unknown secret is only rejected
+
+const SEP = "^"
+
+var (
+	AccessTTL  = 15 * time.Minute
+	RefreshTTL = 30 * 24 * time.Hour
+
+	seenInterval = time.Minute // min interval of persisting last-seen
+	nRotated     = 20          // max count of rotated-out secrets kept for reuse detection
+
+	timeNow = time.Now // variable for replacing in test
+
+	ErrRefreshInvalid = errors.New("invalid or expired refresh token")
+	ErrRefreshReused  = errors.New("refresh token reuse detected, session revoked")
+	errSessionMissing = errors.New("session is not existing")
+
+	mtxSession sync.Map // session key : *sync.Mutex, serializes read-modify-write of one session, e.g. checking & rotating secret
+)
+
+// lock session of [key], return unlock
+func lockSession(key string) func() {
+	mtx, _ := mtxSession.LoadOrStore(key, &sync.Mutex{})
+	mtx.(*sync.Mutex).Lock()
+	return mtx.(*sync.Mutex).Unlock
+}
+
+// key: uname^session id;
+// value: json of one session
+type Session struct {
+	ID       string    `json:"id"`
+	Uname    string    `json:"uname"`
+	Device   string    `json:"device"` // user agent
+	IP       string    `json:"ip"`
+	Created  time.Time `json:"created"`
+	LastSeen time.Time `json:"lastSeen"`
+	Expire   time.Time `json:"expire"`            // refresh token expiry
+	Secret   string    `json:"secret,omitempty"`  // sha256 of current refresh secret, never replied
+	Rotated  []string  `json:"rotated,omitempty"` // sha256 of latest rotated-out secrets, never replied
+	Current  bool      `json:"current,omitempty"` // only in reply, session of caller
+}
+
+func (s Session) String() string {
+	return fmt.Sprintf("%s of %s on [%s] from %s, created: %v, last seen: %v, expire: %v", s.ID, s.Uname, s.Device, s.IP, s.Created, s.LastSeen, s.Expire)
+}
+
+func (s *Session) BadgerDB() *badger.DB {
+	return DbGrp.Session
+}
+
+func (s *Session) Key() []byte {
+	return []byte(s.Uname + SEP + s.ID)
+}
+
+func (s *Session) Marshal(at any) (forKey, forValue []byte) {
+	forKey = s.Key()
+	forValue, err := json.Marshal(s)
+	lk.FailOnErr("%v", err)
+	return
+}
+
+func (s *Session) Unmarshal(dbKey, dbVal []byte) (any, error) {
+	if err := json.Unmarshal(dbVal, s); err != nil {
+		return nil, err
+	}
+	return s, nil
+}
+
+func hash(secret string) string {
+	sum := sha256.Sum256([]byte(secret))
+	return hex.EncodeToString(sum[:])
+}
+
+func newSecret() string {
+	b := make([]byte, 32)
+	_, err := rand.Read(b)
+	lk.FailOnErr("%v", err)
+	return hex.EncodeToString(b)
+}
+
+// signed access jwt of [user] for session [sid]
+func accessToken(user *u.User, sid string, now time.Time) string {
+	claims := u.MakeClaims(user)
+	claims.Password = "" // token content is readable by its holder, never carry password or verifier
+	claims.ID = sid
+	claims.IssuedAt = jwt.NewNumericDate(now)
+	claims.ExpiresAt = jwt.NewNumericDate(now.Add(AccessTTL))
+	ts, err := jwt.NewWithClaims(jwt.SigningMethodHS256, claims).SignedString([]byte(u.TokenKey()))
+	lk.FailOnErr("%v", err)
+	return ts
+}
+
+// rotate refresh secret of [s], save it and return new token pair
+func (s *Session) rotate(user *u.User, now time.Time) (access, refresh string, err error) {
+	secret := newSecret()
+	if len(s.Secret) > 0 {
+		if s.Rotated = append(s.Rotated, s.Secret); len(s.Rotated) > nRotated {
+			s.Rotated = s.Rotated[len(s.Rotated)-nRotated:]
+		}
+	}
+	s.Secret = hash(secret)
+	s.LastSeen = now
+	s.Expire = now.Add(RefreshTTL)
+	if err := bh.UpsertOneObject(s); err != nil {
+		return "", "", err
+	}
+	return accessToken(user, s.ID, now), string(s.Key()) + "." + secret, nil
+}
+
+// start a new session of logged-in [user] on [device] from [ip]. return access & refresh token
+func Start(user *u.User, device, ip string) (access, refresh string, err error) {
+	now := timeNow()
+	s := &Session{
+		ID:      uuid.NewString(),
+		Uname:   user.UName,
+		Device:  device,
+		IP:      ip,
+		Created: now,
+	}
+	return s.rotate(user, now)
+}
+
+// exchange [refresh] token for new token pair, rotating refresh token. [ip] is recorded as last seen ip
+func Refresh(refresh, ip string) (uname, access, newRefresh string, err error) {
+	i := strings.LastIndex(refresh, ".")
+	if i < 0 || i == len(refresh)-1 || !strings.Contains(refresh[:i], SEP) {
+		return "", "", "", ErrRefreshInvalid
+	}
+	key, secret := refresh[:i], refresh[i+1:]
+
+	// one refresh of a session at a time, so a racing copy of the same token is seen as reused
+	defer lockSession(key)()
+
+	s, err := bh.GetOneObject[Session]([]byte(key))
+	if err != nil {
+		return "", "", "", err
+	}
+	if s == nil {
+		return "", "", "", ErrRefreshInvalid
+	}
+	if h := hash(secret); subtle.ConstantTimeCompare([]byte(h), []byte(s.Secret)) != 1 {
+		if !In(h, s.Rotated...) {
+			return "", "", "", ErrRefreshInvalid
+		}
+		lk.Warn("refresh token reuse on session %s of [%s], revoked", s.ID, s.Uname)
+		if _, err := bh.DeleteOneObject[Session](s.Key()); err != nil {
+			return "", "", "", err
+		}
+		return "", "", "", ErrRefreshReused
+	}
+	now := timeNow()
+	if now.After(s.Expire) {
+		_, err := bh.DeleteOneObject[Session](s.Key())
+		return "", "", "", IF(err != nil, err, ErrRefreshInvalid)
+	}
+	user, ok, err := u.LoadActiveUser(s.Uname)
+	if err != nil {
+		return "", "", "", err
+	}
+	if !ok {
+		return "", "", "", ErrRefreshInvalid
+	}
+	s.IP = ip
+	access, newRefresh, err = s.rotate(user, now)
+	return s.Uname, access, newRefresh, err
+}
+
+// access token [claims] belongs to an alive session of its user. last seen is refreshed
+func Alive(claims *u.UserClaims) bool {
+	if claims == nil || len(claims.ID) == 0 {
+		return false
+	}
+	s, err := bh.GetOneObject[Session]([]byte(claims.UName + SEP + claims.ID))
+	if err != nil || s == nil {
+		return false
+	}
+	now := timeNow()
+	if now.After(s.Expire) {
+		return false
+	}
+	if now.Sub(s.LastSeen) > seenInterval {
+		// read again under lock, not to overwrite a rotated secret with this stale copy
+		defer lockSession(string(s.Key()))()
+		if s, err = bh.GetOneObject[Session](s.Key()); err != nil || s == nil {
+			return false
+		}
+		s.LastSeen = now
+		lk.WarnOnErr("%v", bh.UpsertOneObject(s))
+	}
+	return true
+}
+
+// alive sessions of [uname], latest seen first. [current] session is marked
+func List(uname, current string) ([]*Session, error) {
+	now := timeNow()
+	ss, err := bh.GetObjects[Session]([]byte(uname+SEP), func(s *Session) bool { return now.Before(s.Expire) })
+	if err != nil {
+		return nil, err
+	}
+	for _, s := range ss {
+		s.Secret, s.Rotated = "", nil
+		s.Current = s.ID == current
+	}
+	sort.SliceStable(ss, func(i, j int) bool { return ss[i].LastSeen.After(ss[j].LastSeen) })
+	return ss, nil
+}
+
+// delete sessions whose refresh token expired, every [interval]
+func purgeExpired(interval time.Duration) {
+	for range time.Tick(interval) {
+		now := timeNow()
+		ss, err := bh.GetObjects[Session](nil, func(s *Session) bool { return now.After(s.Expire) })
+		lk.WarnOnErr("%v", err)
+		for _, s := range ss {
+			_, err := bh.DeleteOneObject[Session](s.Key())
+			lk.WarnOnErr("%v", err)
+		}
+	}
+}
+
+// revoke session [sid] of [uname]
+func Revoke(uname, sid string) error {
+	defer lockSession(uname + SEP + sid)()
+
+	s, err := bh.GetOneObject[Session]([]byte(uname + SEP + sid))
+	if err != nil {
+		return err
+	}
+	if s == nil {
+		return errSessionMissing
+	}
+	_, err = bh.DeleteOneObject[Session](s.Key())
+	return err
+}
+
+// revoke all sessions of [uname] except [keep] one. return count of revoked
+func RevokeAll(uname, keep string) (int, error) {
+	ss, err := bh.GetObjects[Session]([]byte(uname+SEP), func(s *Session) bool { return s.ID != keep })
+	if err != nil {
+		return 0, err
+	}
+	for _, s := range ss {
+		unlock := lockSession(string(s.Key()))
+		_, err := bh.DeleteOneObject[Session](s.Key())
+		unlock()
+		if err != nil {
+			return 0, err
+		}
+	}
+	return len(ss), nil
+}
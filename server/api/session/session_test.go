@@ -0,0 +1,206 @@
+package session
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	u "github.com/digisan/user-mgr/user"
+	"github.com/golang-jwt/jwt/v4"
+	"github.com/labstack/echo/v4"
+	"github.com/wismed-web/wisite-api/server/api/admin"
+)
+
+func TestMain(m *testing.M) {
+	dir, err := os.MkdirTemp("", "session-test")
+	if err != nil {
+		panic(err)
+	}
+	u.InitDB(filepath.Join(dir, "db-user"))
+	InitDB("")
+	admin.IsAdmin = func(uname string) (bool, error) {
+		return uname == "admin", nil
+	}
+	code := m.Run()
+	os.RemoveAll(dir)
+	os.Exit(code)
+}
+
+func newUser(t *testing.T, uname string) *u.User {
+	user := &u.User{Core: u.Core{UName: uname, Email: uname + "@fake.net"}, Admin: u.Admin{Active: true}}
+	if err := u.UpdateUser(user); err != nil {
+		t.Fatal(err)
+	}
+	return user
+}
+
+func parse(t *testing.T, access string) *u.UserClaims {
+	claims := &u.UserClaims{}
+	tkn, err := jwt.ParseWithClaims(access, claims, func(t *jwt.Token) (any, error) { return []byte(u.TokenKey()), nil })
+	if err != nil || !tkn.Valid {
+		t.Fatalf("invalid access token: %v", err)
+	}
+	return claims
+}
+
+func invoke(handler echo.HandlerFunc, method, uname, sid, query string) *httptest.ResponseRecorder {
+	rec := httptest.NewRecorder()
+	c := echo.New().NewContext(httptest.NewRequest(method, "/?"+query, nil), rec)
+	c.Set("user", &jwt.Token{Claims: &u.UserClaims{Core: u.Core{UName: uname}, RegisteredClaims: jwt.RegisteredClaims{ID: sid}}})
+	handler(c)
+	return rec
+}
+
+func TestRefresh(t *testing.T) {
+	user := newUser(t, "s-alice")
+	access, refresh, err := Start(user, "phone", "10.0.0.1")
+	if err != nil {
+		t.Fatal(err)
+	}
+	claims := parse(t, access)
+	if claims.UName != "s-alice" || len(claims.Password) > 0 || !Alive(claims) {
+		t.Fatalf("unexpected access claims: %+v", claims)
+	}
+	if exp := claims.ExpiresAt.Time; exp.After(time.Now().Add(AccessTTL)) {
+		t.Fatalf("access token should be short-lived, expire at %v", exp)
+	}
+
+	// rotate
+	uname, access2, refresh2, err := Refresh(refresh, "10.0.0.2")
+	if err != nil || uname != "s-alice" || refresh2 == refresh {
+		t.Fatalf("refresh should rotate, got %v %v", uname, err)
+	}
+	if c2 := parse(t, access2); c2.ID != claims.ID || !Alive(c2) {
+		t.Fatal("refreshed access token should be of the same session")
+	}
+
+	// unknown secret is rejected, session is kept
+	key := refresh[:strings.LastIndex(refresh, ".")]
+	if key != "s-alice"+SEP+claims.ID {
+		t.Fatalf("refresh token should carry session key, got %s", refresh)
+	}
+	if _, _, _, err := Refresh(key+".guessed", "10.0.0.3"); err != ErrRefreshInvalid {
+		t.Fatalf("unknown secret should be invalid, got %v", err)
+	}
+	if !Alive(claims) {
+		t.Fatal("session should not be revoked by unknown secret")
+	}
+
+	// reusing rotated-out token revokes session
+	if _, _, _, err := Refresh(refresh, "10.0.0.3"); err != ErrRefreshReused {
+		t.Fatalf("want reuse detected, got %v", err)
+	}
+	if Alive(claims) {
+		t.Fatal("session should be revoked after reuse")
+	}
+	if _, _, _, err := Refresh(refresh2, "10.0.0.2"); err != ErrRefreshInvalid {
+		t.Fatalf("refresh of revoked session should be invalid, got %v", err)
+	}
+	if _, _, _, err := Refresh("garbage", ""); err != ErrRefreshInvalid {
+		t.Fatalf("malformed refresh should be invalid, got %v", err)
+	}
+
+	// expired
+	_, refresh, err = Start(user, "pc", "10.0.0.1")
+	if err != nil {
+		t.Fatal(err)
+	}
+	timeNow = func() time.Time { return time.Now().Add(RefreshTTL + time.Hour) }
+	defer func() { timeNow = time.Now }()
+	if _, _, _, err := Refresh(refresh, ""); err != ErrRefreshInvalid {
+		t.Fatalf("expired refresh should be invalid, got %v", err)
+	}
+}
+
+func TestConcurrentRefresh(t *testing.T) {
+	user := newUser(t, "s-carol")
+	access, refresh, err := Start(user, "phone", "10.0.0.1")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// same token refreshed by real client & thief at once, only one wins. next one is seen as reuse, then session
+	// is revoked, so the rest are invalid
+	const n = 8
+	errs := make([]error, n)
+	wg := sync.WaitGroup{}
+	for i := 0; i < n; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			_, _, _, errs[i] = Refresh(refresh, "10.0.0.9")
+		}(i)
+	}
+	wg.Wait()
+
+	ok, reused, invalid := 0, 0, 0
+	for _, err := range errs {
+		switch err {
+		case nil:
+			ok++
+		case ErrRefreshReused:
+			reused++
+		case ErrRefreshInvalid:
+			invalid++
+		}
+	}
+	if ok != 1 || reused != 1 || invalid != n-2 {
+		t.Fatalf("want 1 refreshed, 1 reused & %d invalid, got %d, %d & %d: %v", n-2, ok, reused, invalid, errs)
+	}
+	if Alive(parse(t, access)) {
+		t.Fatal("session should be revoked after racing reuse")
+	}
+}
+
+func TestSessions(t *testing.T) {
+	user := newUser(t, "s-bob")
+	sids := []string{}
+	for _, device := range []string{"phone", "pc", "pad"} {
+		access, _, err := Start(user, device, "10.0.1.1")
+		if err != nil {
+			t.Fatal(err)
+		}
+		sids = append(sids, parse(t, access).ID)
+	}
+
+	rec := invoke(MyList, http.MethodGet, "s-bob", sids[1], "")
+	ss := []*Session{}
+	if err := json.Unmarshal(rec.Body.Bytes(), &ss); err != nil || len(ss) != 3 {
+		t.Fatalf("unexpected session list: %s", rec.Body.String())
+	}
+	for _, s := range ss {
+		if len(s.Secret) > 0 || s.Current != (s.ID == sids[1]) || s.IP != "10.0.1.1" {
+			t.Fatalf("unexpected session: %+v", s)
+		}
+	}
+
+	if rec := invoke(func(c echo.Context) error {
+		c.SetParamNames("id")
+		c.SetParamValues(sids[0])
+		return MyRevoke(c)
+	}, http.MethodDelete, "s-carol", sids[2], ""); rec.Code != http.StatusNotFound {
+		t.Fatalf("other user session should be 404, got %d", rec.Code)
+	}
+	if rec := invoke(MyRevokeOthers, http.MethodDelete, "s-bob", sids[1], ""); rec.Body.String() != "2\n" {
+		t.Fatalf("want 2 revoked, got %s", rec.Body.String())
+	}
+	if ss, _ := List("s-bob", ""); len(ss) != 1 || ss[0].ID != sids[1] {
+		t.Fatalf("only current session should be kept, got %v", ss)
+	}
+
+	if rec := invoke(UserRevoke, http.MethodDelete, "s-bob", sids[1], "uname=s-bob"); rec.Code != http.StatusUnauthorized {
+		t.Fatalf("non-admin should be 401, got %d", rec.Code)
+	}
+	if rec := invoke(UserRevoke, http.MethodDelete, "admin", "", "uname=s-bob"); rec.Body.String() != "1\n" {
+		t.Fatalf("admin should revoke all, got %s", rec.Body.String())
+	}
+	if Alive(&u.UserClaims{Core: u.Core{UName: "s-bob"}, RegisteredClaims: jwt.RegisteredClaims{ID: sids[1]}}) {
+		t.Fatal("revoked session should not be alive")
+	}
+}
@@ -7,14 +7,16 @@ import (
 	lk "github.com/digisan/logkit"
 	so "github.com/digisan/user-mgr/sign-out"
 	u "github.com/digisan/user-mgr/user"
+	"github.com/golang-jwt/jwt/v4"
 	"github.com/labstack/echo/v4"
+	"github.com/wismed-web/wisite-api/server/api/session"
 	"github.com/wismed-web/wisite-api/server/api/sign"
 )
 
 // *** after implementing, register with path in 'sign-out.go' *** //
 
 // @Title sign out
-// @Summary sign out action.
+// @Summary sign out action. current session is revoked, other sessions are kept.
 // @Description
 // @Tags    Sign
 // @Accept  json
@@ -25,15 +27,22 @@ import (
 // @Security ApiKeyAuth
 func SignOut(c echo.Context) error {
 
-	invoker, err := u.Invoker(c)
-	if err != nil {
+	var (
+		userTkn = c.Get("user").(*jwt.Token)
+		claims  = userTkn.Claims.(*u.UserClaims)
+		uname   = claims.UName
+	)
+
+	// access & refresh token of this session become invalid
+	if err := session.Revoke(uname, claims.ID); err != nil {
 		lk.Warn("%v", err)
 		return c.String(http.StatusInternalServerError, err.Error())
 	}
 
-	defer invoker.DeleteToken() // only in SignOut calling DeleteToken()
-
-	uname := invoker.UName
+	// still signed in on other devices
+	if ss, err := session.List(uname, ""); err == nil && len(ss) > 0 {
+		return c.JSON(http.StatusOK, fmt.Sprintf("[%s] sign-out successfully", uname))
+	}
 
 	// remove user by 'uname'
 	defer sign.UserCache.Delete(uname)
@@ -13,11 +13,13 @@ func SignHandler(e *echo.Group) {
 	var mGET = map[string]echo.HandlerFunc{}
 
 	var mPOST = map[string]echo.HandlerFunc{
-		"/new":              sign.NewUser,
-		"/verify-email":     sign.VerifyEmail,
-		"/in":               sign.LogIn,
-		"/reset-pwd":        sign.ResetPwd,
-		"/verify-reset-pwd": sign.VerifyResetPwd,
+		"/new":              sign.Guard("new", sign.NewUser),
+		"/verify-email":     sign.Guard("verify", sign.VerifyEmail),
+		"/in":               sign.Guard("in", sign.LogIn),
+		"/in-2fa":           sign.Guard("verify", sign.LogIn2FA),
+		"/reset-pwd":        sign.Guard("reset-pwd", sign.ResetPwd),
+		"/verify-reset-pwd": sign.Guard("verify", sign.VerifyResetPwd),
+		"/refresh":          sign.RefreshToken,
 	}
 
 	var mPUT = map[string]echo.HandlerFunc{}
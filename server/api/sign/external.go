@@ -0,0 +1,219 @@
+package sign
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+	"sync"
+	"time"
+
+	lk "github.com/digisan/logkit"
+	u "github.com/digisan/user-mgr/user"
+	"golang.org/x/crypto/bcrypt"
+)
+
+// external identity providers. a user who is unknown locally but can log in one provider
+// is stored as an external user named like "13888888888@@@V", then logs in as a local user
+
+const extSep = "@@@" // for creating external user-name e.g. 13888888888@@@V
+
+var errExtDormant = errors.New("external user is dormant")
+
+type ExtProvider interface {
+	// site code, suffix of external user-name, unique among providers
+	Code() string
+	// mail domain for external user's email
+	MailDomain() string
+	// member level (0-3) of newly created external user. 3 is admin, so it must be given deliberately
+	MemLevel() uint8
+	// [userId] is registered in provider site
+	Exists(userId string) (bool, error)
+	// [userId] with [pwd] can log in provider site
+	LogIn(userId, pwd string) (bool, error)
+}
+
+var (
+	mtxProvider sync.RWMutex
+	providers   []ExtProvider // in registration order, which is checking order when logging in
+)
+
+// add provider [p] for external user login, its code & mail domain must be unique,
+// as a saved external user is found by either user-name or email
+func RegisterProvider(p ExtProvider) error {
+	mtxProvider.Lock()
+	defer mtxProvider.Unlock()
+
+	if len(p.Code()) == 0 {
+		return errors.New("external provider code cannot be empty")
+	}
+	for _, existing := range providers {
+		if existing.Code() == p.Code() {
+			return fmt.Errorf("external provider [%s] is already registered", p.Code())
+		}
+		if existing.MailDomain() == p.MailDomain() {
+			return fmt.Errorf("mail domain [%s] is already used by external provider [%s]", p.MailDomain(), existing.Code())
+		}
+	}
+	providers = append(providers, p)
+	return nil
+}
+
+func Providers() []ExtProvider {
+	mtxProvider.RLock()
+	defer mtxProvider.RUnlock()
+	return append([]ExtProvider{}, providers...)
+}
+
+// create provider from one config object, e.g. item of "external-providers" in config.json.
+// secret token can be given by environment variable named in "token-env" instead of "token".
+// "mem-level" of new external users is 0 if absent
+func NewProvider(m map[string]any) (ExtProvider, error) {
+	str := func(key string) string {
+		if v, ok := m[key]; ok && v != nil {
+			return fmt.Sprint(v)
+		}
+		return ""
+	}
+	token := str("token")
+	if env := str("token-env"); len(env) > 0 {
+		token = os.Getenv(env)
+	}
+	timeout := 10 * time.Second
+	if n, ok := m["timeout"].(float64); ok && n > 0 {
+		timeout = time.Duration(n * float64(time.Second))
+	}
+	level := uint8(0)
+	if v, ok := m["mem-level"]; ok {
+		n, ok := v.(float64)
+		if !ok || n < 0 || n > 3 || n != float64(int(n)) {
+			return nil, fmt.Errorf("external provider [%s] 'mem-level' must be 0-3, got %v", str("code"), v)
+		}
+		level = uint8(n)
+	}
+
+	switch kind := str("kind"); kind {
+	case "v":
+		return NewVProvider(VConfig{
+			Code:       str("code"),
+			MailDomain: str("mail-domain"),
+			ExistsURL:  str("exists-url"),
+			LogInURL:   str("login-url"),
+			Token:      token,
+			Timeout:    timeout,
+			MemLevel:   level,
+		})
+	default:
+		return nil, fmt.Errorf("unknown external provider kind '%s'", kind)
+	}
+}
+
+func newExtUser(p ExtProvider, userId, verifier string) *u.User {
+	return &u.User{
+		Core: u.Core{
+			UName:    fmt.Sprintf("%s%s%s", userId, extSep, p.Code()),
+			Email:    fmt.Sprintf("%s@%s", userId, p.MailDomain()),
+			Password: verifier,
+		},
+		Profile: u.Profile{
+			Name:           userId,
+			Phone:          "",
+			Country:        "",
+			City:           "",
+			Addr:           "",
+			PersonalIDType: "",
+			PersonalID:     "",
+			Gender:         "",
+			DOB:            "",
+			Position:       "",
+			Title:          "",
+			Employer:       "",
+			Bio:            "",
+			AvatarType:     "",
+			Avatar:         []byte{},
+		},
+		Admin: u.Admin{
+			RegTime:   time.Now().Truncate(time.Second),
+			Active:    true,
+			Certified: false,
+			Official:  false,
+			SysRole:   "",
+			MemLevel:  p.MemLevel(),
+			MemExpire: time.Time{},
+			Tags:      "",
+		},
+	}
+}
+
+// local password of external user is never the upstream password, but a salted verifier (bcrypt) of it.
+// verifier lets external user log in when upstream password is unchanged, otherwise upstream is checked
+// and verifier is refreshed
+func newVerifier(pwd string) (string, error) {
+	hash, err := bcrypt.GenerateFromPassword([]byte(pwd), bcrypt.DefaultCost)
+	return string(hash), err
+}
+
+func isVerifier(s string) bool {
+	_, err := bcrypt.Cost([]byte(s))
+	return err == nil
+}
+
+func isExtUName(uname string) bool {
+	return strings.Contains(uname, extSep)
+}
+
+// external user [userId] from any provider for local login. for each provider in order, saved external user
+// is used if [pwd] matches its verifier or upstream accepts [pwd], otherwise, if [userId] can log in provider
+// site, a new external user is created. nil user if no provider accepts [userId] with [pwd]
+func extLogIn(userId, pwd string) (*u.User, error) {
+	for _, p := range Providers() {
+		ext := newExtUser(p, userId, "")
+		saved, ok, err := u.LoadAnyUser(ext.UName)
+		if err != nil {
+			return nil, err
+		}
+		if ok && !saved.IsActive() {
+			return nil, fmt.Errorf("%w: [%v]", errExtDormant, saved.UName)
+		}
+		if ok && isVerifier(saved.Password) && bcrypt.CompareHashAndPassword([]byte(saved.Password), []byte(pwd)) == nil {
+			return saved, nil
+		}
+
+		ok, err = p.LogIn(userId, pwd)
+		if err != nil {
+			lk.Warn("external provider [%s]: %v", p.Code(), redactIn(err.Error(), pwd))
+			continue
+		}
+		if !ok {
+			continue
+		}
+		if saved == nil {
+			saved = ext
+		}
+		if saved.Password, err = newVerifier(pwd); err != nil {
+			return nil, err
+		}
+		if err := u.UpdateUser(saved); err != nil {
+			return nil, err
+		}
+		return saved, nil
+	}
+	return nil, nil
+}
+
+// replace plaintext password of saved external users with its verifier. return count of replaced
+func scrubExtPasswords() (int, error) {
+	users, err := u.ListUser(func(user *u.User) bool { return isExtUName(user.UName) && !isVerifier(user.Password) })
+	if err != nil {
+		return 0, err
+	}
+	for _, user := range users {
+		if user.Password, err = newVerifier(user.Password); err != nil {
+			return 0, err
+		}
+		if err := u.UpdateUser(user); err != nil {
+			return 0, err
+		}
+	}
+	return len(users), nil
+}
@@ -8,193 +8,100 @@ import (
 	"net/http"
 	"net/url"
 	"time"
-
-	lk "github.com/digisan/logkit"
-	si "github.com/digisan/user-mgr/sign-in"
-	su "github.com/digisan/user-mgr/sign-up"
-	u "github.com/digisan/user-mgr/user"
 )
 
-const (
-	extSep  = "@@@" // for creating external user-name e.g. 13888888888@@@V
-	timeout = 10    // unit second
-	// V
-	vCode  = "V"          // V Site Code
-	vEmail = "wismed.net" // V Site Mail
-	// V Site API
-	apiVUserExists        = `https://www.scwismed.cn/api/external/checkUser`
-	apiVUserExistsToken   = `N39UNuYt3&GEfYS*uxYp7mti1Pv^$ISA`
-	apiVUserCanLogin      = `https://www.scwismed.cn/api/external/checkUserIsLogin`
-	apiVUserCanLoginToken = `N39UNuYt3&GEfYS*uxYp7mti1Pv^$ISA`
-)
+// V-Site provider, its api accepts form post with token and returns json like
+// {"code": 200, "status": "SUCCESS", "data": {"sta": 1}}
+
+type VConfig struct {
+	Code       string        // V Site Code, e.g. "V"
+	MailDomain string        // V Site Mail, e.g. "wismed.net"
+	ExistsURL  string        // api for checking user existing
+	LogInURL   string        // api for checking user login
+	Token      string        // shared api token
+	Timeout    time.Duration // for each api call
+	MemLevel   uint8         // member level of new V users, 0 by default, 3 makes them admin
+}
 
-func newExtUser(userId, pwd string) *u.User {
-	return &u.User{
-		Core: u.Core{
-			UName:    fmt.Sprintf("%s%s%s", userId, extSep, vCode),
-			Email:    fmt.Sprintf("%s@%s", userId, vEmail),
-			Password: pwd,
-		},
-		Profile: u.Profile{
-			Name:           userId,
-			Phone:          "",
-			Country:        "",
-			City:           "",
-			Addr:           "",
-			PersonalIDType: "",
-			PersonalID:     "",
-			Gender:         "",
-			DOB:            "",
-			Position:       "",
-			Title:          "",
-			Employer:       "",
-			Bio:            "",
-			AvatarType:     "",
-			Avatar:         []byte{},
-		},
-		Admin: u.Admin{
-			RegTime:   time.Now().Truncate(time.Second),
-			Active:    true,
-			Certified: false,
-			Official:  false,
-			SysRole:   "",
-			MemLevel:  3,
-			MemExpire: time.Time{},
-			Tags:      "",
-		},
-	}
+func (c VConfig) String() string {
+	return fmt.Sprintf("V [%s] @%s, exists: %s, login: %s, token: %s, timeout: %v, member level: %d",
+		c.Code, c.MailDomain, c.ExistsURL, c.LogInURL, redact(c.Token), c.Timeout, c.MemLevel)
 }
 
-func createExtUser(userId, pwd string) (*u.User, error) {
-	user := newExtUser(userId, pwd)
-	return user, su.Store(user)
+type vProvider struct {
+	cfg    VConfig
+	client *http.Client
 }
 
-func validateSavedExtUser(userId, pwd string) *u.User {
-	if user := newExtUser(userId, pwd); si.UserStatusIssue(user) == nil {
-		return user
+func NewVProvider(cfg VConfig) (ExtProvider, error) {
+	switch {
+	case len(cfg.Code) == 0 || len(cfg.MailDomain) == 0:
+		return nil, errors.New("V provider needs code & mail domain")
+	case len(cfg.ExistsURL) == 0 || len(cfg.LogInURL) == 0:
+		return nil, fmt.Errorf("V provider [%s] needs exists & login urls", cfg.Code)
+	case len(cfg.Token) == 0:
+		return nil, fmt.Errorf("V provider [%s] needs api token", cfg.Code)
+	case cfg.MemLevel > 3:
+		return nil, fmt.Errorf("V provider [%s] member level must be 0-3", cfg.Code)
 	}
-	return nil
+	return &vProvider{cfg: cfg, client: &http.Client{Timeout: cfg.Timeout}}, nil
 }
 
-type ResultExt struct {
-	ok  bool
-	err error
+func (p *vProvider) Code() string {
+	return p.cfg.Code
 }
 
-func vUserExistsAsync(userId string) chan ResultExt {
-	cResult := make(chan ResultExt)
-	go func() {
-		params := url.Values{}
-		params.Add("token", apiVUserExistsToken)
-		params.Add("mobile", userId)
-		resp, err := http.PostForm(apiVUserExists, params)
-		lk.WarnOnErr("%v", err)
-		if err != nil {
-			cResult <- ResultExt{
-				ok:  false,
-				err: err,
-			}
-			return
-		}
-		defer resp.Body.Close()
-
-		body, err := io.ReadAll(resp.Body)
-		lk.WarnOnErr("%v", err)
-		if err != nil {
-			cResult <- ResultExt{
-				ok:  false,
-				err: err,
-			}
-			return
-		}
-		mData := make(map[string]any)
-		lk.WarnOnErr("%v", json.Unmarshal(body, &mData))
-
-		// fmt.Println(mData)
-
-		if mData["code"].(float64) == 200 {
-			if mData["status"] == "SUCCESS" {
-				if mData["data"].(map[string]any)["sta"].(float64) == 1 {
-					cResult <- ResultExt{
-						ok:  true,
-						err: nil,
-					}
-					return
-				}
-			}
-		}
-		cResult <- ResultExt{
-			ok:  false,
-			err: fmt.Errorf("[%s] is not registered in V-Site", userId),
-		}
-	}()
-	return cResult
+func (p *vProvider) MailDomain() string {
+	return p.cfg.MailDomain
 }
 
-func vUserExistsCheck(userId string) (bool, error) {
-	select {
-	case <-time.After(timeout * time.Second):
-		return false, errors.New("timeout")
-	case r := <-vUserExistsAsync(userId):
-		return r.ok, r.err
-	}
+func (p *vProvider) MemLevel() uint8 {
+	return p.cfg.MemLevel
 }
 
-func vUserLoginValidateAsync(userId, pwd string) chan ResultExt {
-	cResult := make(chan ResultExt)
-	go func() {
-		params := url.Values{}
-		params.Add("token", apiVUserCanLoginToken)
-		params.Add("mobile", userId)
-		params.Add("password", pwd)
-		resp, err := http.PostForm(apiVUserCanLogin, params)
-		lk.WarnOnErr("%v", err)
-		if err != nil {
-			cResult <- ResultExt{
-				ok:  false,
-				err: err,
-			}
-			return
-		}
-		defer resp.Body.Close()
+type vResult struct {
+	Code   int             `json:"code"`
+	Status string          `json:"status"`
+	Data   json.RawMessage `json:"data"` // differs by api
+}
 
-		body, err := io.ReadAll(resp.Body)
-		lk.WarnOnErr("%v", err)
-		if err != nil {
-			cResult <- ResultExt{
-				ok:  false,
-				err: err,
-			}
-			return
-		}
-		mData := make(map[string]any)
-		lk.WarnOnErr("%v", json.Unmarshal(body, &mData))
+func (p *vProvider) post(api string, params url.Values) (*vResult, error) {
+	params.Set("token", p.cfg.Token)
+	resp, err := p.client.PostForm(api, params)
+	if err != nil {
+		return nil, errors.New(redactIn(err.Error(), p.cfg.Token))
+	}
+	defer resp.Body.Close()
 
-		// fmt.Println(mData)
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, err
+	}
+	rst := &vResult{}
+	if err := json.Unmarshal(body, rst); err != nil {
+		return nil, fmt.Errorf("invalid response from V-Site [%s]: %v", p.cfg.Code, err)
+	}
+	return rst, nil
+}
 
-		if mData["code"].(float64) == 200 {
-			if mData["status"] == "SUCCESS" {
-				cResult <- ResultExt{
-					ok:  true,
-					err: nil,
-				}
-				return
-			}
-		}
-		cResult <- ResultExt{
-			ok:  false,
-			err: fmt.Errorf("[%s] with password [%s] cannot login v-site", userId, pwd),
-		}
-	}()
-	return cResult
+func (p *vProvider) Exists(userId string) (bool, error) {
+	rst, err := p.post(p.cfg.ExistsURL, url.Values{"mobile": {userId}})
+	if err != nil {
+		return false, err
+	}
+	data := struct {
+		Sta int `json:"sta"`
+	}{}
+	if rst.Code != 200 || rst.Status != "SUCCESS" || json.Unmarshal(rst.Data, &data) != nil {
+		return false, nil
+	}
+	return data.Sta == 1, nil
 }
 
-func vUserLoginCheck(userId, pwd string) (bool, error) {
-	select {
-	case <-time.After(timeout * time.Second):
-		return false, errors.New("timeout")
-	case r := <-vUserLoginValidateAsync(userId, pwd):
-		return r.ok, r.err
+func (p *vProvider) LogIn(userId, pwd string) (bool, error) {
+	rst, err := p.post(p.cfg.LogInURL, url.Values{"mobile": {userId}, "password": {pwd}})
+	if err != nil {
+		return false, err
 	}
+	return rst.Code == 200 && rst.Status == "SUCCESS", nil
 }
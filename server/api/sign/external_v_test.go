@@ -1,18 +1,202 @@
 package sign
 
 import (
-	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
 	"testing"
+	"time"
+
+	u "github.com/digisan/user-mgr/user"
+	"github.com/wismed-web/wisite-api/server/api/admin"
 )
 
-func TestVUserExists(t *testing.T) {
-	ok, err := vUserExistsCheck("13980824611")
-	fmt.Println(ok, err)
+// local fake V-Site, [users] is registered mobile : password
+func newFakeVServer(token string, users map[string]string) *httptest.Server {
+	mux := http.NewServeMux()
+	reply := func(w http.ResponseWriter, ok bool, data string) {
+		w.Header().Set("Content-Type", "application/json")
+		if ok {
+			w.Write([]byte(`{"code":200,"status":"SUCCESS","data":` + data + `}`))
+			return
+		}
+		w.Write([]byte(`{"code":500,"status":"FAIL","data":null}`))
+	}
+	mux.HandleFunc("/checkUser", func(w http.ResponseWriter, r *http.Request) {
+		if r.PostFormValue("token") != token {
+			reply(w, false, "")
+			return
+		}
+		_, ok := users[r.PostFormValue("mobile")]
+		reply(w, true, map[bool]string{true: `{"sta":1}`, false: `{"sta":0}`}[ok])
+	})
+	mux.HandleFunc("/checkUserIsLogin", func(w http.ResponseWriter, r *http.Request) {
+		pwd, ok := users[r.PostFormValue("mobile")]
+		reply(w, r.PostFormValue("token") == token && ok && pwd == r.PostFormValue("password"), `"ok"`)
+	})
+	return httptest.NewServer(mux)
+}
+
+func newFakeVProvider(t *testing.T, code, token string, users map[string]string) ExtProvider {
+	srv := newFakeVServer("secret", users)
+	t.Cleanup(srv.Close)
+	p, err := NewProvider(map[string]any{
+		"kind":        "v",
+		"code":        code,
+		"mail-domain": strings.ToLower(code) + ".fake.net",
+		"exists-url":  srv.URL + "/checkUser",
+		"login-url":   srv.URL + "/checkUserIsLogin",
+		"token":       token,
+		"timeout":     float64(2),
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	return p
+}
+
+func TestVProvider(t *testing.T) {
+	p := newFakeVProvider(t, "FV", "secret", map[string]string{"13900000001": "123456"})
+
+	if ok, err := p.Exists("13900000001"); err != nil || !ok {
+		t.Fatalf("registered user should exist, got %v %v", ok, err)
+	}
+	if ok, err := p.Exists("13900000002"); err != nil || ok {
+		t.Fatalf("unknown user should not exist, got %v %v", ok, err)
+	}
+	if ok, err := p.LogIn("13900000001", "123456"); err != nil || !ok {
+		t.Fatalf("correct password should log in, got %v %v", ok, err)
+	}
+	if ok, err := p.LogIn("13900000001", "12345"); err != nil || ok {
+		t.Fatalf("wrong password should not log in, got %v %v", ok, err)
+	}
+
+	bad := newFakeVProvider(t, "FB", "wrong", map[string]string{"13900000001": "123456"})
+	if ok, err := bad.LogIn("13900000001", "123456"); err != nil || ok {
+		t.Fatalf("wrong api token should not log in, got %v %v", ok, err)
+	}
+
+	if _, err := NewProvider(map[string]any{"kind": "x"}); err == nil {
+		t.Fatal("unknown provider kind should fail")
+	}
+	if _, err := NewVProvider(VConfig{Code: "V", MailDomain: "fake.net", ExistsURL: "u", LogInURL: "u"}); err == nil {
+		t.Fatal("V provider without token should fail")
+	}
+
+	slow := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		time.Sleep(500 * time.Millisecond)
+	}))
+	defer slow.Close()
+	p, _ = NewVProvider(VConfig{"SV", "fake.net", slow.URL, slow.URL, "secret", 100 * time.Millisecond, 0})
+	if _, err := p.LogIn("13900000001", "123456"); err == nil {
+		t.Fatal("slow V-Site should time out")
+	}
+}
+
+func TestExtLogIn(t *testing.T) {
+	providers = nil
+	defer func() { providers = nil }()
+
+	usersB := map[string]string{"13900000012": "Pwd-B#2022"}
+	fa := newFakeVProvider(t, "FA", "secret", map[string]string{"13900000011": "Pwd-A#2022"})
+	fb := newFakeVProvider(t, "FB", "secret", usersB)
+	for _, p := range []ExtProvider{fa, fb} {
+		if err := RegisterProvider(p); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := RegisterProvider(fa); err == nil {
+		t.Fatal("duplicate provider code should fail")
+	}
+	if p, _ := NewVProvider(VConfig{"FC", "fa.fake.net", "u", "u", "secret", time.Second, 0}); RegisterProvider(p) == nil {
+		t.Fatal("duplicate provider mail domain should fail")
+	}
+
+	user, err := extLogIn("13900000012", "Pwd-B#2022")
+	if err != nil || user == nil || user.UName != "13900000012@@@FB" || user.Email != "13900000012@fb.fake.net" {
+		t.Fatalf("second provider user should be created, got %v %v", user, err)
+	}
+	saved, ok, err := u.LoadActiveUser(user.UName)
+	if err != nil || !ok || saved.Password == "Pwd-B#2022" || !isVerifier(saved.Password) {
+		t.Fatalf("external user should be saved with verifier, got %v %v", ok, err)
+	}
+	if user, err := extLogIn("13900000012", "wrong"); err != nil || user != nil {
+		t.Fatalf("saved external user with wrong password should fail, got %v %v", user, err)
+	}
+
+	// upstream password changed, new one is checked upstream and verifier is refreshed
+	usersB["13900000012"] = "Pwd-B#2023"
+	if user, err := extLogIn("13900000012", "Pwd-B#2023"); err != nil || user == nil || user.UName != "13900000012@@@FB" {
+		t.Fatalf("changed upstream password should log in, got %v %v", user, err)
+	}
+	refreshed, _, _ := u.LoadActiveUser("13900000012@@@FB")
+	if refreshed.Password == saved.Password || refreshed.RegTime != saved.RegTime {
+		t.Fatal("verifier should be refreshed, keeping other fields")
+	}
+
+	if user, err := extLogIn("13900000011", "wrong"); err != nil || user != nil {
+		t.Fatalf("wrong password should not create external user, got %v %v", user, err)
+	}
+	if user, err := extLogIn("13900000099", "Pwd-A#2022"); err != nil || user != nil {
+		t.Fatalf("unknown user should be nil, got %v %v", user, err)
+	}
+
+	// legacy plaintext password is replaced
+	legacy := newExtUser(fa, "13900000013", "Pwd-L#2022")
+	if err := u.UpdateUser(legacy); err != nil {
+		t.Fatal(err)
+	}
+	if n, err := scrubExtPasswords(); err != nil || n != 1 {
+		t.Fatalf("want 1 scrubbed, got %d %v", n, err)
+	}
+	if user, err := extLogIn("13900000013", "Pwd-L#2022"); err != nil || user == nil || !isVerifier(user.Password) {
+		t.Fatalf("scrubbed user should log in by verifier, got %v %v", user, err)
+	}
+}
+
+func TestExtMemLevel(t *testing.T) {
+	providers = nil
+	defer func() { providers = nil }()
+
+	// new external user is not admin by default, member level is given by provider config
+	fd := newFakeVProvider(t, "FD", "secret", map[string]string{"13900000021": "Pwd-D#2022"})
+	cfg := map[string]any{"kind": "v", "code": "FE", "mail-domain": "fe.fake.net", "exists-url": "u", "login-url": "u", "token": "secret", "mem-level": float64(2)}
+	fe, err := NewProvider(cfg)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := RegisterProvider(fd); err != nil {
+		t.Fatal(err)
+	}
+	user, err := extLogIn("13900000021", "Pwd-D#2022")
+	if err != nil || user == nil || user.MemLevel != 0 {
+		t.Fatalf("external user should be created with member level 0, got %v %v", user, err)
+	}
+	if isAdmin, err := admin.IsAdmin(user.UName); err != nil || isAdmin {
+		t.Fatalf("external user should not be admin, got %v %v", isAdmin, err)
+	}
+	if user := newExtUser(fe, "13900000022", ""); user.MemLevel != 2 {
+		t.Fatalf("configured member level should be used, got %d", user.MemLevel)
+	}
+
+	for _, level := range []any{float64(4), float64(-1), float64(1.5), "3"} {
+		cfg["mem-level"] = level
+		if _, err := NewProvider(cfg); err == nil {
+			t.Fatalf("invalid member level %v should fail", level)
+		}
+	}
 }
 
-func TestVUserLogin(t *testing.T) {
-	ok, err := vUserLoginCheck("13980824611", "123456")
-	fmt.Println(ok, err)
-	ok, err = vUserLoginCheck("13980824611", "12345")
-	fmt.Println(ok, err)
+func TestRedact(t *testing.T) {
+	if redact("") != "" || redact("Pwd-A#2022") == "Pwd-A#2022" {
+		t.Fatal("unexpected redact")
+	}
+	msg := redactIn(`Post "http://v/login?token=abc&password=Pwd-A": EOF`, "abc", "Pwd-A", "")
+	if strings.Contains(msg, "abc") || strings.Contains(msg, "Pwd-A") {
+		t.Fatalf("secrets should be masked, got %s", msg)
+	}
+	cfg := VConfig{Code: "V", Token: "N39UNuYt3"}
+	if strings.Contains(cfg.String(), "N39UNuYt3") {
+		t.Fatal("config token should be masked")
+	}
 }
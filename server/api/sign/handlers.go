@@ -1,6 +1,7 @@
 package sign
 
 import (
+	"errors"
 	"fmt"
 	"net/http"
 	"sync"
@@ -13,6 +14,8 @@ import (
 	su "github.com/digisan/user-mgr/sign-up"
 	u "github.com/digisan/user-mgr/user"
 	"github.com/labstack/echo/v4"
+	"github.com/wismed-web/wisite-api/server/api/mfa"
+	"github.com/wismed-web/wisite-api/server/api/session"
 )
 
 // *** after implementing, register with path in 'sign.go' *** //
@@ -33,11 +36,12 @@ var (
 // @Param   pwd     formData   string  true  "user's password"
 // @Success 200 "OK - then waiting for verification code"
 // @Failure 400 "Fail - invalid registry fields"
+// @Failure 429 "Fail - too many attempts, retry after seconds in header Retry-After"
 // @Failure 500 "Fail - internal error"
 // @Router /api/sign/new [post]
 func NewUser(c echo.Context) error {
 
-	// lk.Debug("[%v] [%v] [%v] [%v]", c.FormValue("uname"), c.FormValue("email"), c.FormValue("name"), c.FormValue("pwd"))
+	// lk.Debug("[%v] [%v] [%v] [%v]", c.FormValue("uname"), c.FormValue("email"), c.FormValue("name"), redact(c.FormValue("pwd")))
 
 	user := &u.User{
 		Core: u.Core{
@@ -99,6 +103,7 @@ func NewUser(c echo.Context) error {
 // @Param   code   formData  string  true  "verification code (in user's email)"
 // @Success 200 "OK - sign-up successfully"
 // @Failure 400 "Fail - incorrect verification code"
+// @Failure 429 "Fail - too many attempts, retry after seconds in header Retry-After"
 // @Failure 500 "Fail - internal error"
 // @Router /api/sign/verify-email [post]
 func VerifyEmail(c echo.Context) error {
@@ -131,7 +136,7 @@ func VerifyEmail(c echo.Context) error {
 }
 
 // @Title sign in
-// @Summary sign in action. if ok, got token
+// @Summary sign in action. if ok, got short-lived access token & refresh token of a new session. if user has 2fa, or 2fa is required to enroll, got a challenge to answer at '/api/sign/in-2fa' instead.
 // @Description
 // @Tags    Sign
 // @Accept  multipart/form-data
@@ -140,6 +145,7 @@ func VerifyEmail(c echo.Context) error {
 // @Param   pwd   formData string true "password" Format(password)
 // @Success 200 "OK - sign-in successfully"
 // @Failure 400 "Fail - incorrect password"
+// @Failure 429 "Fail - too many attempts, retry after seconds in header Retry-After"
 // @Failure 500 "Fail - internal error"
 // @Router /api/sign/in [post]
 func LogIn(c echo.Context) error {
@@ -150,7 +156,7 @@ func LogIn(c echo.Context) error {
 		email = c.FormValue("uname")
 	)
 
-	lk.Debug("login: [%v] [%v]", uname, pwd)
+	lk.Debug("login: [%v] [%v]", uname, redact(pwd))
 
 	user := &u.User{
 		Core: u.Core{
@@ -162,40 +168,89 @@ func LogIn(c echo.Context) error {
 		Admin:   u.Admin{},
 	}
 
-AGAIN:
-
 	if err := si.UserStatusIssue(user); err != nil {
-
-		///////////////////////////////////////
-		// external user checking
-		{
-			// try v-site existing user check. if external user already exists, wrap user & login again
-			if u := validateSavedExtUser(uname, pwd); u != nil {
-				user = u
-				goto AGAIN
-			}
-
-			// external v-site check via remote api
-			if ok, err := vUserLoginCheck(uname, pwd); err == nil && ok {
-				// if can login v-site, but doesn't exist, create a new external user, u.uname is like "13888888888@@@V"
-				u, err := createExtUser(uname, pwd)
-				if err != nil {
-					return c.String(http.StatusInternalServerError, "ERR: creating external user, "+err.Error())
-				}
-				user = u
-				goto AGAIN
-			}
+		// not a local user, try external identity providers, which check password themselves
+		ext, extErr := extLogIn(uname, pwd)
+		switch {
+		case errors.Is(extErr, errExtDormant):
+			return c.String(http.StatusBadRequest, extErr.Error())
+		case extErr != nil:
+			return c.String(http.StatusInternalServerError, "ERR: external user login, "+extErr.Error())
+		case ext == nil:
+			return c.String(http.StatusBadRequest, err.Error())
 		}
-		///////////////////////////////////////
+		user = ext
+
+	} else if !si.PwdOK(user) || isExtUName(user.UName) { // if successful, user updated. external user only logs in with provider id
+		return c.String(http.StatusBadRequest, "incorrect password")
+	}
+
+	// fmt.Println(user)
+
+	// password is ok, 2fa user must answer a challenge with its code to finish sign-in
+	mode, challenge, seed, uri, err := mfa.Challenge(user)
+	if err != nil {
+		return c.String(http.StatusInternalServerError, err.Error())
+	}
+	if len(mode) > 0 {
+		c.Set(ctxPending, true) // sign-in is not finished until 2fa code is answered
+	}
+	switch mode {
+	case "totp":
+		return c.JSON(http.StatusOK, echo.Map{
+			"mfa":       mode,
+			"challenge": challenge,
+		})
+	case "enroll":
+		return c.JSON(http.StatusOK, echo.Map{
+			"mfa":       mode,
+			"challenge": challenge,
+			"secret":    seed,
+			"uri":       uri,
+		})
+	}
+
+	return signedIn(c, user, nil)
+}
 
+// @Title sign in with 2fa
+// @Summary sign in action, step 2 for two-factor authentication user. answer challenge from sign-in with a code from authenticator app, or a one-time recovery code. if 2fa enrollment is required, the code confirms it and recovery codes are returned. if ok, got tokens like sign-in.
+// @Description
+// @Tags    Sign
+// @Accept  multipart/form-data
+// @Produce json
+// @Param   challenge formData string true "challenge from sign-in"
+// @Param   code      formData string true "code from authenticator app, or a recovery code"
+// @Success 200 "OK - sign-in successfully"
+// @Failure 400 "Fail - incorrect code"
+// @Failure 401 "Fail - invalid or expired challenge"
+// @Failure 429 "Fail - too many attempts, retry after seconds in header Retry-After"
+// @Failure 500 "Fail - internal error"
+// @Router /api/sign/in-2fa [post]
+func LogIn2FA(c echo.Context) error {
+	uname, recovery, err := mfa.Answer(c.FormValue("challenge"), c.FormValue("code"))
+	switch {
+	case err == mfa.ErrChallenge:
+		return c.String(http.StatusUnauthorized, err.Error())
+	case err == mfa.ErrCode:
 		return c.String(http.StatusBadRequest, err.Error())
+	case err != nil:
+		return c.String(http.StatusInternalServerError, err.Error())
 	}
 
-	if !si.PwdOK(user) { // if successful, user updated.
-		return c.String(http.StatusBadRequest, "incorrect password")
+	user, ok, err := u.LoadActiveUser(uname)
+	switch {
+	case err != nil:
+		return c.String(http.StatusInternalServerError, err.Error())
+	case !ok:
+		return c.String(http.StatusUnauthorized, fmt.Sprintf("user [%s] is not active", uname))
 	}
 
-	// fmt.Println(user)
+	return signedIn(c, user, recovery)
+}
+
+// finish sign-in of [user], starting a new session. [recovery] codes are replied if 2fa is just enabled
+func signedIn(c echo.Context, user *u.User, recovery []string) error {
 
 	// now, user is real user in db
 	defer lk.FailOnErr("%v", si.Trail(user.UName)) // Refresh Online Users, here UName is real
@@ -210,11 +265,56 @@ AGAIN:
 
 	defer func() { UserCache.Store(user.UName, user) }() // save current user for other usage
 
-	claims := u.MakeClaims(user)
-	token := u.GenerateToken(claims)
+	// one session per login, short-lived access token & rotating refresh token
+	token, refresh, err := session.Start(user, c.Request().UserAgent(), c.RealIP())
+	if err != nil {
+		return c.String(http.StatusInternalServerError, err.Error())
+	}
+	rt := echo.Map{
+		"token":   token,
+		"auth":    "Bearer " + token,
+		"refresh": refresh,
+		"expires": int(session.AccessTTL.Seconds()),
+	}
+	if len(recovery) > 0 {
+		rt["recovery"] = recovery
+	}
+	return c.JSON(http.StatusOK, rt)
+}
+
+// @Title refresh token
+// @Summary exchange refresh token for new access token & refresh token. each refresh token is used only once, reusing an old one revokes its session.
+// @Description
+// @Tags    Sign
+// @Accept  multipart/form-data
+// @Produce json
+// @Param   refresh formData string true "refresh token from sign-in or last refresh"
+// @Success 200 "OK - refresh successfully"
+// @Failure 401 "Fail - invalid, expired or reused refresh token"
+// @Failure 500 "Fail - internal error"
+// @Router /api/sign/refresh [post]
+func RefreshToken(c echo.Context) error {
+	uname, token, refresh, err := session.Refresh(c.FormValue("refresh"), c.RealIP())
+	switch {
+	case err == session.ErrRefreshInvalid || err == session.ErrRefreshReused:
+		return c.String(http.StatusUnauthorized, err.Error())
+	case err != nil:
+		return c.String(http.StatusInternalServerError, err.Error())
+	}
+
+	// back online, in case inactivity monitor has signed it out
+	lk.WarnOnErr("%v", si.Trail(uname))
+	if _, ok := UserCache.Load(uname); !ok {
+		if user, ok, err := u.LoadActiveUser(uname); err == nil && ok {
+			UserCache.Store(uname, user)
+		}
+	}
+
 	return c.JSON(http.StatusOK, echo.Map{
-		"token": token,
-		"auth":  "Bearer " + token,
+		"token":   token,
+		"auth":    "Bearer " + token,
+		"refresh": refresh,
+		"expires": int(session.AccessTTL.Seconds()),
 	})
 }
 
@@ -228,6 +328,7 @@ AGAIN:
 // @Param   email   formData   string  true  "user's email" Format(email)
 // @Success 200 "OK - then waiting for verification code"
 // @Failure 400 "Fail - invalid registry fields"
+// @Failure 429 "Fail - too many attempts, retry after seconds in header Retry-After"
 // @Failure 500 "Fail - internal error"
 // @Router /api/sign/reset-pwd [post]
 func ResetPwd(c echo.Context) error {
@@ -273,6 +374,7 @@ func ResetPwd(c echo.Context) error {
 // @Param   pwd    formData  string  true  "new password"
 // @Success 200 "OK   - password updated successfully"
 // @Failure 400 "Fail - incorrect verification code"
+// @Failure 429 "Fail - too many attempts, retry after seconds in header Retry-After"
 // @Failure 500 "Fail - internal error"
 // @Router /api/sign/verify-reset-pwd [post]
 func VerifyResetPwd(c echo.Context) error {
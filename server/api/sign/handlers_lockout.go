@@ -0,0 +1,61 @@
+package sign
+
+import (
+	"fmt"
+	"net/http"
+
+	"github.com/labstack/echo/v4"
+	"github.com/wismed-web/wisite-api/server/api/admin"
+)
+
+// *** after implementing, register with path in 'admin.go' *** //
+
+// @Title list sign lockouts
+// @Summary list accounts & client ips which are waiting for backoff or locked out in sign apis, locked ones first.
+// @Description
+// @Tags    Admin
+// @Accept  json
+// @Produce json
+// @Success 200 "OK - list successfully"
+// @Failure 401 "Fail - unauthorized error"
+// @Failure 500 "Fail - internal error"
+// @Router /api/admin/lockouts [get]
+// @Security ApiKeyAuth
+func LockoutList(c echo.Context) error {
+	if ok, err := admin.Only(c); !ok {
+		return err
+	}
+	return c.JSON(http.StatusOK, Lockouts())
+}
+
+// @Title clear sign lockout
+// @Summary clear attempts record of one account or client ip, or all records if key is 'all'.
+// @Description
+// @Tags    Admin
+// @Accept  json
+// @Produce json
+// @Param   key query string true "'key' of one lockout item, or 'all'"
+// @Success 200 "OK - clear successfully, return count of cleared"
+// @Failure 400 "Fail - missing key"
+// @Failure 401 "Fail - unauthorized error"
+// @Failure 404 "Fail - key not found"
+// @Failure 500 "Fail - internal error"
+// @Router /api/admin/lockout [delete]
+// @Security ApiKeyAuth
+func ClearLockout(c echo.Context) error {
+	if ok, err := admin.Only(c); !ok {
+		return err
+	}
+	key := c.QueryParam("key")
+	switch key {
+	case "":
+		return c.String(http.StatusBadRequest, "'key' is required, 'all' for clearing all")
+	case "all":
+		key = ""
+	}
+	n := clearLockout(key)
+	if n == 0 && len(key) > 0 {
+		return c.String(http.StatusNotFound, fmt.Sprintf("lockout not found @%s", key))
+	}
+	return c.JSON(http.StatusOK, n)
+}
@@ -23,6 +23,13 @@ func init() {
 	// set user db dir, activate ***[UserDB]***
 	u.InitDB("./data/db-user")
 
+	// external user keeps verifier instead of upstream password
+	n, err := scrubExtPasswords()
+	lk.WarnOnErr("%v", err)
+	if n > 0 {
+		lk.Log("plaintext password of %d external users replaced by verifier", n)
+	}
+
 	// set user validator
 	su.SetValidator(map[string]func(o, v any) u.ValRst{
 		vf.AvatarType: func(o, v any) u.ValRst {
@@ -34,6 +41,9 @@ func init() {
 	// monitor active users
 	ctx, Cancel = context.WithCancel(context.Background())
 	monitorUser(ctx, 3600*time.Second) // heartbeats checker timeout
+
+	// forget expired sign attempts
+	go sweepAttempts(ctx, 10*time.Minute)
 }
 
 func monitorUser(ctx context.Context, offlineTimeout time.Duration) {
@@ -0,0 +1,36 @@
+package sign
+
+import (
+	"fmt"
+	"net"
+	"strings"
+
+	. "github.com/digisan/go-generics/v2"
+	"github.com/labstack/echo/v4"
+)
+
+// client ip extractor for echo, i.e. what c.RealIP() returns for limiting & session records.
+// with no [proxies], client ip is the direct peer address and forwarding headers are ignored.
+// otherwise X-Forwarded-For is walked back only through [proxies] (ip or CIDR, e.g. "10.0.0.0/8"),
+// so a client cannot make up its ip by sending the header itself
+func IPExtractor(proxies ...string) (echo.IPExtractor, error) {
+	if len(proxies) == 0 {
+		return echo.ExtractIPDirect(), nil
+	}
+	opts := []echo.TrustOption{
+		echo.TrustLoopback(false),
+		echo.TrustLinkLocal(false),
+		echo.TrustPrivateNet(false),
+	}
+	for _, p := range proxies {
+		if !strings.Contains(p, "/") {
+			p += IF(strings.Contains(p, ":"), "/128", "/32")
+		}
+		_, ipNet, err := net.ParseCIDR(p)
+		if err != nil {
+			return nil, fmt.Errorf("invalid trusted proxy '%s': %v", p, err)
+		}
+		opts = append(opts, echo.TrustIPRange(ipNet))
+	}
+	return echo.ExtractIPFromXFFHeader(opts...), nil
+}
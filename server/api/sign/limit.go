@@ -0,0 +1,347 @@
+package sign
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	. "github.com/digisan/go-generics/v2"
+	lk "github.com/digisan/logkit"
+	u "github.com/digisan/user-mgr/user"
+	"github.com/golang-jwt/jwt/v4"
+	"github.com/labstack/echo/v4"
+	"github.com/wismed-web/wisite-api/server/api/mfa"
+)
+
+// brute-force protection for sign apis. attempts are tracked per account (form 'uname', owner of answered
+// 2fa challenge, or signed-in caller) and per client ip in each scope. after some free failures, next attempt must wait for an
+// exponential backoff delay, and after more failures, it is locked out for a while. in-memory only,
+// restarting service clears all
+
+const (
+	SEP = "^"
+
+	ctxPending = "sign-pending" // set by sign api whose 2xx reply is not finished yet, e.g. waiting for 2fa
+)
+
+type policy struct {
+	free     int           // failures without delay
+	lock     int           // failures to lock out
+	base     time.Duration // delay after first failure beyond [free], doubled for each next one
+	maxDelay time.Duration // max backoff delay
+	lockFor  time.Duration // lockout duration
+	window   time.Duration // attempts record is forgotten if no attempt within it
+	countOK  bool          // successful attempt also counts, e.g. it sends an email
+}
+
+var (
+	accountPolicy = policy{free: 3, lock: 10, base: time.Second, maxDelay: 5 * time.Minute, lockFor: 15 * time.Minute, window: time.Hour}
+	ipPolicy      = policy{free: 10, lock: 50, base: time.Second, maxDelay: 5 * time.Minute, lockFor: 30 * time.Minute, window: time.Hour}
+
+	// scope : [account policy, ip policy]
+	mScopePolicy = map[string][2]policy{
+		"in":        {accountPolicy, ipPolicy},
+		"verify":    {accountPolicy, ipPolicy}, // verification & 2fa code guessing, shared by all code checking apis
+		"new":       {withCountOK(accountPolicy), withCountOK(ipPolicy)},
+		"reset-pwd": {withCountOK(accountPolicy), withCountOK(ipPolicy)},
+	}
+
+	mtxAttempt sync.Mutex
+	mAttempt   = map[string]*Attempt{} // key: scope ^ kind ^ subject
+
+	timeNow = time.Now // variable for replacing in test
+)
+
+func withCountOK(p policy) policy {
+	p.countOK = true
+	return p
+}
+
+// attempts record of one account or client ip in one scope
+type Attempt struct {
+	Key      string    `json:"key"` // scope ^ kind ^ subject, for clearing
+	Scope    string    `json:"scope"`
+	Kind     string    `json:"kind"` // "account" or "ip"
+	Subject  string    `json:"subject"`
+	Failures int       `json:"failures"` // counted attempts
+	Last     time.Time `json:"last"`     // last counted attempt
+	Until    time.Time `json:"until"`    // no attempt is accepted before it
+	Locked   bool      `json:"locked"`
+}
+
+func (a Attempt) String() string {
+	return fmt.Sprintf("%s %s [%s] failures: %d, last: %v, until: %v, locked: %v", a.Scope, a.Kind, a.Subject, a.Failures, a.Last, a.Until, a.Locked)
+}
+
+func attemptKey(scope, kind, subject string) string {
+	return scope + SEP + kind + SEP + subject
+}
+
+func (a *Attempt) policy() policy {
+	return mScopePolicy[a.Scope][IF(a.Kind == "account", 0, 1)]
+}
+
+// record is forgotten once lockout ends or nothing happened within window
+func (a *Attempt) expired(now time.Time) bool {
+	return (a.Locked && !now.Before(a.Until)) || now.Sub(a.Last) > a.policy().window
+}
+
+// attempts of [account] & [ip] in [scope], empty subject is ignored. caller holds 'mtxAttempt'
+func attemptsOf(scope, account, ip string, now time.Time) []*Attempt {
+	as := []*Attempt{}
+	for kind, subject := range map[string]string{"account": account, "ip": ip} {
+		if len(subject) == 0 {
+			continue
+		}
+		key := attemptKey(scope, kind, subject)
+		if existing, ok := mAttempt[key]; ok {
+			if !existing.expired(now) {
+				as = append(as, existing)
+				continue
+			}
+			delete(mAttempt, key)
+		}
+		as = append(as, &Attempt{Key: key, Scope: scope, Kind: kind, Subject: subject})
+	}
+	return as
+}
+
+// how long [account] & [ip] must wait before next attempt in [scope]
+func waitFor(scope, account, ip string) time.Duration {
+	mtxAttempt.Lock()
+	defer mtxAttempt.Unlock()
+
+	now := timeNow()
+	wait := time.Duration(0)
+	for _, a := range attemptsOf(scope, account, ip, now) {
+		if d := a.Until.Sub(now); d > wait {
+			wait = d
+		}
+	}
+	return wait
+}
+
+// outcome of an attempt reserved by 'reserve'
+const (
+	attemptFailed    = iota // reserved failure stays counted
+	attemptOK               // success clears failures of account unless success also counts in scope
+	attemptUncounted        // neither success nor failure, reserved failure is given back
+)
+
+// reserve next attempt of [account] & [ip] in [scope] if they need not wait, otherwise return how long to wait.
+// reserved attempt is counted as failure until 'settle' knows its outcome, so that concurrent attempts are
+// delayed by in-flight ones instead of all passing before any is recorded
+func reserve(scope, account, ip string) time.Duration {
+	mtxAttempt.Lock()
+	defer mtxAttempt.Unlock()
+
+	now := timeNow()
+	as := attemptsOf(scope, account, ip, now)
+	wait := time.Duration(0)
+	for _, a := range as {
+		if d := a.Until.Sub(now); d > wait {
+			wait = d
+		}
+	}
+	if wait > 0 {
+		return wait
+	}
+	for _, a := range as {
+		a.fail(now)
+	}
+	return 0
+}
+
+// settle [outcome] of attempt of [account] & [ip] in [scope] reserved by 'reserve'
+func settle(scope, account, ip string, outcome int) {
+	mtxAttempt.Lock()
+	defer mtxAttempt.Unlock()
+
+	for _, a := range attemptsOf(scope, account, ip, timeNow()) {
+		switch {
+		case outcome == attemptFailed, outcome == attemptOK && a.policy().countOK:
+		case outcome == attemptOK && a.Kind == "account":
+			delete(mAttempt, a.Key)
+		default:
+			a.unfail()
+		}
+	}
+}
+
+// record one attempt result of [account] & [ip] in [scope]. success clears failures of [account]
+// unless success also counts in [scope]
+func record(scope, account, ip string, failed bool) {
+	mtxAttempt.Lock()
+	defer mtxAttempt.Unlock()
+
+	now := timeNow()
+	for _, a := range attemptsOf(scope, account, ip, now) {
+		if !failed && !a.policy().countOK {
+			if a.Kind == "account" {
+				delete(mAttempt, a.Key)
+			}
+			continue
+		}
+		a.fail(now)
+	}
+}
+
+// count one failure of [a] at [now]. caller holds 'mtxAttempt'
+func (a *Attempt) fail(now time.Time) {
+	a.Failures++
+	a.Last = now
+	a.hold(now)
+	if a.Locked {
+		lk.Warn("sign [%s] locked out %s [%s] after %d attempts", a.Scope, a.Kind, a.Subject, a.Failures)
+	}
+	mAttempt[a.Key] = a
+}
+
+// give back one failure of [a], e.g. its reserved attempt was not counted. caller holds 'mtxAttempt'
+func (a *Attempt) unfail() {
+	if a.Failures == 0 {
+		return
+	}
+	if a.Failures--; a.Failures == 0 {
+		delete(mAttempt, a.Key)
+		return
+	}
+	a.hold(a.Last)
+}
+
+// delay or lock out next attempt of [a] from [from] by its failures
+func (a *Attempt) hold(from time.Time) {
+	p := a.policy()
+	a.Until, a.Locked = time.Time{}, false
+	switch n := a.Failures; {
+	case n >= p.lock:
+		a.Until, a.Locked = from.Add(p.lockFor), true
+	case n > p.free:
+		delay := p.maxDelay
+		if shift := n - p.free - 1; shift < 32 && p.base<<shift < p.maxDelay {
+			delay = p.base << shift
+		}
+		a.Until = from.Add(delay)
+	}
+}
+
+// account of sign attempt, i.e. form 'uname', owner of 2fa challenge being answered,
+// or signed-in caller, e.g. checking 2fa code for changing own 2fa
+func attemptAccount(c echo.Context) string {
+	if account := strings.TrimSpace(c.FormValue("uname")); len(account) > 0 {
+		return account
+	}
+	if token := c.FormValue("challenge"); len(token) > 0 {
+		return mfa.ChallengeOwner(token)
+	}
+	if userTkn, ok := c.Get("user").(*jwt.Token); ok {
+		if claims, ok := userTkn.Claims.(*u.UserClaims); ok {
+			return claims.UName
+		}
+	}
+	return ""
+}
+
+// protect sign api [h] of [scope] from brute-force. attempt is rejected with 429 while waiting for backoff
+// or locked out, otherwise it is reserved before [h] runs. 2xx response is a success unless it is marked pending, 4xx (except 429) is a failure,
+// others are not counted
+func Guard(scope string, h echo.HandlerFunc) echo.HandlerFunc {
+	_, ok := mScopePolicy[scope]
+	lk.FailOnErrWhen(!ok, "%v", fmt.Errorf("no attempt policy for sign scope '%s'", scope))
+
+	return func(c echo.Context) error {
+		var (
+			account = attemptAccount(c)
+			ip      = c.RealIP()
+		)
+		if wait := reserve(scope, account, ip); wait > 0 {
+			secs := int((wait + time.Second - 1) / time.Second)
+			c.Response().Header().Set("Retry-After", strconv.Itoa(secs))
+			return c.String(http.StatusTooManyRequests, fmt.Sprintf("too many attempts, retry after %d seconds", secs))
+		}
+		err := h(c)
+		outcome := attemptUncounted
+		switch status := c.Response().Status; {
+		case !c.Response().Committed:
+			// error left to echo error handler, not counted
+		case c.Get(ctxPending) != nil:
+			// e.g. password is ok but 2fa code is to be answered, neither success nor failure
+		case status >= 200 && status < 300:
+			outcome = attemptOK
+		case status >= 400 && status < 500 && status != http.StatusTooManyRequests:
+			outcome = attemptFailed
+		}
+		settle(scope, account, ip, outcome)
+		return err
+	}
+}
+
+// current attempts records with delay or lockout, locked ones first, then latest first
+func Lockouts() []*Attempt {
+	mtxAttempt.Lock()
+	defer mtxAttempt.Unlock()
+
+	now := timeNow()
+	sweep(now)
+	as := []*Attempt{}
+	for _, a := range mAttempt {
+		if a.Until.After(now) {
+			cp := *a
+			as = append(as, &cp)
+		}
+	}
+	sort.SliceStable(as, func(i, j int) bool {
+		if as[i].Locked != as[j].Locked {
+			return as[i].Locked
+		}
+		return as[i].Last.After(as[j].Last)
+	})
+	return as
+}
+
+// remove expired records. caller holds 'mtxAttempt'
+func sweep(now time.Time) {
+	for key, a := range mAttempt {
+		if a.expired(now) {
+			delete(mAttempt, key)
+		}
+	}
+}
+
+// sweep expired records every [interval] until [ctx] is done
+func sweepAttempts(ctx context.Context, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ticker.C:
+			mtxAttempt.Lock()
+			sweep(timeNow())
+			mtxAttempt.Unlock()
+		case <-ctx.Done():
+			return
+		}
+	}
+}
+
+// clear attempts record of [key] (scope ^ kind ^ subject). if [key] is empty, clear all. return count of cleared
+func clearLockout(key string) int {
+	mtxAttempt.Lock()
+	defer mtxAttempt.Unlock()
+
+	if len(key) == 0 {
+		n := len(mAttempt)
+		mAttempt = map[string]*Attempt{}
+		return n
+	}
+	if _, ok := mAttempt[key]; !ok {
+		return 0
+	}
+	delete(mAttempt, key)
+	return 1
+}
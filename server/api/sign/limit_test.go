@@ -0,0 +1,319 @@
+package sign
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"os"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	u "github.com/digisan/user-mgr/user"
+	"github.com/golang-jwt/jwt/v4"
+	"github.com/labstack/echo/v4"
+	"github.com/wismed-web/wisite-api/server/api/mfa"
+	"github.com/wismed-web/wisite-api/server/api/session"
+)
+
+func TestMain(m *testing.M) {
+	// 2fa & session dbs are opened by main for serving, in-memory in test
+	mfa.InitDB("")
+	session.InitDB("")
+	os.Exit(m.Run())
+}
+
+func call(h echo.HandlerFunc, method, target string, form url.Values, ip string) *httptest.ResponseRecorder {
+	req := httptest.NewRequest(method, target, strings.NewReader(form.Encode()))
+	req.Header.Set(echo.HeaderContentType, echo.MIMEApplicationForm)
+	req.Header.Set(echo.HeaderXRealIP, ip)
+	rec := httptest.NewRecorder()
+	h(echo.New().NewContext(req, rec))
+	return rec
+}
+
+func TestGuard(t *testing.T) {
+	now := time.Now()
+	timeNow = func() time.Time { return now }
+	defer func() { timeNow = time.Now; clearLockout("") }()
+
+	pwd := "right"
+	login := Guard("in", func(c echo.Context) error {
+		if c.FormValue("pwd") != pwd {
+			return c.String(http.StatusBadRequest, "incorrect password")
+		}
+		return c.JSON(http.StatusOK, "token")
+	})
+	try := func(uname, p, ip string) int {
+		return call(login, http.MethodPost, "/api/sign/in", url.Values{"uname": {uname}, "pwd": {p}}, ip).Code
+	}
+
+	// free failures, then exponential backoff
+	for i := 0; i < accountPolicy.free; i++ {
+		if code := try("g-alice", "wrong", "10.0.0.1"); code != http.StatusBadRequest {
+			t.Fatalf("free failure %d should be 400, got %d", i, code)
+		}
+	}
+	if code := try("g-alice", "wrong", "10.0.0.1"); code != http.StatusBadRequest {
+		t.Fatalf("first delayed failure should be 400, got %d", code)
+	}
+	rec := call(login, http.MethodPost, "/api/sign/in", url.Values{"uname": {"g-alice"}, "pwd": {pwd}}, "10.0.0.2")
+	if rec.Code != http.StatusTooManyRequests || rec.Header().Get("Retry-After") != "1" {
+		t.Fatalf("attempt within backoff should be 429 with Retry-After, got %d %v", rec.Code, rec.Header())
+	}
+	now = now.Add(time.Second)
+	try("g-alice", "wrong", "10.0.0.1")
+	if wait := waitFor("in", "g-alice", ""); wait != 2*time.Second {
+		t.Fatalf("backoff should double, got %v", wait)
+	}
+
+	// lockout
+	for i := accountPolicy.free + 2; i < accountPolicy.lock; i++ {
+		now = now.Add(accountPolicy.maxDelay)
+		try("g-alice", "wrong", "10.0.0.1")
+	}
+	ls := Lockouts()
+	if len(ls) == 0 || !ls[0].Locked || ls[0].Subject != "g-alice" || ls[0].Kind != "account" {
+		t.Fatalf("account should be locked out, got %v", ls)
+	}
+	now = now.Add(accountPolicy.lockFor - time.Minute)
+	if code := try("g-alice", pwd, "10.0.0.3"); code != http.StatusTooManyRequests {
+		t.Fatalf("locked account should be 429, got %d", code)
+	}
+
+	// other account is not affected, success clears account failures
+	if code := try("g-bob", pwd, "10.0.0.3"); code != http.StatusOK {
+		t.Fatalf("other account should log in, got %d", code)
+	}
+	now = now.Add(time.Minute)
+	if code := try("g-alice", pwd, "10.0.0.3"); code != http.StatusOK {
+		t.Fatalf("lockout should end, got %d", code)
+	}
+	if wait := waitFor("in", "g-alice", ""); wait != 0 {
+		t.Fatalf("success should clear account failures, got %v", wait)
+	}
+
+	// ip failures across accounts
+	for i := 0; i < ipPolicy.free+1; i++ {
+		try("g-user"+string(rune('a'+i)), "wrong", "10.0.0.9")
+	}
+	if code := try("g-carol", pwd, "10.0.0.9"); code != http.StatusTooManyRequests {
+		t.Fatalf("ip in backoff should be 429, got %d", code)
+	}
+
+	// success also counts in email sending scope
+	sendCode := Guard("reset-pwd", func(c echo.Context) error { return c.JSON(http.StatusOK, "sent") })
+	for i := 0; i <= accountPolicy.free; i++ {
+		call(sendCode, http.MethodPost, "/api/sign/reset-pwd", url.Values{"uname": {"g-dave"}}, "10.0.1.1")
+	}
+	if rec := call(sendCode, http.MethodPost, "/api/sign/reset-pwd", url.Values{"uname": {"g-dave"}}, "10.0.1.2"); rec.Code != http.StatusTooManyRequests {
+		t.Fatalf("repeated code sending should be 429, got %d", rec.Code)
+	}
+}
+
+func TestGuardConcurrent(t *testing.T) {
+	now := time.Now()
+	timeNow = func() time.Time { return now }
+	defer func() { timeNow = time.Now; clearLockout("") }()
+
+	// burst of guesses for one account from fresh ips, each guess is checked slowly
+	var (
+		entered, done atomic.Int32
+		release       = make(chan struct{})
+		n             = 4 * accountPolicy.lock
+		wg            sync.WaitGroup
+	)
+	login := Guard("in", func(c echo.Context) error {
+		entered.Add(1)
+		<-release
+		return c.String(http.StatusBadRequest, "incorrect password")
+	})
+	codes := make([]int, n)
+	for i := 0; i < n; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			defer done.Add(1)
+			codes[i] = call(login, http.MethodPost, "/api/sign/in", url.Values{"uname": {"c-alice"}, "pwd": {"wrong"}}, fmt.Sprintf("10.0.7.%d", i+1)).Code
+		}(i)
+	}
+	for entered.Load()+done.Load() < int32(n) {
+		time.Sleep(time.Millisecond)
+	}
+	close(release)
+	wg.Wait()
+
+	checked := 0
+	for _, code := range codes {
+		if code == http.StatusBadRequest {
+			checked++
+		}
+	}
+	if checked != accountPolicy.free+1 || int(entered.Load()) != checked {
+		t.Fatalf("in-flight guesses should delay others, %d of %d were checked", checked, n)
+	}
+	if a := mAttempt[attemptKey("in", "account", "c-alice")]; a == nil || a.Failures != checked {
+		t.Fatalf("checked guesses should stay counted, got %v", a)
+	}
+
+	// attempt not counted gives back its reserved failure
+	now = now.Add(accountPolicy.maxDelay)
+	broken := Guard("in", func(c echo.Context) error { return echo.ErrInternalServerError })
+	call(broken, http.MethodPost, "/api/sign/in", url.Values{"uname": {"c-alice"}}, "10.0.7.1")
+	if a := mAttempt[attemptKey("in", "account", "c-alice")]; a == nil || a.Failures != checked {
+		t.Fatalf("uncounted attempt should not add failure, got %v", a)
+	}
+	if a := mAttempt[attemptKey("in", "ip", "10.0.7.1")]; a == nil || a.Failures != 1 {
+		t.Fatalf("uncounted attempt should not add ip failure, got %v", a)
+	}
+}
+
+func TestGuard2FA(t *testing.T) {
+	now := time.Now()
+	timeNow = func() time.Time { return now }
+	defer func() { timeNow = time.Now; clearLockout("") }()
+
+	// correct password waiting for 2fa is not a success, account failures are kept
+	login := Guard("in", func(c echo.Context) error {
+		if c.FormValue("pwd") != "right" {
+			return c.String(http.StatusBadRequest, "incorrect password")
+		}
+		c.Set(ctxPending, true)
+		return c.JSON(http.StatusOK, echo.Map{"mfa": "totp"})
+	})
+	for i := 0; i <= accountPolicy.free; i++ {
+		call(login, http.MethodPost, "/api/sign/in", url.Values{"uname": {"t-alice"}, "pwd": {"wrong"}}, "10.0.4.1")
+	}
+	now = now.Add(accountPolicy.maxDelay)
+	if rec := call(login, http.MethodPost, "/api/sign/in", url.Values{"uname": {"t-alice"}, "pwd": {"right"}}, "10.0.4.1"); rec.Code != http.StatusOK {
+		t.Fatalf("correct password should be 200, got %d", rec.Code)
+	}
+	if a := mAttempt[attemptKey("in", "account", "t-alice")]; a == nil || a.Failures != accountPolicy.free+1 {
+		t.Fatalf("pending sign-in should keep account failures, got %v", a)
+	}
+
+	// wrong 2fa codes count against challenge owner, even with fresh challenge & ip each time
+	if err := mfa.SetRequireAdmin(true); err != nil {
+		t.Fatal(err)
+	}
+	defer mfa.SetRequireAdmin(false)
+	admin := &u.User{Core: u.Core{UName: "t-admin"}, Admin: u.Admin{MemLevel: 3}}
+	answer := Guard("verify", LogIn2FA)
+	code := 0
+	for i := 0; i <= accountPolicy.free+1; i++ {
+		_, challenge, _, _, err := mfa.Challenge(admin)
+		if err != nil {
+			t.Fatal(err)
+		}
+		code = call(answer, http.MethodPost, "/api/sign/in-2fa", url.Values{"challenge": {challenge}, "code": {"000000"}}, fmt.Sprintf("10.0.5.%d", i+1)).Code
+	}
+	if code != http.StatusTooManyRequests {
+		t.Fatalf("2fa guessing should be limited per account, got %d", code)
+	}
+}
+
+func TestGuardSignedIn(t *testing.T) {
+	defer clearLockout("")
+
+	// e.g. disabling own 2fa, wrong codes count against signed-in caller even from fresh ip each time
+	disable := Guard("verify", func(c echo.Context) error {
+		return c.String(http.StatusBadRequest, "incorrect 2fa code")
+	})
+	code := 0
+	for i := 0; i <= accountPolicy.free+1; i++ {
+		code = call(func(c echo.Context) error {
+			c.Set("user", &jwt.Token{Claims: &u.UserClaims{Core: u.Core{UName: "s-alice"}}})
+			return disable(c)
+		}, http.MethodPost, "/api/mfa/disable", url.Values{"code": {"000000"}}, fmt.Sprintf("10.0.6.%d", i+1)).Code
+	}
+	if code != http.StatusTooManyRequests {
+		t.Fatalf("2fa code guessing of signed-in user should be limited per account, got %d", code)
+	}
+	if a := mAttempt[attemptKey("verify", "account", "s-alice")]; a == nil {
+		t.Fatal("signed-in caller should be counted as account")
+	}
+}
+
+func TestGuardSpoofedIP(t *testing.T) {
+	defer clearLockout("")
+
+	for _, proxies := range [][]string{nil, {"192.168.9.1"}} {
+		ext, err := IPExtractor(proxies...)
+		if err != nil {
+			t.Fatal(err)
+		}
+		e := echo.New()
+		e.IPExtractor = ext
+		e.POST("/api/sign/in", Guard("in", func(c echo.Context) error {
+			return c.String(http.StatusBadRequest, "incorrect password")
+		}))
+
+		// each attempt makes up a new forwarded ip & account, only the peer (or client seen by trusted proxy) counts
+		code := 0
+		for i := 0; i <= ipPolicy.free+1; i++ {
+			form := url.Values{"uname": {fmt.Sprintf("x-user%d", i)}, "pwd": {"wrong"}}
+			req := httptest.NewRequest(http.MethodPost, "/api/sign/in", strings.NewReader(form.Encode()))
+			req.Header.Set(echo.HeaderContentType, echo.MIMEApplicationForm)
+			req.Header.Set(echo.HeaderXForwardedFor, fmt.Sprintf("10.9.%d.%d", i/250, i%250+1))
+			req.RemoteAddr = "10.0.3.1:40000"
+			if len(proxies) > 0 {
+				req.Header.Set(echo.HeaderXForwardedFor, fmt.Sprintf("10.9.%d.%d, 10.0.3.1", i/250, i%250+1))
+				req.RemoteAddr = proxies[0] + ":40000"
+			}
+			rec := httptest.NewRecorder()
+			e.ServeHTTP(rec, req)
+			code = rec.Code
+		}
+		if code != http.StatusTooManyRequests {
+			t.Fatalf("rotating X-Forwarded-For should not escape ip backoff (proxies %v), got %d", proxies, code)
+		}
+		clearLockout("")
+	}
+}
+
+func TestLockoutAdmin(t *testing.T) {
+	defer clearLockout("")
+	for _, user := range []*u.User{
+		{Core: u.Core{UName: "l-admin", Email: "l-admin@fake.net"}, Admin: u.Admin{Active: true, MemLevel: 3}},
+		{Core: u.Core{UName: "l-user", Email: "l-user@fake.net"}, Admin: u.Admin{Active: true}},
+	} {
+		if err := u.UpdateUser(user); err != nil {
+			t.Fatal(err)
+		}
+	}
+	invoke := func(h echo.HandlerFunc, method, uname, query string) *httptest.ResponseRecorder {
+		rec := httptest.NewRecorder()
+		c := echo.New().NewContext(httptest.NewRequest(method, "/?"+query, nil), rec)
+		c.Set("user", &jwt.Token{Claims: &u.UserClaims{Core: u.Core{UName: uname}}})
+		h(c)
+		return rec
+	}
+
+	for i := 0; i < accountPolicy.lock; i++ {
+		record("in", "l-victim", "10.0.2.1", true)
+	}
+	if rec := invoke(LockoutList, http.MethodGet, "l-user", ""); rec.Code != http.StatusUnauthorized {
+		t.Fatalf("non-admin should be 401, got %d", rec.Code)
+	}
+	rec := invoke(LockoutList, http.MethodGet, "l-admin", "")
+	ls := []*Attempt{}
+	if err := json.Unmarshal(rec.Body.Bytes(), &ls); err != nil || len(ls) != 1 || ls[0].Subject != "l-victim" {
+		t.Fatalf("unexpected lockout list: %s", rec.Body.String())
+	}
+	if rec := invoke(ClearLockout, http.MethodDelete, "l-admin", "key="+url.QueryEscape(ls[0].Key)); rec.Code != http.StatusOK {
+		t.Fatalf("clear should be 200, got %d %s", rec.Code, rec.Body.String())
+	}
+	if wait := waitFor("in", "l-victim", ""); wait != 0 {
+		t.Fatalf("cleared account should not wait, got %v", wait)
+	}
+	if rec := invoke(ClearLockout, http.MethodDelete, "l-admin", "key=nothing"); rec.Code != http.StatusNotFound {
+		t.Fatalf("unknown key should be 404, got %d", rec.Code)
+	}
+	if rec := invoke(ClearLockout, http.MethodDelete, "l-admin", "key=all"); rec.Code != http.StatusOK || rec.Body.String() != "1\n" {
+		t.Fatalf("clear all should clear ip record, got %d %s", rec.Code, rec.Body.String())
+	}
+}
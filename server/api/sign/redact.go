@@ -0,0 +1,23 @@
+package sign
+
+import "strings"
+
+// secrets (password, api token, verification code) must go through redact before logging
+
+// masked [secret] for logging, only tells if it is empty
+func redact(secret string) string {
+	if len(secret) == 0 {
+		return ""
+	}
+	return "******"
+}
+
+// [msg] with each non-empty one of [secrets] masked, e.g. for upstream error message echoing request
+func redactIn(msg string, secrets ...string) string {
+	for _, secret := range secrets {
+		if len(secret) > 0 {
+			msg = strings.ReplaceAll(msg, secret, redact(secret))
+		}
+	}
+	return msg
+}
@@ -1 +1,283 @@
 package vote
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	em "github.com/digisan/event-mgr"
+	lk "github.com/digisan/logkit"
+	u "github.com/digisan/user-mgr/user"
+	"github.com/golang-jwt/jwt/v4"
+	"github.com/labstack/echo/v4"
+)
+
+// *** after implementing, register with path in 'vote.go' *** //
+
+// fetch a living vote event & its Vote content. (nil, nil, nil) if not found
+func fetchVote(id string) (*em.Event, *Vote, error) {
+	event, err := em.FetchEvent(true, id)
+	if err != nil {
+		return nil, nil, err
+	}
+	if event == nil || event.EvtType != EvtType {
+		return nil, nil, nil
+	}
+	V := &Vote{}
+	if err := json.Unmarshal([]byte(event.RawJSON), V); err != nil {
+		lk.Warn("Unmarshal Vote Error, event is %v", event)
+		return nil, nil, fmt.Errorf("convert RawJSON to [Vote] Unmarshal error")
+	}
+	return event, V, nil
+}
+
+// "0,2" => [0, 2]
+func parseIdx(s string) ([]int, error) {
+	idxGrp := []int{}
+	for _, str := range strings.Split(s, ",") {
+		if str = strings.TrimSpace(str); len(str) == 0 {
+			continue
+		}
+		idx, err := strconv.Atoi(str)
+		if err != nil {
+			return nil, fmt.Errorf("'%s' is not a valid option index", str)
+		}
+		idxGrp = append(idxGrp, idx)
+	}
+	return idxGrp, nil
+}
+
+// @Title Vote template
+// @Summary get Vote template for dev reference.
+// @Description
+// @Tags    Vote
+// @Accept  json
+// @Produce json
+// @Success 200 "OK - get template successfully"
+// @Router /api/vote/template [get]
+// @Security ApiKeyAuth
+func Template(c echo.Context) error {
+	return c.JSON(http.StatusOK, Vote{
+		Topic:               "Vote topic",
+		Keywords:            "keywords for this Vote",
+		Options:             []string{"option A", "option B", "option C"},
+		Deadline:            time.Now().Add(7 * 24 * time.Hour).Truncate(time.Second),
+		Multiple:            false,
+		ResultAfterDeadline: false,
+	})
+}
+
+// @Title create a Vote
+// @Summary create a Vote by filling a Vote template.
+// @Description
+// @Tags    Vote
+// @Accept  json
+// @Produce json
+// @Param   data body string true "filled Vote template json file"
+// @Success 200 "OK - create successfully, return Vote ID"
+// @Failure 400 "Fail - incorrect Vote format"
+// @Failure 500 "Fail - internal error"
+// @Router /api/vote/create [post]
+// @Security ApiKeyAuth
+func Create(c echo.Context) error {
+	var (
+		userTkn = c.Get("user").(*jwt.Token)
+		claims  = userTkn.Claims.(*u.UserClaims)
+		uname   = claims.UName
+	)
+
+	V := new(Vote)
+	if err := c.Bind(V); err != nil {
+		lk.Warn("incorrect Uploaded Vote format: " + err.Error())
+		return c.String(http.StatusBadRequest, "incorrect Vote format: "+err.Error())
+	}
+	if err := V.Validate(); err != nil {
+		return c.String(http.StatusBadRequest, err.Error())
+	}
+	lk.Log("Creating Vote ---> [%s] --- %v", uname, V)
+
+	data, err := json.Marshal(V)
+	if err != nil {
+		return c.String(http.StatusInternalServerError, err.Error())
+	}
+
+	evt := em.NewEvent("", uname, EvtType, string(data), "")
+	if err = em.AddEvent(evt); err != nil {
+		return c.String(http.StatusInternalServerError, err.Error())
+	}
+	return c.JSON(http.StatusOK, evt.ID)
+}
+
+// @Title get one Vote
+// @Summary get one Vote content with its status.
+// @Description
+// @Tags    Vote
+// @Accept  json
+// @Produce json
+// @Param   id query string true "Vote ID"
+// @Success 200 "OK - get Vote successfully"
+// @Failure 400 "Fail - incorrect query param id"
+// @Failure 404 "Fail - not found"
+// @Failure 500 "Fail - internal error"
+// @Router /api/vote/one [get]
+// @Security ApiKeyAuth
+func GetOne(c echo.Context) error {
+	var (
+		id = c.QueryParam("id")
+	)
+	if len(id) == 0 {
+		return c.String(http.StatusBadRequest, "'id' is invalid (cannot be empty)")
+	}
+
+	event, V, err := fetchVote(id)
+	if err != nil {
+		return c.String(http.StatusInternalServerError, err.Error())
+	}
+	if event == nil {
+		return c.String(http.StatusNotFound, fmt.Sprintf("Vote not found @%s", id))
+	}
+
+	return c.JSON(http.StatusOK, struct {
+		ID     string    `json:"id"`
+		Owner  string    `json:"owner"`
+		Tm     time.Time `json:"tm"`
+		Vote   *Vote     `json:"vote"`
+		Closed bool      `json:"closed"`
+	}{
+		event.ID, event.Owner, event.Tm, V, V.Closed(),
+	})
+}
+
+func ballotAction(c echo.Context, change bool) error {
+	var (
+		userTkn = c.Get("user").(*jwt.Token)
+		claims  = userTkn.Claims.(*u.UserClaims)
+		uname   = claims.UName
+		id      = c.Param("id")
+	)
+
+	idxGrp, err := parseIdx(c.QueryParam("options"))
+	if err != nil {
+		return c.String(http.StatusBadRequest, err.Error())
+	}
+
+	event, V, err := fetchVote(id)
+	if err != nil {
+		return c.String(http.StatusInternalServerError, err.Error())
+	}
+	if event == nil {
+		return c.String(http.StatusNotFound, fmt.Sprintf("Vote not found @%s", id))
+	}
+
+	chosen, err := castBallot(id, V, uname, change, idxGrp...)
+	switch {
+	case err == errBallotExists:
+		return c.String(http.StatusConflict, err.Error())
+	case err == errBallotMissing:
+		return c.String(http.StatusNotFound, err.Error())
+	case err == errVoteClosed:
+		return c.String(http.StatusForbidden, err.Error())
+	case err != nil:
+		return c.String(http.StatusBadRequest, err.Error())
+	}
+	return c.JSON(http.StatusOK, chosen)
+}
+
+// @Title cast a ballot
+// @Summary cast current user's ballot for a Vote. one user can only cast one ballot.
+// @Description
+// @Tags    Vote
+// @Accept  json
+// @Produce json
+// @Param   id      path  string true "Vote ID"
+// @Param   options query string true "chosen option indices, separated by ',' e.g. '0,2'"
+// @Success 200 "OK - cast successfully, return chosen option indices"
+// @Failure 400 "Fail - invalid options"
+// @Failure 403 "Fail - Vote is closed"
+// @Failure 404 "Fail - Vote not found"
+// @Failure 409 "Fail - ballot already cast"
+// @Failure 500 "Fail - internal error"
+// @Router /api/vote/cast/{id} [post]
+// @Security ApiKeyAuth
+func Cast(c echo.Context) error {
+	return ballotAction(c, false)
+}
+
+// @Title change a ballot
+// @Summary change current user's cast ballot for a Vote before its deadline.
+// @Description
+// @Tags    Vote
+// @Accept  json
+// @Produce json
+// @Param   id      path  string true "Vote ID"
+// @Param   options query string true "new chosen option indices, separated by ',' e.g. '0,2'"
+// @Success 200 "OK - change successfully, return chosen option indices"
+// @Failure 400 "Fail - invalid options"
+// @Failure 403 "Fail - Vote is closed"
+// @Failure 404 "Fail - Vote or ballot not found"
+// @Failure 500 "Fail - internal error"
+// @Router /api/vote/change/{id} [put]
+// @Security ApiKeyAuth
+func Change(c echo.Context) error {
+	return ballotAction(c, true)
+}
+
+// @Title get Vote tally
+// @Summary get live tally of a Vote and current user's ballot.
+// @Description tally counts are hidden before deadline if Vote is set 'resultAfterDeadline'.
+// @Tags    Vote
+// @Accept  json
+// @Produce json
+// @Param   id path string true "Vote ID"
+// @Success 200 "OK - get tally successfully"
+// @Failure 404 "Fail - Vote not found"
+// @Failure 500 "Fail - internal error"
+// @Router /api/vote/tally/{id} [get]
+// @Security ApiKeyAuth
+func Tally(c echo.Context) error {
+	var (
+		userTkn = c.Get("user").(*jwt.Token)
+		claims  = userTkn.Claims.(*u.UserClaims)
+		uname   = claims.UName
+		id      = c.Param("id")
+	)
+
+	event, V, err := fetchVote(id)
+	if err != nil {
+		return c.String(http.StatusInternalServerError, err.Error())
+	}
+	if event == nil {
+		return c.String(http.StatusNotFound, fmt.Sprintf("Vote not found @%s", id))
+	}
+
+	ep, err := em.NewEventParticipate(id, true)
+	if err != nil {
+		return c.String(http.StatusInternalServerError, err.Error())
+	}
+
+	type Result struct {
+		Options []string `json:"options"`
+		Counts  []int    `json:"counts"`
+		Voters  int      `json:"voters"`
+		Hidden  bool     `json:"hidden"`
+		Closed  bool     `json:"closed"`
+		Mine    []int    `json:"mine"`
+	}
+	rst := Result{
+		Options: V.Options,
+		Counts:  []int{},
+		Voters:  0,
+		Hidden:  !V.TallyVisible(),
+		Closed:  V.Closed(),
+		Mine:    ballot(ep, V, uname),
+	}
+	if !rst.Hidden {
+		if rst.Counts, rst.Voters, err = tally(id, V); err != nil {
+			return c.String(http.StatusInternalServerError, err.Error())
+		}
+	}
+	return c.JSON(http.StatusOK, rst)
+}
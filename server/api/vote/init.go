@@ -1 +0,0 @@
-package vote
\ No newline at end of file
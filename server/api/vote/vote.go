@@ -1,6 +1,169 @@
 package vote
 
+import (
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	em "github.com/digisan/event-mgr"
+	. "github.com/digisan/go-generics/v2"
+)
+
+const (
+	EvtType = "Vote"
+	catItem = "vote-item-" // participate category for each option, e.g. "vote-item-0"
+)
+
+var (
+	mtxBallot = &sync.Mutex{} // guard ballot read-modify-write, one user one ballot
+
+	errBallotExists  = errors.New("you have already cast a ballot, change it instead")
+	errBallotMissing = errors.New("you have not cast a ballot yet")
+	errVoteClosed    = errors.New("vote is closed, ballot is not accepted")
+)
+
 type Vote struct {
-	Topic    string `json:"topic"`
-	Keywords string `json:"keywords"`	
+	Topic               string    `json:"topic"`
+	Keywords            string    `json:"keywords"`
+	Options             []string  `json:"options"`
+	Deadline            time.Time `json:"deadline"`            // zero value means no deadline
+	Multiple            bool      `json:"multiple"`            // true: multiple choice; false: single choice
+	ResultAfterDeadline bool      `json:"resultAfterDeadline"` // true: tally is hidden until deadline
+}
+
+func (v Vote) String() string {
+	sb := strings.Builder{}
+	sb.WriteString(fmt.Sprintf("\nTopic: %v\n", v.Topic))
+	sb.WriteString(fmt.Sprintf("Keywords: %v\n", v.Keywords))
+	for i, opt := range v.Options {
+		sb.WriteString(fmt.Sprintf("\t\tOption %d: %v\n", i, opt))
+	}
+	sb.WriteString(fmt.Sprintf("Deadline: %v\n", v.Deadline))
+	sb.WriteString(fmt.Sprintf("Multiple: %v\n", v.Multiple))
+	sb.WriteString(fmt.Sprintf("ResultAfterDeadline: %v\n", v.ResultAfterDeadline))
+	return sb.String()
+}
+
+func (v *Vote) Validate() error {
+	v.Topic = strings.TrimSpace(v.Topic)
+	if len(v.Topic) == 0 {
+		return errors.New("vote topic cannot be empty")
+	}
+	for i, opt := range v.Options {
+		v.Options[i] = strings.TrimSpace(opt)
+	}
+	FilterFast(&v.Options, func(i int, e string) bool { return len(e) > 0 })
+	if len(v.Options) < 2 {
+		return errors.New("vote needs at least 2 non-empty options")
+	}
+	if len(Settify(v.Options...)) != len(v.Options) {
+		return errors.New("vote options must be unique")
+	}
+	if !v.Deadline.IsZero() && v.Deadline.Before(time.Now()) {
+		return errors.New("vote deadline must be in the future")
+	}
+	if v.Deadline.IsZero() && v.ResultAfterDeadline {
+		return errors.New("'resultAfterDeadline' needs a valid deadline")
+	}
+	return nil
+}
+
+func (v *Vote) Closed() bool {
+	return !v.Deadline.IsZero() && time.Now().After(v.Deadline)
+}
+
+func (v *Vote) TallyVisible() bool {
+	return !v.ResultAfterDeadline || v.Closed()
+}
+
+// validate option indices for a ballot, return sorted unique indices
+func (v *Vote) checkBallot(idxGrp ...int) ([]int, error) {
+	idxGrp = Settify(idxGrp...)
+	sort.Ints(idxGrp)
+	if len(idxGrp) == 0 {
+		return nil, errors.New("a ballot must choose at least one option")
+	}
+	if !v.Multiple && len(idxGrp) > 1 {
+		return nil, errors.New("this is a single choice vote, only one option can be chosen")
+	}
+	for _, idx := range idxGrp {
+		if idx < 0 || idx >= len(v.Options) {
+			return nil, fmt.Errorf("option index [%d] is out of range [0, %d)", idx, len(v.Options))
+		}
+	}
+	return idxGrp, nil
+}
+
+func category(idx int) string {
+	return fmt.Sprintf("%s%d", catItem, idx)
+}
+
+// current ballot (chosen option indices) of a user, empty if not voted yet
+func ballot(ep *em.EventParticipate, v *Vote, uname string) []int {
+	idxGrp := []int{}
+	for i := range v.Options {
+		if ep.HasPtp(category(i), uname) {
+			idxGrp = append(idxGrp, i)
+		}
+	}
+	return idxGrp
+}
+
+// replace a user's ballot. if [change] is false, fail when user has already voted
+func castBallot(id string, v *Vote, uname string, change bool, idxGrp ...int) ([]int, error) {
+	mtxBallot.Lock()
+	defer mtxBallot.Unlock()
+
+	if v.Closed() {
+		return nil, errVoteClosed
+	}
+	idxGrp, err := v.checkBallot(idxGrp...)
+	if err != nil {
+		return nil, err
+	}
+	ep, err := em.NewEventParticipate(id, true)
+	if err != nil {
+		return nil, err
+	}
+	prev := ballot(ep, v, uname)
+	switch {
+	case !change && len(prev) > 0:
+		return nil, errBallotExists
+	case change && len(prev) == 0:
+		return nil, errBallotMissing
+	}
+	for _, idx := range prev {
+		if _, err := ep.RmPtps(category(idx), uname); err != nil {
+			return nil, err
+		}
+	}
+	for _, idx := range idxGrp {
+		if err := ep.AddPtps(category(idx), uname); err != nil {
+			return nil, err
+		}
+	}
+	return idxGrp, nil
+}
+
+// count for each option & total voters
+func tally(id string, v *Vote) ([]int, int, error) {
+	ep, err := em.NewEventParticipate(id, true)
+	if err != nil {
+		return nil, 0, err
+	}
+	counts := make([]int, len(v.Options))
+	voters := []string{}
+	for i := range v.Options {
+		ptps, err := ep.Ptps(category(i))
+		if err != nil {
+			return nil, 0, err
+		}
+		FilterFast(&ptps, func(i int, e string) bool { return len(e) > 0 }) // emptied category is stored as ""
+		counts[i] = len(ptps)
+		voters = append(voters, ptps...)
+	}
+	return counts, len(Settify(voters...)), nil
 }
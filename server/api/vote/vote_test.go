@@ -0,0 +1,110 @@
+package vote
+
+import (
+	"context"
+	"os"
+	"reflect"
+	"testing"
+	"time"
+
+	em "github.com/digisan/event-mgr"
+)
+
+func TestMain(m *testing.M) {
+	dir, err := os.MkdirTemp("", "vote-test")
+	if err != nil {
+		panic(err)
+	}
+	em.InitDB(dir)
+	em.InitEventSpan("MINUTE", context.Background())
+	code := m.Run()
+	os.RemoveAll(dir)
+	os.Exit(code)
+}
+
+func newVote(t *testing.T) string {
+	evt := em.NewEvent("", "v-owner", EvtType, "{}", "")
+	if err := em.AddEvent(evt); err != nil {
+		t.Fatal(err)
+	}
+	return evt.ID
+}
+
+func TestBallot(t *testing.T) {
+	v := &Vote{Topic: "lunch", Options: []string{"rice", "noodle", "bread"}}
+	if err := v.Validate(); err != nil {
+		t.Fatal(err)
+	}
+	id := newVote(t)
+
+	for _, idx := range [][]int{{}, {0, 1}, {3}, {-1}} {
+		if _, err := castBallot(id, v, "v-alice", false, idx...); err == nil {
+			t.Fatalf("invalid ballot %v should fail", idx)
+		}
+	}
+	if chosen, err := castBallot(id, v, "v-alice", false, 1, 1); err != nil || !reflect.DeepEqual(chosen, []int{1}) {
+		t.Fatalf("cast: %v %v", chosen, err)
+	}
+	if _, err := castBallot(id, v, "v-bob", true, 0); err != errBallotMissing {
+		t.Fatalf("change before cast should fail, got %v", err)
+	}
+	if _, err := castBallot(id, v, "v-bob", false, 1); err != nil {
+		t.Fatal(err)
+	}
+
+	counts, voters, err := tally(id, v)
+	if err != nil || !reflect.DeepEqual(counts, []int{0, 2, 0}) || voters != 2 {
+		t.Fatalf("tally: %v %d %v", counts, voters, err)
+	}
+}
+
+func TestDoubleVote(t *testing.T) {
+	v := &Vote{Topic: "colors", Options: []string{"red", "green", "blue"}, Multiple: true}
+	id := newVote(t)
+
+	if _, err := castBallot(id, v, "v-carol", false, 0, 2); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := castBallot(id, v, "v-carol", false, 1); err != errBallotExists {
+		t.Fatalf("second ballot should fail, got %v", err)
+	}
+	if chosen, err := castBallot(id, v, "v-carol", true, 1); err != nil || !reflect.DeepEqual(chosen, []int{1}) {
+		t.Fatalf("change: %v %v", chosen, err)
+	}
+
+	ep, err := em.NewEventParticipate(id, true)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if mine := ballot(ep, v, "v-carol"); !reflect.DeepEqual(mine, []int{1}) {
+		t.Fatalf("changed ballot should replace old one, got %v", mine)
+	}
+	counts, voters, err := tally(id, v)
+	if err != nil || !reflect.DeepEqual(counts, []int{0, 1, 0}) || voters != 1 {
+		t.Fatalf("tally: %v %d %v", counts, voters, err)
+	}
+}
+
+func TestClosing(t *testing.T) {
+	v := &Vote{Topic: "done", Options: []string{"yes", "no"}, Deadline: time.Now().Add(-time.Minute)}
+	if err := v.Validate(); err == nil {
+		t.Fatal("past deadline should be rejected at creating")
+	}
+	if (&Vote{Topic: "t", Options: []string{"a", "b"}, ResultAfterDeadline: true}).Validate() == nil {
+		t.Fatal("'resultAfterDeadline' without deadline should be rejected")
+	}
+
+	v.ResultAfterDeadline = true
+	id := newVote(t)
+	if !v.Closed() || !v.TallyVisible() {
+		t.Fatal("vote after deadline should be closed with visible tally")
+	}
+	if _, err := castBallot(id, v, "v-dave", false, 0); err != errVoteClosed {
+		t.Fatalf("closed vote should not accept ballot, got %v", err)
+	}
+
+	open := &Vote{Topic: "t", Options: []string{"a", "b"}, Deadline: time.Now().Add(time.Hour), ResultAfterDeadline: true}
+	if open.Closed() || open.TallyVisible() {
+		t.Fatal("tally should be hidden before deadline")
+	}
+}
@@ -1,5 +1,4 @@
-// Package docs GENERATED BY SWAG; DO NOT EDIT
-// This file was generated by swaggo/swag
+// Code generated by swaggo/swag. DO NOT EDIT
 package docs
 
 import "github.com/swaggo/swag"
@@ -71,6 +70,53 @@ const docTemplate = `{
                 }
             }
         },
+        "/api/admin/analytics/top": {
+            "get": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Admin"
+                ],
+                "summary": "admin gets most viewed Posts (with engagement) and authors in a month.",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "month as 'yyyymm', default is current month",
+                        "name": "period",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "max count of Posts \u0026 authors, default is 10, max is 100",
+                        "name": "top",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK - get top list successfully"
+                    },
+                    "400": {
+                        "description": "Fail - incorrect query param"
+                    },
+                    "401": {
+                        "description": "Fail - unauthorized error"
+                    },
+                    "500": {
+                        "description": "Fail - internal error"
+                    }
+                }
+            }
+        },
         "/api/admin/avatar": {
             "get": {
                 "security": [
@@ -110,15 +156,15 @@ const docTemplate = `{
                 }
             }
         },
-        "/api/admin/officialize": {
-            "put": {
+        "/api/admin/category/del/{id}": {
+            "delete": {
                 "security": [
                     {
                         "ApiKeyAuth": []
                     }
                 ],
                 "consumes": [
-                    "multipart/form-data"
+                    "application/json"
                 ],
                 "produces": [
                     "application/json"
@@ -126,29 +172,66 @@ const docTemplate = `{
                 "tags": [
                     "Admin"
                 ],
-                "summary": "officialize or un-officialize a user",
+                "summary": "admin deletes a Post category. Posts in it are no longer listed by category.",
                 "parameters": [
                     {
                         "type": "string",
-                        "description": "unique user name",
-                        "name": "uname",
-                        "in": "formData",
+                        "description": "category id",
+                        "name": "id",
+                        "in": "path",
                         "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK - delete successfully"
+                    },
+                    "401": {
+                        "description": "Fail - unauthorized error"
+                    },
+                    "404": {
+                        "description": "Fail - category not found"
                     },
+                    "500": {
+                        "description": "Fail - internal error"
+                    }
+                }
+            }
+        },
+        "/api/admin/category/save": {
+            "post": {
+                "security": [
                     {
-                        "type": "string",
-                        "description": "true: officialize, false: un-officialize",
-                        "name": "flag",
-                        "in": "formData",
-                        "required": true
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Admin"
+                ],
+                "summary": "admin creates a Post category, or updates its name \u0026 description if id exists.",
+                "parameters": [
+                    {
+                        "description": "category json, e.g. {'id': 'covid-19', 'name': 'COVID-19', 'desc': 'pandemic related'}",
+                        "name": "data",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "type": "string"
+                        }
                     }
                 ],
                 "responses": {
                     "200": {
-                        "description": "OK - action successfully"
+                        "description": "OK - save successfully"
                     },
                     "400": {
-                        "description": "Fail - invalid true/false flag"
+                        "description": "Fail - incorrect category format or id"
                     },
                     "401": {
                         "description": "Fail - unauthorized error"
@@ -159,8 +242,8 @@ const docTemplate = `{
                 }
             }
         },
-        "/api/admin/onlines": {
-            "get": {
+        "/api/admin/lockout": {
+            "delete": {
                 "security": [
                     {
                         "ApiKeyAuth": []
@@ -175,29 +258,36 @@ const docTemplate = `{
                 "tags": [
                     "Admin"
                 ],
-                "summary": "get all online users",
+                "summary": "clear attempts record of one account or client ip, or all records if key is 'all'.",
                 "parameters": [
                     {
                         "type": "string",
-                        "description": "user filter with uname wildcard(*)",
-                        "name": "uname",
-                        "in": "query"
+                        "description": "'key' of one lockout item, or 'all'",
+                        "name": "key",
+                        "in": "query",
+                        "required": true
                     }
                 ],
                 "responses": {
                     "200": {
-                        "description": "OK - list successfully"
+                        "description": "OK - clear successfully, return count of cleared"
+                    },
+                    "400": {
+                        "description": "Fail - missing key"
                     },
                     "401": {
                         "description": "Fail - unauthorized error"
                     },
+                    "404": {
+                        "description": "Fail - key not found"
+                    },
                     "500": {
                         "description": "Fail - internal error"
                     }
                 }
             }
         },
-        "/api/admin/spa/menu": {
+        "/api/admin/lockouts": {
             "get": {
                 "security": [
                     {
@@ -213,10 +303,13 @@ const docTemplate = `{
                 "tags": [
                     "Admin"
                 ],
-                "summary": "get tailored side menu for different user group",
+                "summary": "list accounts \u0026 client ips which are waiting for backoff or locked out in sign apis, locked ones first.",
                 "responses": {
                     "200": {
-                        "description": "OK - get menu successfully"
+                        "description": "OK - list successfully"
+                    },
+                    "401": {
+                        "description": "Fail - unauthorized error"
                     },
                     "500": {
                         "description": "Fail - internal error"
@@ -224,7 +317,7 @@ const docTemplate = `{
                 }
             }
         },
-        "/api/admin/users": {
+        "/api/admin/mfa/require": {
             "get": {
                 "security": [
                     {
@@ -240,42 +333,110 @@ const docTemplate = `{
                 "tags": [
                     "Admin"
                 ],
-                "summary": "get all users' info",
+                "summary": "get whether two-factor authentication is required for admin (MemLevel 3) users.",
+                "responses": {
+                    "200": {
+                        "description": "OK - get policy successfully"
+                    },
+                    "401": {
+                        "description": "Fail - unauthorized error"
+                    },
+                    "500": {
+                        "description": "Fail - internal error"
+                    }
+                }
+            },
+            "put": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Admin"
+                ],
+                "summary": "require two-factor authentication for admin (MemLevel 3) users or not. when required, admin without 2fa must enroll at next sign-in.",
                 "parameters": [
                     {
-                        "type": "string",
-                        "description": "user filter with uname wildcard(*)",
-                        "name": "uname",
-                        "in": "query"
+                        "type": "boolean",
+                        "description": "true: require; false: not require",
+                        "name": "flag",
+                        "in": "query",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK - set policy successfully"
+                    },
+                    "400": {
+                        "description": "Fail - invalid flag"
+                    },
+                    "401": {
+                        "description": "Fail - unauthorized error"
                     },
+                    "500": {
+                        "description": "Fail - internal error"
+                    }
+                }
+            }
+        },
+        "/api/admin/moderation/deactivate/{id}": {
+            "put": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Admin"
+                ],
+                "summary": "admin deactivates the account of a Post author.",
+                "parameters": [
                     {
                         "type": "string",
-                        "description": "user filter with name wildcard(*)",
-                        "name": "name",
-                        "in": "query"
+                        "description": "Post ID whose author is deactivated",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
                     },
                     {
                         "type": "string",
-                        "description": "user filter with active status",
-                        "name": "active",
+                        "description": "moderation note, recorded in audit trail",
+                        "name": "note",
                         "in": "query"
                     }
                 ],
                 "responses": {
                     "200": {
-                        "description": "OK - list successfully"
+                        "description": "OK - deactivate successfully"
                     },
                     "401": {
                         "description": "Fail - unauthorized error"
                     },
+                    "404": {
+                        "description": "Fail - Post not found"
+                    },
                     "500": {
                         "description": "Fail - internal error"
                     }
                 }
             }
         },
-        "/api/client/get/size": {
-            "get": {
+        "/api/admin/moderation/hide/{id}": {
+            "put": {
                 "security": [
                     {
                         "ApiKeyAuth": []
@@ -288,21 +449,42 @@ const docTemplate = `{
                     "application/json"
                 ],
                 "tags": [
-                    "Client"
+                    "Admin"
+                ],
+                "summary": "admin hides a Post. it disappears from all listings \u0026 search, only its owner \u0026 admin can still get it.",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Post ID for hiding",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "moderation note, recorded in audit trail",
+                        "name": "note",
+                        "in": "query"
+                    }
                 ],
-                "summary": "get client viewport, header, menu, content, \u0026 post-title size",
                 "responses": {
                     "200": {
-                        "description": "OK - get client viewport \u0026 other parts' size ok"
+                        "description": "OK - hide successfully"
                     },
-                    "400": {
-                        "description": "Fail - viewport is not set"
+                    "401": {
+                        "description": "Fail - unauthorized error"
+                    },
+                    "404": {
+                        "description": "Fail - Post not found"
+                    },
+                    "500": {
+                        "description": "Fail - internal error"
                     }
                 }
             }
         },
-        "/api/client/set/view": {
-            "put": {
+        "/api/admin/moderation/queue": {
+            "get": {
                 "security": [
                     {
                         "ApiKeyAuth": []
@@ -315,32 +497,40 @@ const docTemplate = `{
                     "application/json"
                 ],
                 "tags": [
-                    "Client"
+                    "Admin"
                 ],
-                "summary": "set client browser's viewport ( width, height )",
+                "summary": "admin lists reported Posts with report counts, Posts with most pending reports first.",
                 "parameters": [
                     {
-                        "description": "width: window.innerWidth \u0026 height: window.innerHeight",
-                        "name": "innerSize",
-                        "in": "body",
-                        "required": true,
-                        "schema": {
-                            "type": "string"
-                        }
+                        "type": "string",
+                        "description": "filter by status, one of [open, hidden, restored, removed, all], default is open",
+                        "name": "status",
+                        "in": "query"
                     }
                 ],
                 "responses": {
                     "200": {
-                        "description": "OK - set client viewport ok"
+                        "description": "OK - list successfully"
                     },
                     "400": {
-                        "description": "Fail - invalid width or height for setting viewport"
+                        "description": "Fail - incorrect status"
+                    },
+                    "401": {
+                        "description": "Fail - unauthorized error"
+                    },
+                    "500": {
+                        "description": "Fail - internal error"
                     }
                 }
             }
         },
-        "/api/debug/erase/all-post": {
+        "/api/admin/moderation/remove/{id}": {
             "delete": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
                 "consumes": [
                     "application/json"
                 ],
@@ -348,12 +538,2260 @@ const docTemplate = `{
                     "application/json"
                 ],
                 "tags": [
-                    "Debug"
+                    "Admin"
+                ],
+                "summary": "admin removes (deletes) a reported Post.",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Post ID for removing",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "moderation note, recorded in audit trail",
+                        "name": "note",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK - remove successfully"
+                    },
+                    "401": {
+                        "description": "Fail - unauthorized error"
+                    },
+                    "404": {
+                        "description": "Fail - Post not found"
+                    },
+                    "500": {
+                        "description": "Fail - internal error"
+                    }
+                }
+            }
+        },
+        "/api/admin/moderation/restore/{id}": {
+            "put": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Admin"
+                ],
+                "summary": "admin restores a hidden or reported Post, its pending reports are dismissed.",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Post ID for restoring",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "moderation note, recorded in audit trail",
+                        "name": "note",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK - restore successfully"
+                    },
+                    "401": {
+                        "description": "Fail - unauthorized error"
+                    },
+                    "404": {
+                        "description": "Fail - Post not found"
+                    },
+                    "500": {
+                        "description": "Fail - internal error"
+                    }
+                }
+            }
+        },
+        "/api/admin/moderation/warn/{id}": {
+            "put": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Admin"
+                ],
+                "summary": "admin warns the author of a Post. the author gets it in warning list, and by websocket if online.",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Post ID whose author is warned",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "warning message to author",
+                        "name": "note",
+                        "in": "query",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK - warn successfully"
+                    },
+                    "400": {
+                        "description": "Fail - empty warning message"
+                    },
+                    "401": {
+                        "description": "Fail - unauthorized error"
+                    },
+                    "404": {
+                        "description": "Fail - Post not found"
+                    },
+                    "500": {
+                        "description": "Fail - internal error"
+                    }
+                }
+            }
+        },
+        "/api/admin/officialize": {
+            "put": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "consumes": [
+                    "multipart/form-data"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Admin"
+                ],
+                "summary": "officialize or un-officialize a user",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "unique user name",
+                        "name": "uname",
+                        "in": "formData",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "true: officialize, false: un-officialize",
+                        "name": "flag",
+                        "in": "formData",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK - action successfully"
+                    },
+                    "400": {
+                        "description": "Fail - invalid true/false flag"
+                    },
+                    "401": {
+                        "description": "Fail - unauthorized error"
+                    },
+                    "500": {
+                        "description": "Fail - internal error"
+                    }
+                }
+            }
+        },
+        "/api/admin/onlines": {
+            "get": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Admin"
+                ],
+                "summary": "get all online users",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "user filter with uname wildcard(*)",
+                        "name": "uname",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK - list successfully"
+                    },
+                    "401": {
+                        "description": "Fail - unauthorized error"
+                    },
+                    "500": {
+                        "description": "Fail - internal error"
+                    }
+                }
+            }
+        },
+        "/api/admin/sessions": {
+            "get": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Admin"
+                ],
+                "summary": "list alive login sessions of one user, latest first.",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "user name",
+                        "name": "uname",
+                        "in": "query",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK - list successfully"
+                    },
+                    "400": {
+                        "description": "Fail - missing uname"
+                    },
+                    "401": {
+                        "description": "Fail - unauthorized error"
+                    },
+                    "500": {
+                        "description": "Fail - internal error"
+                    }
+                }
+            },
+            "delete": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Admin"
+                ],
+                "summary": "revoke one session of a user, or all of the user's sessions if id is empty.",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "user name",
+                        "name": "uname",
+                        "in": "query",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "session id, empty for all sessions of the user",
+                        "name": "id",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK - revoke successfully, return count of revoked"
+                    },
+                    "400": {
+                        "description": "Fail - missing uname"
+                    },
+                    "401": {
+                        "description": "Fail - unauthorized error"
+                    },
+                    "404": {
+                        "description": "Fail - session not found"
+                    },
+                    "500": {
+                        "description": "Fail - internal error"
+                    }
+                }
+            }
+        },
+        "/api/admin/spa/menu": {
+            "get": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Admin"
+                ],
+                "summary": "get tailored side menu for different user group",
+                "responses": {
+                    "200": {
+                        "description": "OK - get menu successfully"
+                    },
+                    "500": {
+                        "description": "Fail - internal error"
+                    }
+                }
+            }
+        },
+        "/api/admin/users": {
+            "get": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Admin"
+                ],
+                "summary": "get all users' info",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "user filter with uname wildcard(*)",
+                        "name": "uname",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "user filter with name wildcard(*)",
+                        "name": "name",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "user filter with active status",
+                        "name": "active",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK - list successfully"
+                    },
+                    "401": {
+                        "description": "Fail - unauthorized error"
+                    },
+                    "500": {
+                        "description": "Fail - internal error"
+                    }
+                }
+            }
+        },
+        "/api/client/get/size": {
+            "get": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Client"
+                ],
+                "summary": "get client viewport, header, menu, content, \u0026 post-title size",
+                "responses": {
+                    "200": {
+                        "description": "OK - get client viewport \u0026 other parts' size ok"
+                    },
+                    "400": {
+                        "description": "Fail - viewport is not set"
+                    }
+                }
+            }
+        },
+        "/api/client/set/view": {
+            "put": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Client"
+                ],
+                "summary": "set client browser's viewport ( width, height )",
+                "parameters": [
+                    {
+                        "description": "width: window.innerWidth \u0026 height: window.innerHeight",
+                        "name": "innerSize",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "type": "string"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK - set client viewport ok"
+                    },
+                    "400": {
+                        "description": "Fail - invalid width or height for setting viewport"
+                    }
+                }
+            }
+        },
+        "/api/debug/erase/all-post": {
+            "delete": {
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Debug"
+                ],
+                "summary": "erase all Post data collected by wisite service (high risk, only for debugging)",
+                "responses": {
+                    "200": {
+                        "description": "OK - delete successfully"
+                    },
+                    "500": {
+                        "description": "Fail - internal error"
+                    }
+                }
+            }
+        },
+        "/api/file/fileitems": {
+            "get": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "File"
+                ],
+                "summary": "get fileitems by given path or id.",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "file ID (md5)",
+                        "name": "id",
+                        "in": "query",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK - get fileitems successfully"
+                    },
+                    "400": {
+                        "description": "Fail - incorrect query param id"
+                    },
+                    "404": {
+                        "description": "Fail - not found"
+                    },
+                    "500": {
+                        "description": "Fail - internal error"
+                    }
+                }
+            }
+        },
+        "/api/file/pathcontent": {
+            "get": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "File"
+                ],
+                "summary": "get content under specific path.",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "year-month, e.g. 2022-05",
+                        "name": "ym",
+                        "in": "query",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "group path, e.g. group1/group2/group3",
+                        "name": "gpath",
+                        "in": "query",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK - get content successfully"
+                    },
+                    "500": {
+                        "description": "Fail - internal error"
+                    }
+                }
+            }
+        },
+        "/api/file/upload-bodydata": {
+            "post": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "consumes": [
+                    "application/octet-stream"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "File"
+                ],
+                "summary": "upload file action via body content.",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "filename for uploading data from body",
+                        "name": "fname",
+                        "in": "query",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "note for uploading file",
+                        "name": "note",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "1st category for uploading file",
+                        "name": "group0",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "2nd category for uploading file",
+                        "name": "group1",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "3rd category for uploading file",
+                        "name": "group2",
+                        "in": "query"
+                    },
+                    {
+                        "format": "binary",
+                        "description": "file data for uploading",
+                        "name": "data",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "type": "string"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK - return storage path"
+                    },
+                    "400": {
+                        "description": "Fail - file param is incorrect"
+                    },
+                    "500": {
+                        "description": "Fail - internal error"
+                    }
+                }
+            }
+        },
+        "/api/file/upload-formfile": {
+            "post": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "consumes": [
+                    "multipart/form-data"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "File"
+                ],
+                "summary": "upload file action via form file input.",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "note for uploading file",
+                        "name": "note",
+                        "in": "formData"
+                    },
+                    {
+                        "type": "string",
+                        "description": "1st category for uploading file",
+                        "name": "group0",
+                        "in": "formData"
+                    },
+                    {
+                        "type": "string",
+                        "description": "2nd category for uploading file",
+                        "name": "group1",
+                        "in": "formData"
+                    },
+                    {
+                        "type": "string",
+                        "description": "3rd category for uploading file",
+                        "name": "group2",
+                        "in": "formData"
+                    },
+                    {
+                        "type": "file",
+                        "description": "file path for uploading",
+                        "name": "file",
+                        "in": "formData",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK - return storage path"
+                    },
+                    "400": {
+                        "description": "Fail - file param is incorrect"
+                    },
+                    "500": {
+                        "description": "Fail - internal error"
+                    }
+                }
+            }
+        },
+        "/api/mfa/confirm": {
+            "put": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "consumes": [
+                    "multipart/form-data"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "MFA"
+                ],
+                "summary": "confirm two-factor authentication enrollment, step 2. send a code from authenticator app to enable 2fa, return one-time recovery codes, which are shown only this time.",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "6 digits code from authenticator app",
+                        "name": "code",
+                        "in": "formData",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK - 2fa enabled, return recovery codes"
+                    },
+                    "400": {
+                        "description": "Fail - incorrect code"
+                    },
+                    "401": {
+                        "description": "Fail - inactive user"
+                    },
+                    "409": {
+                        "description": "Fail - not enrolled or already enabled"
+                    },
+                    "429": {
+                        "description": "Fail - too many attempts, retry after seconds in header Retry-After"
+                    },
+                    "500": {
+                        "description": "Fail - internal error"
+                    }
+                }
+            }
+        },
+        "/api/mfa/disable": {
+            "post": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "consumes": [
+                    "multipart/form-data"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "MFA"
+                ],
+                "summary": "turn off own two-factor authentication. not allowed if 2fa is required for admin.",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "code from authenticator app, or a recovery code",
+                        "name": "code",
+                        "in": "formData",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK - 2fa disabled"
+                    },
+                    "400": {
+                        "description": "Fail - incorrect code"
+                    },
+                    "401": {
+                        "description": "Fail - inactive user"
+                    },
+                    "409": {
+                        "description": "Fail - 2fa is not enabled or required"
+                    },
+                    "429": {
+                        "description": "Fail - too many attempts, retry after seconds in header Retry-After"
+                    },
+                    "500": {
+                        "description": "Fail - internal error"
+                    }
+                }
+            }
+        },
+        "/api/mfa/enroll": {
+            "post": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "MFA"
+                ],
+                "summary": "start two-factor authentication enrollment, step 1. return secret \u0026 'otpauth' uri (for QR code) to add into authenticator app. re-enrolling replaces pending secret.",
+                "responses": {
+                    "200": {
+                        "description": "OK - then confirm with a code from authenticator app"
+                    },
+                    "401": {
+                        "description": "Fail - inactive user"
+                    },
+                    "409": {
+                        "description": "Fail - 2fa is already enabled"
+                    },
+                    "500": {
+                        "description": "Fail - internal error"
+                    }
+                }
+            }
+        },
+        "/api/mfa/recovery": {
+            "post": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "consumes": [
+                    "multipart/form-data"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "MFA"
+                ],
+                "summary": "replace own recovery codes with new ones, old ones become invalid.",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "6 digits code from authenticator app",
+                        "name": "code",
+                        "in": "formData",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK - return new recovery codes"
+                    },
+                    "400": {
+                        "description": "Fail - incorrect code"
+                    },
+                    "401": {
+                        "description": "Fail - inactive user"
+                    },
+                    "409": {
+                        "description": "Fail - 2fa is not enabled"
+                    },
+                    "429": {
+                        "description": "Fail - too many attempts, retry after seconds in header Retry-After"
+                    },
+                    "500": {
+                        "description": "Fail - internal error"
+                    }
+                }
+            }
+        },
+        "/api/mfa/status": {
+            "get": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "MFA"
+                ],
+                "summary": "get own two-factor authentication status, i.e. enabled, pending enrollment, count of unused recovery codes, and whether it is required.",
+                "responses": {
+                    "200": {
+                        "description": "OK - get status successfully"
+                    },
+                    "401": {
+                        "description": "Fail - inactive user"
+                    },
+                    "500": {
+                        "description": "Fail - internal error"
+                    }
+                }
+            }
+        },
+        "/api/notify/del/{id}": {
+            "delete": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Notify"
+                ],
+                "summary": "delete one own notification.",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "notification id",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK - delete successfully"
+                    },
+                    "404": {
+                        "description": "Fail - notification not found"
+                    },
+                    "500": {
+                        "description": "Fail - internal error"
+                    }
+                }
+            }
+        },
+        "/api/notify/list": {
+            "get": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Notify"
+                ],
+                "summary": "list own notifications, newest first. they are also pushed by websocket '/ws/msg' when connected.",
+                "parameters": [
+                    {
+                        "type": "boolean",
+                        "description": "true: only unread ones",
+                        "name": "unread",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "max count, default is 20, max is 100",
+                        "name": "limit",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "notification id, only older ones than it",
+                        "name": "before",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK - list successfully"
+                    },
+                    "400": {
+                        "description": "Fail - incorrect query param"
+                    },
+                    "500": {
+                        "description": "Fail - internal error"
+                    }
+                }
+            }
+        },
+        "/api/notify/mute": {
+            "get": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Notify"
+                ],
+                "summary": "get own muted notification types.",
+                "responses": {
+                    "200": {
+                        "description": "OK - get successfully"
+                    },
+                    "500": {
+                        "description": "Fail - internal error"
+                    }
+                }
+            },
+            "put": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Notify"
+                ],
+                "summary": "replace own muted notification types. muted types are neither recorded nor pushed.",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "comma separated types from [follow, comment, reaction, mention, share], empty to unmute all",
+                        "name": "types",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK - set successfully"
+                    },
+                    "400": {
+                        "description": "Fail - invalid type"
+                    },
+                    "500": {
+                        "description": "Fail - internal error"
+                    }
+                }
+            }
+        },
+        "/api/notify/read/{id}": {
+            "put": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Notify"
+                ],
+                "summary": "mark one own notification as read, or all of them if id is 'all'.",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "notification id, or 'all'",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK - mark successfully, return count of newly read"
+                    },
+                    "404": {
+                        "description": "Fail - notification not found"
+                    },
+                    "500": {
+                        "description": "Fail - internal error"
+                    }
+                }
+            }
+        },
+        "/api/notify/unread-count": {
+            "get": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Notify"
+                ],
+                "summary": "get count of own unread notifications.",
+                "responses": {
+                    "200": {
+                        "description": "OK - get count successfully"
+                    },
+                    "500": {
+                        "description": "Fail - internal error"
+                    }
+                }
+            }
+        },
+        "/api/post/analytics": {
+            "get": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Post"
+                ],
+                "summary": "author dashboard of views, reactions, bookmarks \u0026 comments on own Posts created in a month range. views are updated in batch, so latest ones may be counted a few seconds later.",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "first month as 'yyyymm', default is current month",
+                        "name": "from",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "last month as 'yyyymm', default is current month. at most 12 months from 'from'",
+                        "name": "to",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK - get analytics successfully"
+                    },
+                    "400": {
+                        "description": "Fail - incorrect query param"
+                    },
+                    "500": {
+                        "description": "Fail - internal error"
+                    }
+                }
+            }
+        },
+        "/api/post/audit": {
+            "get": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Post"
+                ],
+                "summary": "get audit records of performed or refused Post deletion (admin only).",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "filter by who invoked the action",
+                        "name": "actor",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "filter by target Post ID",
+                        "name": "target",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK - get audit trail successfully"
+                    },
+                    "401": {
+                        "description": "Fail - unauthorized error"
+                    },
+                    "500": {
+                        "description": "Fail - internal error"
+                    }
+                }
+            }
+        },
+        "/api/post/bookmark/bookmarked": {
+            "get": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Post"
+                ],
+                "summary": "get all bookmarked Post ids.",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "order[desc asc] to get Post ids ordered by bookmark time. only for 'compat'",
+                        "name": "order",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "page size, default is 20, max is 100",
+                        "name": "limit",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "cursor ('next_cursor' of last page) for older Post ids",
+                        "name": "before",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "cursor ('prev_cursor' of last page) for newer Post ids",
+                        "name": "after",
+                        "in": "query"
+                    },
+                    {
+                        "type": "boolean",
+                        "description": "true: return plain id array as before, no paging",
+                        "name": "compat",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK - get successfully"
+                    },
+                    "500": {
+                        "description": "Fail - internal error"
+                    }
+                }
+            }
+        },
+        "/api/post/bookmark/status/{id}": {
+            "get": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Post"
+                ],
+                "summary": "get current login user's bookmark status for a post.",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Post ID (event id) for checking bookmark status",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK - get bookmark status successfully"
+                    },
+                    "500": {
+                        "description": "Fail - internal error"
+                    }
+                }
+            }
+        },
+        "/api/post/bookmark/{id}": {
+            "patch": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Post"
+                ],
+                "summary": "add or remove a personal bookmark for a post.",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Post ID (event id) for toggling a bookmark",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK - toggled bookmark successfully"
+                    },
+                    "500": {
+                        "description": "Fail - internal error"
+                    }
+                }
+            }
+        },
+        "/api/post/category/ids/{id}": {
+            "get": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Post"
+                ],
+                "summary": "get Post ids in one category, newest first, cursor paged.",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "category id",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "integer",
+                        "description": "page size, default is 20, max is 100",
+                        "name": "limit",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "cursor ('next_cursor' of last page) for older Post ids",
+                        "name": "before",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "cursor ('prev_cursor' of last page) for newer Post ids",
+                        "name": "after",
+                        "in": "query"
+                    },
+                    {
+                        "type": "boolean",
+                        "description": "true: return plain id array, no paging",
+                        "name": "compat",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK - get successfully"
+                    },
+                    "400": {
+                        "description": "Fail - incorrect query param"
+                    },
+                    "404": {
+                        "description": "Fail - category not found"
+                    },
+                    "500": {
+                        "description": "Fail - internal error"
+                    }
+                }
+            }
+        },
+        "/api/post/category/list": {
+            "get": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Post"
+                ],
+                "summary": "list all admin-defined Post categories with their Post counts.",
+                "responses": {
+                    "200": {
+                        "description": "OK - list successfully"
+                    },
+                    "500": {
+                        "description": "Fail - internal error"
+                    }
+                }
+            }
+        },
+        "/api/post/del/one": {
+            "delete": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Post"
+                ],
+                "summary": "delete one Post content.",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Post ID for deleting",
+                        "name": "id",
+                        "in": "query",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "reason for deleting, recorded in audit trail",
+                        "name": "reason",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK - delete successfully"
+                    },
+                    "400": {
+                        "description": "Fail - incorrect query param id"
+                    },
+                    "403": {
+                        "description": "Fail - neither Post owner nor admin"
+                    },
+                    "404": {
+                        "description": "Fail - not found"
+                    },
+                    "500": {
+                        "description": "Fail - internal error"
+                    }
+                }
+            }
+        },
+        "/api/post/draft/del/{id}": {
+            "delete": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Post"
+                ],
+                "summary": "delete one own draft, scheduled one is cancelled.",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "draft id",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK - delete successfully"
+                    },
+                    "404": {
+                        "description": "Fail - draft not found"
+                    },
+                    "500": {
+                        "description": "Fail - internal error"
+                    }
+                }
+            }
+        },
+        "/api/post/draft/list": {
+            "get": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Post"
+                ],
+                "summary": "list own drafts (without content), most recently updated first. scheduled one has 'publish_at'.",
+                "responses": {
+                    "200": {
+                        "description": "OK - list successfully"
+                    },
+                    "500": {
+                        "description": "Fail - internal error"
+                    }
+                }
+            }
+        },
+        "/api/post/draft/new": {
+            "post": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Post"
+                ],
+                "summary": "save a Post as own draft. if 'publish_at' is given, it is published as a normal Post at that time.",
+                "parameters": [
+                    {
+                        "description": "draft json, e.g. {'post': {filled Post template}, 'followee': 'optional Post ID', 'publish_at': '2023-06-01T09:00:00+10:00'}",
+                        "name": "data",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "type": "string"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK - create successfully, return draft id",
+                        "headers": {
+                            "X-Sanitize-Report": {
+                                "type": "string",
+                                "description": "json {count, removed} of removed unsafe html elements \u0026 attributes, only first few listed, only if any"
+                            }
+                        }
+                    },
+                    "400": {
+                        "description": "Fail - incorrect draft format, or 'publish_at' is not future time"
+                    },
+                    "500": {
+                        "description": "Fail - internal error"
+                    }
+                }
+            }
+        },
+        "/api/post/draft/one/{id}": {
+            "get": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Post"
+                ],
+                "summary": "get one own draft with its content.",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "draft id",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK - get draft successfully"
+                    },
+                    "404": {
+                        "description": "Fail - draft not found"
+                    },
+                    "500": {
+                        "description": "Fail - internal error"
+                    }
+                }
+            }
+        },
+        "/api/post/draft/update/{id}": {
+            "put": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Post"
+                ],
+                "summary": "replace own draft content \u0026 schedule. without 'publish_at', it becomes unscheduled.",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "draft id",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "draft json, e.g. {'post': {filled Post template}, 'followee': 'optional Post ID', 'publish_at': '2023-06-01T09:00:00+10:00'}",
+                        "name": "data",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "type": "string"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK - update successfully, return updated draft",
+                        "headers": {
+                            "X-Sanitize-Report": {
+                                "type": "string",
+                                "description": "json {count, removed} of removed unsafe html elements \u0026 attributes, only first few listed, only if any"
+                            }
+                        }
+                    },
+                    "400": {
+                        "description": "Fail - incorrect draft format, or 'publish_at' is not future time"
+                    },
+                    "404": {
+                        "description": "Fail - draft not found"
+                    },
+                    "500": {
+                        "description": "Fail - internal error"
+                    }
+                }
+            }
+        },
+        "/api/post/edit/{id}": {
+            "put": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Post"
+                ],
+                "summary": "edit own Post by a filled Post template. earlier Post body is kept as a revision.",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Post ID for editing",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "filled Post template json file",
+                        "name": "data",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "type": "string"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK - edit successfully, return archived revision meta",
+                        "headers": {
+                            "X-Sanitize-Report": {
+                                "type": "string",
+                                "description": "json {count, removed} of removed unsafe html elements \u0026 attributes, only first few listed, only if any"
+                            }
+                        }
+                    },
+                    "400": {
+                        "description": "Fail - incorrect Post format"
+                    },
+                    "403": {
+                        "description": "Fail - not Post owner"
+                    },
+                    "404": {
+                        "description": "Fail - Post not found"
+                    },
+                    "500": {
+                        "description": "Fail - internal error"
+                    }
+                }
+            }
+        },
+        "/api/post/erase/one": {
+            "delete": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Post"
+                ],
+                "summary": "erase one Post content permanently.",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Post ID for erasing",
+                        "name": "id",
+                        "in": "query",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "reason for erasing, recorded in audit trail",
+                        "name": "reason",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK - erase successfully"
+                    },
+                    "400": {
+                        "description": "Fail - incorrect query param id"
+                    },
+                    "403": {
+                        "description": "Fail - not admin"
+                    },
+                    "404": {
+                        "description": "Fail - not found"
+                    },
+                    "500": {
+                        "description": "Fail - internal error"
+                    }
+                }
+            }
+        },
+        "/api/post/feed": {
+            "get": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Post"
+                ],
+                "summary": "get a page of Post ids from followed users and self, without blocked or muted users.",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "'latest' (default) or 'popular' (ranked by reaction and comment counts)",
+                        "name": "mode",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "only for 'popular', rank Posts in recent [days], default is 7",
+                        "name": "days",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "page size, default is 20, max is 100",
+                        "name": "limit",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "cursor ('next_cursor' of last page) for next page",
+                        "name": "before",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "cursor ('prev_cursor' of last page) for previous page",
+                        "name": "after",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK - get feed page successfully"
+                    },
+                    "400": {
+                        "description": "Fail - incorrect query param"
+                    },
+                    "500": {
+                        "description": "Fail - internal error"
+                    }
+                }
+            }
+        },
+        "/api/post/follower/ids": {
+            "get": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Post"
+                ],
+                "summary": "get a specified Post follower-Post id group.",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "followee Post ID",
+                        "name": "followee",
+                        "in": "query",
+                        "required": true
+                    },
+                    {
+                        "type": "integer",
+                        "description": "page size, default is 20, max is 100",
+                        "name": "limit",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "cursor ('next_cursor' of last page) for older Post ids",
+                        "name": "before",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "cursor ('prev_cursor' of last page) for newer Post ids",
+                        "name": "after",
+                        "in": "query"
+                    },
+                    {
+                        "type": "boolean",
+                        "description": "true: return plain id array as before, no paging",
+                        "name": "compat",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK - get successfully"
+                    },
+                    "404": {
+                        "description": "Fail - empty follower ids"
+                    },
+                    "500": {
+                        "description": "Fail - internal error"
+                    }
+                }
+            }
+        },
+        "/api/post/ids": {
+            "get": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Post"
+                ],
+                "summary": "get a batch of Post id group.",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "time or count. if missing, all Post ids are paged (unavailable when 'compat')",
+                        "name": "fetchby",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "recent [value] minutes for time OR most recent [value] count",
+                        "name": "value",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "page size, default is 20, max is 100",
+                        "name": "limit",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "cursor ('next_cursor' of last page) for older Post ids",
+                        "name": "before",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "cursor ('prev_cursor' of last page) for newer Post ids",
+                        "name": "after",
+                        "in": "query"
+                    },
+                    {
+                        "type": "boolean",
+                        "description": "true: return plain id array as before, no paging",
+                        "name": "compat",
+                        "in": "query"
+                    }
                 ],
-                "summary": "erase all Post data collected by wisite service (high risk, only for debugging)",
                 "responses": {
                     "200": {
-                        "description": "OK - delete successfully"
+                        "description": "OK - get successfully"
+                    },
+                    "400": {
+                        "description": "Fail - incorrect query param type"
+                    },
+                    "404": {
+                        "description": "Fail - not found"
+                    },
+                    "500": {
+                        "description": "Fail - internal error"
+                    }
+                }
+            }
+        },
+        "/api/post/ids-all": {
+            "get": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Post"
+                ],
+                "summary": "get all Post id group.",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "page size, default is 20, max is 100",
+                        "name": "limit",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "cursor ('next_cursor' of last page) for older Post ids",
+                        "name": "before",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "cursor ('prev_cursor' of last page) for newer Post ids",
+                        "name": "after",
+                        "in": "query"
+                    },
+                    {
+                        "type": "boolean",
+                        "description": "true: return plain id array as before, no paging",
+                        "name": "compat",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK - get successfully"
+                    },
+                    "400": {
+                        "description": "Fail - incorrect query param"
+                    },
+                    "404": {
+                        "description": "Fail - empty event ids"
+                    },
+                    "500": {
+                        "description": "Fail - internal error"
+                    }
+                }
+            }
+        },
+        "/api/post/many": {
+            "post": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Post"
+                ],
+                "summary": "get a batch of Post contents in one response. missing or deleted Post is reported per item.",
+                "parameters": [
+                    {
+                        "description": "Post ids json, e.g. {'ids': ['id1', 'id2']}, at most 100 ids",
+                        "name": "data",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "type": "string"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK - get Post items in request order"
+                    },
+                    "400": {
+                        "description": "Fail - incorrect ids format or too many ids"
+                    }
+                }
+            }
+        },
+        "/api/post/mentions": {
+            "get": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Post"
+                ],
+                "summary": "get ids of Posts mentioning the caller by '@uname', newest first, cursor paged. Posts from blocked users are excluded.",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "page size, default is 20, max is 100",
+                        "name": "limit",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "cursor ('next_cursor' of last page) for older Post ids",
+                        "name": "before",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "cursor ('prev_cursor' of last page) for newer Post ids",
+                        "name": "after",
+                        "in": "query"
+                    },
+                    {
+                        "type": "boolean",
+                        "description": "true: return plain id array, no paging",
+                        "name": "compat",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK - get successfully"
+                    },
+                    "400": {
+                        "description": "Fail - incorrect query param"
+                    },
+                    "500": {
+                        "description": "Fail - internal error"
+                    }
+                }
+            }
+        },
+        "/api/post/one": {
+            "get": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Post"
+                ],
+                "summary": "get one Post content.",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Post ID for its content",
+                        "name": "id",
+                        "in": "query",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK - get Post event successfully"
+                    },
+                    "400": {
+                        "description": "Fail - incorrect query param id"
+                    },
+                    "404": {
+                        "description": "Fail - not found"
+                    },
+                    "500": {
+                        "description": "Fail - internal error"
+                    }
+                }
+            }
+        },
+        "/api/post/own/ids": {
+            "get": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Post"
+                ],
+                "summary": "get own Post id group in one specific time period.",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "time period for query, format is 'yyyymm', e.g. '202206'. if missing, current yyyymm applies",
+                        "name": "period",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "page size, default is 20, max is 100",
+                        "name": "limit",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "cursor ('next_cursor' of last page) for older Post ids",
+                        "name": "before",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "cursor ('prev_cursor' of last page) for newer Post ids",
+                        "name": "after",
+                        "in": "query"
+                    },
+                    {
+                        "type": "boolean",
+                        "description": "true: return plain id array as before, no paging",
+                        "name": "compat",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK - get successfully"
+                    },
+                    "400": {
+                        "description": "Fail - incorrect query param type"
+                    },
+                    "404": {
+                        "description": "Fail - empty event ids"
                     },
                     "500": {
                         "description": "Fail - internal error"
@@ -361,7 +2799,7 @@ const docTemplate = `{
                 }
             }
         },
-        "/api/file/fileitems": {
+        "/api/post/react/list/{id}": {
             "get": {
                 "security": [
                     {
@@ -375,27 +2813,33 @@ const docTemplate = `{
                     "application/json"
                 ],
                 "tags": [
-                    "File"
+                    "Post"
                 ],
-                "summary": "get fileitems by given path or id.",
+                "summary": "get user names for each reaction on a post, users blocked by current login user are excluded.",
                 "parameters": [
                     {
                         "type": "string",
-                        "description": "file ID (md5)",
+                        "description": "Post ID (event id) for listing reactors",
                         "name": "id",
-                        "in": "query",
+                        "in": "path",
                         "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "only this reaction type. if missing, all types are listed",
+                        "name": "type",
+                        "in": "query"
                     }
                 ],
                 "responses": {
                     "200": {
-                        "description": "OK - get fileitems successfully"
+                        "description": "OK - get reactors successfully"
                     },
                     "400": {
-                        "description": "Fail - incorrect query param id"
+                        "description": "Fail - unsupported reaction type"
                     },
                     "404": {
-                        "description": "Fail - not found"
+                        "description": "Fail - Post not found"
                     },
                     "500": {
                         "description": "Fail - internal error"
@@ -403,7 +2847,7 @@ const docTemplate = `{
                 }
             }
         },
-        "/api/file/pathcontent": {
+        "/api/post/react/status/{id}": {
             "get": {
                 "security": [
                     {
@@ -417,28 +2861,24 @@ const docTemplate = `{
                     "application/json"
                 ],
                 "tags": [
-                    "File"
+                    "Post"
                 ],
-                "summary": "get content under specific path.",
+                "summary": "get each reaction count and current login user's reactions for a post.",
                 "parameters": [
                     {
                         "type": "string",
-                        "description": "year-month, e.g. 2022-05",
-                        "name": "ym",
-                        "in": "query",
-                        "required": true
-                    },
-                    {
-                        "type": "string",
-                        "description": "group path, e.g. group1/group2/group3",
-                        "name": "gpath",
-                        "in": "query",
+                        "description": "Post ID (event id) for checking reaction status",
+                        "name": "id",
+                        "in": "path",
                         "required": true
                     }
                 ],
                 "responses": {
                     "200": {
-                        "description": "OK - get content successfully"
+                        "description": "OK - get reaction status successfully"
+                    },
+                    "404": {
+                        "description": "Fail - Post not found"
                     },
                     "500": {
                         "description": "Fail - internal error"
@@ -446,135 +2886,72 @@ const docTemplate = `{
                 }
             }
         },
-        "/api/file/upload-bodydata": {
-            "post": {
+        "/api/post/react/types": {
+            "get": {
                 "security": [
                     {
                         "ApiKeyAuth": []
                     }
                 ],
                 "consumes": [
-                    "application/octet-stream"
+                    "application/json"
                 ],
                 "produces": [
                     "application/json"
                 ],
                 "tags": [
-                    "File"
-                ],
-                "summary": "upload file action via body content.",
-                "parameters": [
-                    {
-                        "type": "string",
-                        "description": "filename for uploading data from body",
-                        "name": "fname",
-                        "in": "query",
-                        "required": true
-                    },
-                    {
-                        "type": "string",
-                        "description": "note for uploading file",
-                        "name": "note",
-                        "in": "query"
-                    },
-                    {
-                        "type": "string",
-                        "description": "1st category for uploading file",
-                        "name": "group0",
-                        "in": "query"
-                    },
-                    {
-                        "type": "string",
-                        "description": "2nd category for uploading file",
-                        "name": "group1",
-                        "in": "query"
-                    },
-                    {
-                        "type": "string",
-                        "description": "3rd category for uploading file",
-                        "name": "group2",
-                        "in": "query"
-                    },
-                    {
-                        "format": "binary",
-                        "description": "file data for uploading",
-                        "name": "data",
-                        "in": "body",
-                        "required": true,
-                        "schema": {
-                            "type": "string"
-                        }
-                    }
+                    "Post"
                 ],
+                "summary": "get all available reaction types.",
                 "responses": {
                     "200": {
-                        "description": "OK - return storage path"
-                    },
-                    "400": {
-                        "description": "Fail - file param is incorrect"
-                    },
-                    "500": {
-                        "description": "Fail - internal error"
+                        "description": "OK - get reaction types successfully"
                     }
                 }
             }
         },
-        "/api/file/upload-formfile": {
-            "post": {
+        "/api/post/react/{id}": {
+            "patch": {
                 "security": [
                     {
                         "ApiKeyAuth": []
                     }
                 ],
                 "consumes": [
-                    "multipart/form-data"
+                    "application/json"
                 ],
                 "produces": [
                     "application/json"
                 ],
                 "tags": [
-                    "File"
+                    "Post"
                 ],
-                "summary": "upload file action via form file input.",
+                "summary": "add or remove a personal reaction of one type for a post.",
                 "parameters": [
                     {
                         "type": "string",
-                        "description": "note for uploading file",
-                        "name": "note",
-                        "in": "formData"
-                    },
-                    {
-                        "type": "string",
-                        "description": "1st category for uploading file",
-                        "name": "group0",
-                        "in": "formData"
-                    },
-                    {
-                        "type": "string",
-                        "description": "2nd category for uploading file",
-                        "name": "group1",
-                        "in": "formData"
+                        "description": "Post ID (event id) for adding or removing reaction",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
                     },
                     {
                         "type": "string",
-                        "description": "3rd category for uploading file",
-                        "name": "group2",
-                        "in": "formData"
-                    },
-                    {
-                        "type": "file",
-                        "description": "file path for uploading",
-                        "name": "file",
-                        "in": "formData",
+                        "description": "reaction type, one of '/api/post/react/types'",
+                        "name": "type",
+                        "in": "query",
                         "required": true
                     }
                 ],
                 "responses": {
                     "200": {
-                        "description": "OK - return storage path"
+                        "description": "OK - added or removed reaction successfully"
                     },
                     "400": {
-                        "description": "Fail - file param is incorrect"
+                        "description": "Fail - unsupported reaction type"
+                    },
+                    "404": {
+                        "description": "Fail - Post not found"
                     },
                     "500": {
                         "description": "Fail - internal error"
@@ -582,8 +2959,8 @@ const docTemplate = `{
                 }
             }
         },
-        "/api/post/bookmark/bookmarked": {
-            "get": {
+        "/api/post/report/{id}": {
+            "post": {
                 "security": [
                     {
                         "ApiKeyAuth": []
@@ -598,18 +2975,38 @@ const docTemplate = `{
                 "tags": [
                     "Post"
                 ],
-                "summary": "get all bookmarked Post ids.",
+                "summary": "report a Post to admin for moderation. reporting the same Post again replaces own earlier report.",
                 "parameters": [
                     {
                         "type": "string",
-                        "description": "order[desc asc] to get Post ids ordered by event time",
-                        "name": "order",
+                        "description": "Post ID for reporting",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "reason code, one of [spam, abuse, misinformation, illegal, other]",
+                        "name": "reason",
+                        "in": "query",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "more details for admin",
+                        "name": "note",
                         "in": "query"
                     }
                 ],
                 "responses": {
                     "200": {
-                        "description": "OK - get successfully"
+                        "description": "OK - report successfully"
+                    },
+                    "400": {
+                        "description": "Fail - incorrect reason code, or reporting own Post"
+                    },
+                    "404": {
+                        "description": "Fail - Post not found"
                     },
                     "500": {
                         "description": "Fail - internal error"
@@ -617,7 +3014,7 @@ const docTemplate = `{
                 }
             }
         },
-        "/api/post/bookmark/status/{id}": {
+        "/api/post/revision/list/{id}": {
             "get": {
                 "security": [
                     {
@@ -633,11 +3030,11 @@ const docTemplate = `{
                 "tags": [
                     "Post"
                 ],
-                "summary": "get current login user's bookmark status for a post.",
+                "summary": "list all earlier revision meta (seq, author, time) of a Post.",
                 "parameters": [
                     {
                         "type": "string",
-                        "description": "Post ID (event id) for checking bookmark status",
+                        "description": "Post ID for its revisions",
                         "name": "id",
                         "in": "path",
                         "required": true
@@ -645,7 +3042,10 @@ const docTemplate = `{
                 ],
                 "responses": {
                     "200": {
-                        "description": "OK - get bookmark status successfully"
+                        "description": "OK - list successfully"
+                    },
+                    "404": {
+                        "description": "Fail - Post not found"
                     },
                     "500": {
                         "description": "Fail - internal error"
@@ -653,8 +3053,8 @@ const docTemplate = `{
                 }
             }
         },
-        "/api/post/bookmark/{id}": {
-            "patch": {
+        "/api/post/revision/one/{id}": {
+            "get": {
                 "security": [
                     {
                         "ApiKeyAuth": []
@@ -669,19 +3069,35 @@ const docTemplate = `{
                 "tags": [
                     "Post"
                 ],
-                "summary": "add or remove a personal bookmark for a post.",
+                "summary": "get one earlier Post body by its revision seq. only Post owner or admin can get it.",
                 "parameters": [
                     {
                         "type": "string",
-                        "description": "Post ID (event id) for toggling a bookmark",
+                        "description": "Post ID",
                         "name": "id",
                         "in": "path",
                         "required": true
+                    },
+                    {
+                        "type": "integer",
+                        "description": "revision seq, 0 is the original body",
+                        "name": "seq",
+                        "in": "query",
+                        "required": true
                     }
                 ],
                 "responses": {
                     "200": {
-                        "description": "OK - toggled bookmark successfully"
+                        "description": "OK - get revision successfully"
+                    },
+                    "400": {
+                        "description": "Fail - incorrect query param seq"
+                    },
+                    "403": {
+                        "description": "Fail - neither Post owner nor admin"
+                    },
+                    "404": {
+                        "description": "Fail - Post or revision not found"
                     },
                     "500": {
                         "description": "Fail - internal error"
@@ -689,13 +3105,14 @@ const docTemplate = `{
                 }
             }
         },
-        "/api/post/del/one": {
-            "delete": {
+        "/api/post/search": {
+            "get": {
                 "security": [
                     {
                         "ApiKeyAuth": []
                     }
                 ],
+                "description": "words in double quotes are a phrase which must be matched. hits in keywords \u0026 topic rank higher.",
                 "consumes": [
                     "application/json"
                 ],
@@ -705,34 +3122,58 @@ const docTemplate = `{
                 "tags": [
                     "Post"
                 ],
-                "summary": "delete one Post content.",
+                "summary": "full-text search Post (including comment) by topic, keywords and paragraph text.",
                 "parameters": [
                     {
                         "type": "string",
-                        "description": "Post ID for deleting",
-                        "name": "id",
+                        "description": "search text, e.g. 'covid \\",
+                        "name": "q",
                         "in": "query",
                         "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "only Post from this owner",
+                        "name": "owner",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "only Post created from this date, 'yyyy-mm-dd' or RFC3339",
+                        "name": "from",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "only Post created until this date, 'yyyy-mm-dd' or RFC3339",
+                        "name": "to",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "page number, start from 1. default is 1",
+                        "name": "page",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "page size, default is 20, max is 100",
+                        "name": "size",
+                        "in": "query"
                     }
                 ],
                 "responses": {
                     "200": {
-                        "description": "OK - delete successfully"
+                        "description": "OK - search successfully, return ranked hits of requested page \u0026 total count"
                     },
                     "400": {
-                        "description": "Fail - incorrect query param id"
-                    },
-                    "404": {
-                        "description": "Fail - not found"
-                    },
-                    "500": {
-                        "description": "Fail - internal error"
+                        "description": "Fail - incorrect query param"
                     }
                 }
             }
         },
-        "/api/post/erase/one": {
-            "delete": {
+        "/api/post/share/ids/{id}": {
+            "get": {
                 "security": [
                     {
                         "ApiKeyAuth": []
@@ -747,25 +3188,46 @@ const docTemplate = `{
                 "tags": [
                     "Post"
                 ],
-                "summary": "erase one Post content permanently.",
+                "summary": "get ids of alive Shares of one Post, newest first, cursor paged. unshare by deleting the Share as one Post.",
                 "parameters": [
                     {
                         "type": "string",
-                        "description": "Post ID for erasing",
+                        "description": "original Post id",
                         "name": "id",
-                        "in": "query",
+                        "in": "path",
                         "required": true
+                    },
+                    {
+                        "type": "integer",
+                        "description": "page size, default is 20, max is 100",
+                        "name": "limit",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "cursor ('next_cursor' of last page) for older ids",
+                        "name": "before",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "cursor ('prev_cursor' of last page) for newer ids",
+                        "name": "after",
+                        "in": "query"
+                    },
+                    {
+                        "type": "boolean",
+                        "description": "true: return plain id array, no paging",
+                        "name": "compat",
+                        "in": "query"
                     }
                 ],
                 "responses": {
                     "200": {
-                        "description": "OK - erase successfully"
+                        "description": "OK - get successfully"
                     },
                     "400": {
-                        "description": "Fail - incorrect query param id"
-                    },
-                    "404": {
-                        "description": "Fail - not found"
+                        "description": "Fail - incorrect query param"
                     },
                     "500": {
                         "description": "Fail - internal error"
@@ -773,8 +3235,8 @@ const docTemplate = `{
                 }
             }
         },
-        "/api/post/follower/ids": {
-            "get": {
+        "/api/post/share/{id}": {
+            "post": {
                 "security": [
                     {
                         "ApiKeyAuth": []
@@ -789,22 +3251,31 @@ const docTemplate = `{
                 "tags": [
                     "Post"
                 ],
-                "summary": "get a specified Post follower-Post id group.",
+                "summary": "share one Post to followers with optional comment. sharing a Share shares its original Post.",
                 "parameters": [
                     {
                         "type": "string",
-                        "description": "followee Post ID",
-                        "name": "followee",
-                        "in": "query",
+                        "description": "original Post id",
+                        "name": "id",
+                        "in": "path",
                         "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "optional comment, at most 500 characters",
+                        "name": "comment",
+                        "in": "query"
                     }
                 ],
                 "responses": {
                     "200": {
-                        "description": "OK - get successfully"
+                        "description": "OK - share successfully, return share id"
+                    },
+                    "400": {
+                        "description": "Fail - invalid comment"
                     },
                     "404": {
-                        "description": "Fail - empty follower ids"
+                        "description": "Fail - Post not found"
                     },
                     "500": {
                         "description": "Fail - internal error"
@@ -812,7 +3283,7 @@ const docTemplate = `{
                 }
             }
         },
-        "/api/post/ids": {
+        "/api/post/tag/ids/{tag}": {
             "get": {
                 "security": [
                     {
@@ -828,21 +3299,38 @@ const docTemplate = `{
                 "tags": [
                     "Post"
                 ],
-                "summary": "get a batch of Post id group.",
+                "summary": "get Post ids with one tag, newest first, cursor paged.",
                 "parameters": [
                     {
                         "type": "string",
-                        "description": "time or count",
-                        "name": "fetchby",
-                        "in": "query",
+                        "description": "tag, normalized as lower case with '-' for space",
+                        "name": "tag",
+                        "in": "path",
                         "required": true
                     },
+                    {
+                        "type": "integer",
+                        "description": "page size, default is 20, max is 100",
+                        "name": "limit",
+                        "in": "query"
+                    },
                     {
                         "type": "string",
-                        "description": "recent [value] minutes for time OR most recent [value] count",
-                        "name": "value",
-                        "in": "query",
-                        "required": true
+                        "description": "cursor ('next_cursor' of last page) for older Post ids",
+                        "name": "before",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "cursor ('prev_cursor' of last page) for newer Post ids",
+                        "name": "after",
+                        "in": "query"
+                    },
+                    {
+                        "type": "boolean",
+                        "description": "true: return plain id array, no paging",
+                        "name": "compat",
+                        "in": "query"
                     }
                 ],
                 "responses": {
@@ -850,10 +3338,7 @@ const docTemplate = `{
                         "description": "OK - get successfully"
                     },
                     "400": {
-                        "description": "Fail - incorrect query param type"
-                    },
-                    "404": {
-                        "description": "Fail - not found"
+                        "description": "Fail - incorrect query param"
                     },
                     "500": {
                         "description": "Fail - internal error"
@@ -861,7 +3346,7 @@ const docTemplate = `{
                 }
             }
         },
-        "/api/post/ids-all": {
+        "/api/post/tag/list": {
             "get": {
                 "security": [
                     {
@@ -877,21 +3362,32 @@ const docTemplate = `{
                 "tags": [
                     "Post"
                 ],
-                "summary": "get all Post id group.",
+                "summary": "list tags with their Post counts, most used first.",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "only tags starting with this, e.g. for auto-completion",
+                        "name": "prefix",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "max count of tags, default is 20, max is 100",
+                        "name": "limit",
+                        "in": "query"
+                    }
+                ],
                 "responses": {
                     "200": {
-                        "description": "OK - get successfully"
-                    },
-                    "404": {
-                        "description": "Fail - empty event ids"
+                        "description": "OK - list successfully"
                     },
-                    "500": {
-                        "description": "Fail - internal error"
+                    "400": {
+                        "description": "Fail - incorrect query param"
                     }
                 }
             }
         },
-        "/api/post/one": {
+        "/api/post/tag/trending": {
             "get": {
                 "security": [
                     {
@@ -907,40 +3403,32 @@ const docTemplate = `{
                 "tags": [
                     "Post"
                 ],
-                "summary": "get one Post content.",
+                "summary": "most used tags of Posts created within a recent time window.",
                 "parameters": [
                     {
-                        "type": "string",
-                        "description": "Post ID for its content",
-                        "name": "id",
-                        "in": "query",
-                        "required": true
+                        "type": "integer",
+                        "description": "time window in hours, default is 24, max is 720",
+                        "name": "hours",
+                        "in": "query"
                     },
                     {
-                        "type": "boolean",
-                        "description": "remote ip for media src?",
-                        "name": "remote",
-                        "in": "query",
-                        "required": true
+                        "type": "integer",
+                        "description": "max count of tags, default is 10, max is 100",
+                        "name": "top",
+                        "in": "query"
                     }
                 ],
                 "responses": {
                     "200": {
-                        "description": "OK - get Post event successfully"
+                        "description": "OK - get trending tags successfully"
                     },
                     "400": {
-                        "description": "Fail - incorrect query param id"
-                    },
-                    "404": {
-                        "description": "Fail - not found"
-                    },
-                    "500": {
-                        "description": "Fail - internal error"
+                        "description": "Fail - incorrect query param"
                     }
                 }
             }
         },
-        "/api/post/own/ids": {
+        "/api/post/template": {
             "get": {
                 "security": [
                     {
@@ -956,32 +3444,15 @@ const docTemplate = `{
                 "tags": [
                     "Post"
                 ],
-                "summary": "get own Post id group in one specific time period.",
-                "parameters": [
-                    {
-                        "type": "string",
-                        "description": "time period for query, format is 'yyyymm', e.g. '202206'. if missing, current yyyymm applies",
-                        "name": "period",
-                        "in": "query"
-                    }
-                ],
+                "summary": "get Post template for dev reference.",
                 "responses": {
                     "200": {
-                        "description": "OK - get successfully"
-                    },
-                    "400": {
-                        "description": "Fail - incorrect query param type"
-                    },
-                    "404": {
-                        "description": "Fail - empty event ids"
-                    },
-                    "500": {
-                        "description": "Fail - internal error"
+                        "description": "OK - upload successfully"
                     }
                 }
             }
         },
-        "/api/post/template": {
+        "/api/post/thread/{id}": {
             "get": {
                 "security": [
                     {
@@ -997,10 +3468,40 @@ const docTemplate = `{
                 "tags": [
                     "Post"
                 ],
-                "summary": "get Post template for dev reference.",
+                "summary": "get the whole nested reply tree of a Post, with Post content inline.",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "root Post ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "integer",
+                        "description": "reply levels under root, default is 5, max is 20",
+                        "name": "depth",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "sort replies on each level by 'oldest' (default), 'newest' or 'liked' (most reactions of all types)",
+                        "name": "sort",
+                        "in": "query"
+                    }
+                ],
                 "responses": {
                     "200": {
-                        "description": "OK - upload successfully"
+                        "description": "OK - get reply tree successfully"
+                    },
+                    "400": {
+                        "description": "Fail - incorrect query param"
+                    },
+                    "404": {
+                        "description": "Fail - not found"
+                    },
+                    "500": {
+                        "description": "Fail - internal error"
                     }
                 }
             }
@@ -1113,7 +3614,13 @@ const docTemplate = `{
                 ],
                 "responses": {
                     "200": {
-                        "description": "OK - upload successfully"
+                        "description": "OK - upload successfully",
+                        "headers": {
+                            "X-Sanitize-Report": {
+                                "type": "string",
+                                "description": "json {count, removed} of removed unsafe html elements \u0026 attributes, only first few listed, only if any"
+                            }
+                        }
                     },
                     "400": {
                         "description": "Fail - incorrect Post format"
@@ -1124,6 +3631,33 @@ const docTemplate = `{
                 }
             }
         },
+        "/api/post/warnings": {
+            "get": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Post"
+                ],
+                "summary": "list warnings from admin on own Posts, oldest first.",
+                "responses": {
+                    "200": {
+                        "description": "OK - list successfully"
+                    },
+                    "500": {
+                        "description": "Fail - internal error"
+                    }
+                }
+            }
+        },
         "/api/rel/action/{whom}": {
             "put": {
                 "security": [
@@ -1209,6 +3743,99 @@ const docTemplate = `{
                 }
             }
         },
+        "/api/session/list": {
+            "get": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Session"
+                ],
+                "summary": "list own alive login sessions with device, ip and last seen, latest first. the caller's one is marked 'current'.",
+                "responses": {
+                    "200": {
+                        "description": "OK - list successfully"
+                    },
+                    "500": {
+                        "description": "Fail - internal error"
+                    }
+                }
+            }
+        },
+        "/api/session/others": {
+            "delete": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Session"
+                ],
+                "summary": "revoke all own sessions except the caller's one.",
+                "responses": {
+                    "200": {
+                        "description": "OK - revoke successfully, return count of revoked"
+                    },
+                    "500": {
+                        "description": "Fail - internal error"
+                    }
+                }
+            }
+        },
+        "/api/session/revoke/{id}": {
+            "delete": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Session"
+                ],
+                "summary": "revoke one own session, its access \u0026 refresh token become invalid at once. revoking current one signs out this device.",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "session id",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK - revoke successfully"
+                    },
+                    "404": {
+                        "description": "Fail - session not found"
+                    },
+                    "500": {
+                        "description": "Fail - internal error"
+                    }
+                }
+            }
+        },
         "/api/sign-out/": {
             "get": {
                 "security": [
@@ -1225,7 +3852,7 @@ const docTemplate = `{
                 "tags": [
                     "Sign"
                 ],
-                "summary": "sign out action.",
+                "summary": "sign out action. current session is revoked, other sessions are kept.",
                 "responses": {
                     "200": {
                         "description": "OK - sign-out successfully"
@@ -1236,7 +3863,52 @@ const docTemplate = `{
                 }
             }
         },
-        "/api/sign/in": {
+        "/api/sign/in": {
+            "post": {
+                "consumes": [
+                    "multipart/form-data"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Sign"
+                ],
+                "summary": "sign in action. if ok, got short-lived access token \u0026 refresh token of a new session. if user has 2fa, or 2fa is required to enroll, got a challenge to answer at '/api/sign/in-2fa' instead.",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "user name or email",
+                        "name": "uname",
+                        "in": "formData",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "format": "password",
+                        "description": "password",
+                        "name": "pwd",
+                        "in": "formData",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK - sign-in successfully"
+                    },
+                    "400": {
+                        "description": "Fail - incorrect password"
+                    },
+                    "429": {
+                        "description": "Fail - too many attempts, retry after seconds in header Retry-After"
+                    },
+                    "500": {
+                        "description": "Fail - internal error"
+                    }
+                }
+            }
+        },
+        "/api/sign/in-2fa": {
             "post": {
                 "consumes": [
                     "multipart/form-data"
@@ -1247,20 +3919,19 @@ const docTemplate = `{
                 "tags": [
                     "Sign"
                 ],
-                "summary": "sign in action. if ok, got token",
+                "summary": "sign in action, step 2 for two-factor authentication user. answer challenge from sign-in with a code from authenticator app, or a one-time recovery code. if 2fa enrollment is required, the code confirms it and recovery codes are returned. if ok, got tokens like sign-in.",
                 "parameters": [
                     {
                         "type": "string",
-                        "description": "user name or email",
-                        "name": "uname",
+                        "description": "challenge from sign-in",
+                        "name": "challenge",
                         "in": "formData",
                         "required": true
                     },
                     {
                         "type": "string",
-                        "format": "password",
-                        "description": "password",
-                        "name": "pwd",
+                        "description": "code from authenticator app, or a recovery code",
+                        "name": "code",
                         "in": "formData",
                         "required": true
                     }
@@ -1270,7 +3941,13 @@ const docTemplate = `{
                         "description": "OK - sign-in successfully"
                     },
                     "400": {
-                        "description": "Fail - incorrect password"
+                        "description": "Fail - incorrect code"
+                    },
+                    "401": {
+                        "description": "Fail - invalid or expired challenge"
+                    },
+                    "429": {
+                        "description": "Fail - too many attempts, retry after seconds in header Retry-After"
                     },
                     "500": {
                         "description": "Fail - internal error"
@@ -1328,6 +4005,43 @@ const docTemplate = `{
                     "400": {
                         "description": "Fail - invalid registry fields"
                     },
+                    "429": {
+                        "description": "Fail - too many attempts, retry after seconds in header Retry-After"
+                    },
+                    "500": {
+                        "description": "Fail - internal error"
+                    }
+                }
+            }
+        },
+        "/api/sign/refresh": {
+            "post": {
+                "consumes": [
+                    "multipart/form-data"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Sign"
+                ],
+                "summary": "exchange refresh token for new access token \u0026 refresh token. each refresh token is used only once, reusing an old one revokes its session.",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "refresh token from sign-in or last refresh",
+                        "name": "refresh",
+                        "in": "formData",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK - refresh successfully"
+                    },
+                    "401": {
+                        "description": "Fail - invalid, expired or reused refresh token"
+                    },
                     "500": {
                         "description": "Fail - internal error"
                     }
@@ -1370,6 +4084,9 @@ const docTemplate = `{
                     "400": {
                         "description": "Fail - invalid registry fields"
                     },
+                    "429": {
+                        "description": "Fail - too many attempts, retry after seconds in header Retry-After"
+                    },
                     "500": {
                         "description": "Fail - internal error"
                     }
@@ -1411,6 +4128,9 @@ const docTemplate = `{
                     "400": {
                         "description": "Fail - incorrect verification code"
                     },
+                    "429": {
+                        "description": "Fail - too many attempts, retry after seconds in header Retry-After"
+                    },
                     "500": {
                         "description": "Fail - internal error"
                     }
@@ -1459,6 +4179,9 @@ const docTemplate = `{
                     "400": {
                         "description": "Fail - incorrect verification code"
                     },
+                    "429": {
+                        "description": "Fail - too many attempts, retry after seconds in header Retry-After"
+                    },
                     "500": {
                         "description": "Fail - internal error"
                     }
@@ -1702,6 +4425,260 @@ const docTemplate = `{
                     }
                 }
             }
+        },
+        "/api/vote/cast/{id}": {
+            "post": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Vote"
+                ],
+                "summary": "cast current user's ballot for a Vote. one user can only cast one ballot.",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Vote ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "chosen option indices, separated by ',' e.g. '0,2'",
+                        "name": "options",
+                        "in": "query",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK - cast successfully, return chosen option indices"
+                    },
+                    "400": {
+                        "description": "Fail - invalid options"
+                    },
+                    "403": {
+                        "description": "Fail - Vote is closed"
+                    },
+                    "404": {
+                        "description": "Fail - Vote not found"
+                    },
+                    "409": {
+                        "description": "Fail - ballot already cast"
+                    },
+                    "500": {
+                        "description": "Fail - internal error"
+                    }
+                }
+            }
+        },
+        "/api/vote/change/{id}": {
+            "put": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Vote"
+                ],
+                "summary": "change current user's cast ballot for a Vote before its deadline.",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Vote ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "new chosen option indices, separated by ',' e.g. '0,2'",
+                        "name": "options",
+                        "in": "query",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK - change successfully, return chosen option indices"
+                    },
+                    "400": {
+                        "description": "Fail - invalid options"
+                    },
+                    "403": {
+                        "description": "Fail - Vote is closed"
+                    },
+                    "404": {
+                        "description": "Fail - Vote or ballot not found"
+                    },
+                    "500": {
+                        "description": "Fail - internal error"
+                    }
+                }
+            }
+        },
+        "/api/vote/create": {
+            "post": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Vote"
+                ],
+                "summary": "create a Vote by filling a Vote template.",
+                "parameters": [
+                    {
+                        "description": "filled Vote template json file",
+                        "name": "data",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "type": "string"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK - create successfully, return Vote ID"
+                    },
+                    "400": {
+                        "description": "Fail - incorrect Vote format"
+                    },
+                    "500": {
+                        "description": "Fail - internal error"
+                    }
+                }
+            }
+        },
+        "/api/vote/one": {
+            "get": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Vote"
+                ],
+                "summary": "get one Vote content with its status.",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Vote ID",
+                        "name": "id",
+                        "in": "query",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK - get Vote successfully"
+                    },
+                    "400": {
+                        "description": "Fail - incorrect query param id"
+                    },
+                    "404": {
+                        "description": "Fail - not found"
+                    },
+                    "500": {
+                        "description": "Fail - internal error"
+                    }
+                }
+            }
+        },
+        "/api/vote/tally/{id}": {
+            "get": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "tally counts are hidden before deadline if Vote is set 'resultAfterDeadline'.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Vote"
+                ],
+                "summary": "get live tally of a Vote and current user's ballot.",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Vote ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK - get tally successfully"
+                    },
+                    "404": {
+                        "description": "Fail - Vote not found"
+                    },
+                    "500": {
+                        "description": "Fail - internal error"
+                    }
+                }
+            }
+        },
+        "/api/vote/template": {
+            "get": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Vote"
+                ],
+                "summary": "get Vote template for dev reference.",
+                "responses": {
+                    "200": {
+                        "description": "OK - get template successfully"
+                    }
+                }
+            }
         }
     },
     "securityDefinitions": {
@@ -15,11 +15,18 @@ import (
 	lk "github.com/digisan/logkit"
 	r "github.com/digisan/user-mgr/relation"
 	u "github.com/digisan/user-mgr/user"
+	"github.com/golang-jwt/jwt/v4"
 	"github.com/labstack/echo/v4"
 	"github.com/labstack/echo/v4/middleware"
 	"github.com/postfinance/single"
 	echoSwagger "github.com/swaggo/echo-swagger"
 	"github.com/wismed-web/wisite-api/server/api"
+	"github.com/wismed-web/wisite-api/server/api/file/media"
+	"github.com/wismed-web/wisite-api/server/api/mfa"
+	"github.com/wismed-web/wisite-api/server/api/notify"
+	"github.com/wismed-web/wisite-api/server/api/post"
+	"github.com/wismed-web/wisite-api/server/api/session"
+	"github.com/wismed-web/wisite-api/server/api/sign"
 	_ "github.com/wismed-web/wisite-api/server/docs" // once `swag init`, comment it out
 	"github.com/wismed-web/wisite-api/server/ws"
 )
@@ -27,6 +34,8 @@ import (
 var (
 	fHttp2 = false //
 	port   = 3323  // must be same as @host IP
+
+	ipExtractor echo.IPExtractor // client ip, only trusts forwarding headers from configured proxies
 )
 
 func init() {
@@ -36,6 +45,19 @@ func init() {
 
 	fHttp2 = cfg.Val[bool]("http2")
 	port = cfg.Val[int]("port")
+	post.SetReactions(cfg.ValArr[string]("reactions")...)
+	lk.FailOnErr("%v", post.SetPublicURL(cfg.Val[string]("public-url")))
+	var err error
+	ipExtractor, err = sign.IPExtractor(cfg.ValArr[string]("trusted-proxies")...)
+	lk.FailOnErr("%v", err)
+	for _, m := range cfg.Objects("external-providers") {
+		p, err := sign.NewProvider(m) // e.g. missing token, skip it but keep serving local users
+		if err != nil {
+			lk.Warn("external provider is disabled: %v", err)
+			continue
+		}
+		lk.FailOnErr("%v", sign.RegisterProvider(p))
+	}
 }
 
 // @title WISMED WISITE API
@@ -55,9 +77,21 @@ func init() {
 func main() {
 
 	http2Ptr := flag.Bool("http2", false, "http2 mode?")
+	backfillPtr := flag.Bool("backfill-media", false, "probe & store media metadata for existing uploaded files, then exit")
 	flag.Parse()
 	fHttp2 = *http2Ptr
 
+	const dataDir = "./data" // dbs of api packages, e.g. media metadata, events & sessions
+	media.InitDB(dataDir)
+
+	if *backfillPtr {
+		defer media.CloseDB()
+		n, err := media.Backfill()
+		lk.FailOnErr("%v", err)
+		lk.Log("media metadata backfilled for %d files", n)
+		return
+	}
+
 	// only one instance
 	const dir = "./tmp-locker"
 	gio.MustCreateDir(dir)
@@ -70,6 +104,12 @@ func main() {
 		lk.Log("Server Exited Successfully")
 	}()
 
+	// other api dbs, only for serving
+	post.Init(dataDir)
+	notify.InitDB(dataDir)
+	session.Init(dataDir)
+	mfa.InitDB(dataDir)
+
 	// start Service
 	done := make(chan string)
 	echoHost(done)
@@ -81,6 +121,11 @@ func waitShutdown(e *echo.Echo) {
 		defer u.CloseDB()         // after closing echo, close user db, i.e. deactivate ***[UserDB]***
 		defer r.CloseDB()         // after closing echo, close relation db, i.e. deactivate ***[RelDB]***
 		defer fm.DisposeFileMgr() // close file db
+		defer post.CloseDB()      // close post db, e.g. revisions
+		defer media.CloseDB()     // close media metadata db
+		defer notify.CloseDB()    // close notification db
+		defer session.CloseDB()   // close login session db
+		defer mfa.CloseDB()       // close 2fa db
 
 		ctx, cancel := context.WithCancel(context.Background())
 		defer cancel()
@@ -97,6 +142,9 @@ func waitShutdown(e *echo.Echo) {
 
 		// shutdown echo
 		lk.FailOnErr("%v", e.Shutdown(ctx)) // close echo at e.Shutdown
+
+		// stop post background routines (e.g. draft scheduler) before closing post db
+		post.Cancel()
 	}()
 }
 
@@ -107,6 +155,9 @@ func echoHost(done chan<- string) {
 		e := echo.New()
 		defer e.Close()
 
+		// client ip for sign limiting & sessions, never taken from headers of untrusted peer
+		e.IPExtractor = ipExtractor
+
 		// Middleware
 		e.Use(middleware.Logger())
 		e.Use(middleware.Recover())
@@ -122,6 +173,9 @@ func echoHost(done chan<- string) {
 		// waiting for shutdown
 		waitShutdown(e)
 
+		// post background routines, e.g. draft scheduler, only for serving
+		post.Start()
+
 		// host static file/folder | only for testing
 		hookStatic(e)
 
@@ -147,6 +201,10 @@ func echoHost(done chan<- string) {
 			"/api/user",
 			"/api/rel",
 			"/api/client",
+			"/api/vote",
+			"/api/notify",
+			"/api/session",
+			"/api/mfa",
 		}
 		handlers := []func(*echo.Group){
 			api.SignoutHandler,
@@ -156,6 +214,10 @@ func echoHost(done chan<- string) {
 			api.UserHandler,
 			api.RelHandler,
 			api.ClientHandler,
+			api.VoteHandler,
+			api.NotifyHandler,
+			api.SessionHandler,
+			api.MFAHandler,
 		}
 		for i, group := range groups {
 			r := e.Group(group)
@@ -179,15 +241,13 @@ func echoHost(done chan<- string) {
 	}()
 }
 
+// access token must belong to an alive session, i.e. not signed out or revoked
 func ValidateToken(next echo.HandlerFunc) echo.HandlerFunc {
 	return func(c echo.Context) error {
-		token, claims, err := u.TokenClaimsInHandler(c)
-		if err != nil {
-			return err
-		}
-		invoker := u.ClaimsToUser(claims)
-		if invoker.ValidateToken(token.Raw) {
-			return next(c)
+		if token, ok := c.Get("user").(*jwt.Token); ok {
+			if claims, ok := token.Claims.(*u.UserClaims); ok && session.Alive(claims) {
+				return next(c)
+			}
 		}
 		return c.JSON(http.StatusUnauthorized, map[string]any{
 			"message": "invalid or expired jwt",
@@ -3,10 +3,15 @@ package ws
 import (
 	"context"
 	"fmt"
+	"net/http"
+	"strings"
 	"sync"
 
 	lk "github.com/digisan/logkit"
+	u "github.com/digisan/user-mgr/user"
+	"github.com/golang-jwt/jwt/v4"
 	"github.com/labstack/echo/v4"
+	"github.com/wismed-web/wisite-api/server/api/session"
 	"golang.org/x/net/websocket"
 )
 
@@ -51,20 +56,46 @@ func CloseAllMsg() {
 	})
 }
 
-// Activate WS Msg by GET
+// uname of a signed-in user from jwt in query param 'token' or 'Authorization' header.
+// browser websocket cannot set header, so query param is accepted
+func wsUser(c echo.Context) (string, bool) {
+	raw := c.QueryParam("token")
+	if len(raw) == 0 {
+		raw = strings.TrimPrefix(c.Request().Header.Get(echo.HeaderAuthorization), "Bearer ")
+	}
+	claims := &u.UserClaims{}
+	tkn, err := jwt.ParseWithClaims(raw, claims, func(t *jwt.Token) (any, error) { return []byte(u.TokenKey()), nil })
+	if err != nil || !tkn.Valid {
+		return "", false
+	}
+	return claims.UName, session.Alive(claims)
+}
+
+// Activate WS Msg by GET, messages to signed-in user are sent to this connection
 func WSMsg(c echo.Context) error {
 
-	id := c.Request().Header.Get("id")
-	id = "id" // just for testing ***********************************
+	id, ok := wsUser(c)
+	if !ok {
+		return c.String(http.StatusUnauthorized, "invalid or expired jwt")
+	}
 
-	// reg a new message channel
-	mIdMsg.Store(id, make(chan any, 1024))
+	// reg a new message channel, a later connection of the same user takes over
+	chMsg := make(chan any, 1024)
+	mIdMsg.Store(id, chMsg)
 
 	// reg message channel closing
 	ctx, cancel := context.WithCancel(context.Background())
 	defer cancel()
 	mIdWSCancel.Store(id, cancel)
 
+	// unreg if not taken over, then offline user's messages are not queued
+	defer func() {
+		if ch, ok := mIdMsg.Load(id); ok && ch == chMsg {
+			mIdMsg.Delete(id)
+			mIdWSCancel.Delete(id)
+		}
+	}()
+
 	websocket.Handler(func(ws *websocket.Conn) {
 		defer ws.Close()
 
@@ -77,18 +108,27 @@ func WSMsg(c echo.Context) error {
 		}
 		lk.Log("%s\n", clientMsg)
 
+		// client closing or broken connection ends sending
+		go func() {
+			defer cancel()
+			var discard string
+			for websocket.Message.Receive(ws, &discard) == nil {
+			}
+		}()
+
 		done := make(chan struct{})
 		go func(ctx context.Context, done chan<- struct{}) {
 			defer func() { done <- struct{}{} }()
-			if IChMsg, ok := mIdMsg.Load(id); ok {
-				chMsg := IChMsg.(chan any)
-				for {
-					select {
-					case msg := <-chMsg:
-						lk.WarnOnErr("%v", websocket.Message.Send(ws, fmt.Sprintf("WS message from server --- %v", msg)))
-					case <-ctx.Done():
-						return
+			for {
+				select {
+				case msg := <-chMsg:
+					if s, ok := msg.(string); ok {
+						lk.WarnOnErr("%v", websocket.Message.Send(ws, fmt.Sprintf("WS message from server --- %v", s)))
+					} else {
+						lk.WarnOnErr("%v", websocket.JSON.Send(ws, msg)) // e.g. notification
 					}
+				case <-ctx.Done():
+					return
 				}
 			}
 		}(ctx, done)
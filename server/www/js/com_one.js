@@ -30,7 +30,7 @@ export default {
         /////////////////////////////////////
 
         // web socket example
-        let ws = local_ws("ws/msg"); // hook ws, must be registered in server reg_api
+        let ws = local_ws("ws/msg?token=" + token); // hook ws, must be registered in server reg_api, signed-in jwt identifies receiver
         ws.onopen = function () {
             console.log('ws connected')
         }